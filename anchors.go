@@ -0,0 +1,128 @@
+package jsondoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/russross/blackfriday"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// anchorLink returns the permalink ("¶") appended to the headings with
+// the given (HTML escaped) id so that readers may share deep links to
+// particular sections.
+func anchorLink(id string) string {
+	return ` <a class="anchor" href="#` + id + `" title="Copy link to this section">¶</a>`
+}
+
+// headingRenderer renders the goldmark headings with their permalinks
+// (if they have an id).
+type headingRenderer struct{}
+
+func (headingRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindHeading, renderHeading)
+}
+
+func renderHeading(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.Heading)
+	if entering {
+		fmt.Fprintf(w, "<h%d", n.Level)
+		if n.Attributes() != nil {
+			html.RenderAttributes(w, n, html.HeadingAttributeFilter)
+		}
+		w.WriteByte('>')
+		return ast.WalkContinue, nil
+	}
+	if v, ok := n.AttributeString("id"); ok {
+		if id, ok := v.([]byte); ok && len(id) > 0 {
+			w.WriteString(anchorLink(string(util.EscapeHTML(id))))
+		}
+	}
+	fmt.Fprintf(w, "</h%d>\n", n.Level)
+	return ast.WalkContinue, nil
+}
+
+// headerIDRe matches the start of a heading with an id as written by
+// blackfriday.
+var headerIDRe = regexp.MustCompile(`^\s*<h[1-6] id="([^"]*)">`)
+
+// anchorRenderer is the blackfriday HTML renderer writing the headings
+// with their permalinks (if they have an id).
+type anchorRenderer struct {
+	blackfriday.Renderer
+}
+
+func (r anchorRenderer) Header(out *bytes.Buffer, text func() bool, level int, id string) {
+	marker := out.Len()
+	r.Renderer.Header(out, text, level, id)
+	h := out.Bytes()[marker:]
+	end := fmt.Sprintf("</h%d>\n", level)
+	m := headerIDRe.FindSubmatch(h)
+	if m == nil || !bytes.HasSuffix(h, []byte(end)) {
+		return
+	}
+	link := anchorLink(string(m[1]))
+	out.Truncate(out.Len() - len(end))
+	out.WriteString(link)
+	out.WriteString(end)
+}
+
+// landmarksStart returns the "Skip to content" link (for keyboard and
+// screen reader users) and the labeled table of contents (if any)
+// followed by the start of the main element wrapping the documentation
+// (ended with landmarksEnd).
+func landmarksStart(toc []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("<a class=\"skip-link\" href=\"#main-content\">Skip to content</a>\n")
+	if bytes.HasPrefix(toc, []byte("<nav>")) {
+		b.WriteString("<button class=\"nav-toggle\" type=\"button\" aria-expanded=\"false\" aria-controls=\"jsondoc-nav\">&#9776; Contents</button>\n")
+		b.WriteString("<nav id=\"jsondoc-nav\" aria-label=\"Table of contents\">")
+		b.Write(bytes.TrimRight(toc[len("<nav>"):], "\n"))
+	}
+	b.WriteString("\n<main id=\"main-content\">\n")
+	return b.Bytes()
+}
+
+const landmarksEnd = "</main>\n"
+
+// anchor describes a link target in the generated documentation.
+type anchor struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"` // "endpoint", "input", "output", "errors" or "type"
+	Title  string `json:"title"`
+	Method string `json:"method,omitempty"`
+	Path   string `json:"path,omitempty"`
+	Type   string `json:"type,omitempty"`
+}
+
+func (d *JSONDoc) addAnchor(a anchor) {
+	d.anchors = append(d.anchors, a)
+}
+
+// WriteAnchors writes a JSON manifest of all the anchors of the
+// documentation.
+func (d *JSONDoc) WriteAnchors(w io.Writer) error {
+	c, err := d.begin()
+	if err != nil {
+		return err
+	}
+	defer d.end(c)
+	return c.writeAnchors(w)
+}
+
+func (d *JSONDoc) writeAnchors(w io.Writer) error {
+	b, err := json.MarshalIndent(struct {
+		Anchors []anchor `json:"anchors"`
+	}{d.anchors}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
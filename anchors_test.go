@@ -0,0 +1,51 @@
+package jsondoc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAnchors(t *testing.T) {
+	src := "package api\n\ntype Item struct {\n\tTag Tag `json:\"tag\"`\n}\n\ntype Tag struct {\n\tName string `json:\"name\"`\n}\n"
+	tmpl := "# API\n\n## Items {.wide #items}\n\nMulti\nline *heading*\n-----\n\n{{endpoint \"GET\" \"/item\"}}\n\n{{output \"Item\"}}\n"
+	link := func(id string) string {
+		return ` <a class="anchor" href="#` + id + `" title="Copy link to this section">¶</a>`
+	}
+	for _, c := range []struct {
+		engine string
+		want   []string
+	}{
+		{engineGoldmark, []string{
+			`<h1 id="api">API` + link("api") + `</h1>`,
+			`<h2 class="wide" id="items">Items` + link("items") + `</h2>`,
+			"<h2 id=\"line-heading\">Multi\nline <em>heading</em>" + link("line-heading") + "</h2>",
+			`<h3 id="endpoint-get-item-output">Output (Item)` + link("endpoint-get-item-output") + `</h3>`,
+			`<h4 id="type-Tag-1">Type Tag` + link("type-Tag-1") + `</h4>`,
+			`<li><a href="#items">Items</a></li>`,
+		}},
+		{engineBlackfriday, []string{
+			`<h1 id="toc_0">API` + link("toc_0") + `</h1>`,
+			`<h2 id="toc_2">line <em>heading</em>` + link("toc_2") + `</h2>`,
+			`<h3 id="endpoint-get-item-output">Output (Item)` + link("endpoint-get-item-output") + `</h3>`,
+			`<h4 id="type-Tag-1">Type Tag` + link("type-Tag-1") + `</h4>`,
+			`<li><a href="#toc_2">line <em>heading</em></a></li>`,
+		}},
+	} {
+		d := newTestDoc(t, src, tmpl, Options{Engine: c.engine})
+		var b bytes.Buffer
+		if _, err := d.WriteTo(&b); err != nil {
+			t.Fatal(err)
+		}
+		out := b.String()
+		for _, s := range c.want {
+			if !strings.Contains(out, s) {
+				t.Errorf("%s: %q not found in the output", c.engine, s)
+			}
+		}
+		nav := out[strings.Index(out, "<nav id="):strings.Index(out, "</nav>")]
+		if strings.Contains(nav, "¶") {
+			t.Errorf("%s: permalinks in the table of contents:\n%s", c.engine, nav)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package jsondoc
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// branding is the corporate branding of the generated documentation
+// (given in the configuration file or with Options).
+type branding struct {
+	Logo   string // URL or local file of the logo image (embedded)
+	Header string // HTML of the header bar
+	Footer string // HTML of the footer (such as legal notices and support links)
+}
+
+// header returns the header bar with the logo and the header text (empty
+// if neither is given).
+func (b branding) header() (string, error) {
+	if b.Logo == "" && b.Header == "" {
+		return "", nil
+	}
+	h := "<header class=\"branding\">\n"
+	if b.Logo != "" {
+		src, err := imageSource(b.Logo)
+		if err != nil {
+			return "", fmt.Errorf("logo: %v", err)
+		}
+		h += fmt.Sprintf("<img class=\"logo\" src=\"%s\" alt=\"Logo\">\n", html.EscapeString(src))
+	}
+	if b.Header != "" {
+		h += "<div>" + b.Header + "</div>\n"
+	}
+	return h + "</header>\n", nil
+}
+
+// footer returns the footer with the footer text (empty if not given).
+func (b branding) footer() string {
+	if b.Footer == "" {
+		return ""
+	}
+	return "<footer class=\"branding\">" + b.Footer + "</footer>\n"
+}
+
+// imageSource returns src if it is an URL or the data URL with the
+// content of the local image file src (so that the documentation works
+// offline).
+func imageSource(src string) (string, error) {
+	if strings.Contains(src, "://") || strings.HasPrefix(src, "data:") {
+		return src, nil
+	}
+	ext := strings.ToLower(filepath.Ext(src))
+	typ := mime.TypeByExtension(ext)
+	if ext == ".ico" {
+		// not known to mime on all systems
+		typ = "image/x-icon"
+	}
+	if !strings.HasPrefix(typ, "image/") {
+		return "", fmt.Errorf("%s: not an image file (by its extension)", src)
+	}
+	b, err := os.ReadFile(src)
+	if err != nil {
+		return "", err
+	}
+	return "data:" + typ + ";base64," + base64.StdEncoding.EncodeToString(b), nil
+}
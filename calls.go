@@ -0,0 +1,64 @@
+package jsondoc
+
+import (
+	"bytes"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The exported methods of JSONDoc may be called concurrently. The
+// template is executed once (by the first of them) and the state it
+// collects (the markdown output, the endpoints, the rendered types and
+// so on) is not modified afterwards. Each call then renders its output
+// with its own copy of the JSONDoc (see begin) holding the state of the
+// call (the buffer, the source of fake values of the samples, the timer
+// and the warnings) and sharing the caches filled lazily (the parsed
+// and type checked packages) which are guarded by the locks of calls.
+
+// calls is the state of a JSONDoc shared by the concurrent calls of its
+// methods.
+type calls struct {
+	once sync.Once   // executes the template
+	err  error       // error of executing the template
+	done atomic.Bool // the template was executed
+
+	cache sync.Mutex // guards packages, packageNames, stdPackages, referenced, marshalersChecked and capturedValues
+	check sync.Mutex // serializes type checking (guarding typesPackages, typesInfo and typeDecls)
+
+	mu       sync.Mutex               // guards the fields below
+	phases   map[string]time.Duration // times of the phases of the ended calls
+	warnings int                      // warnings reported by the ended calls
+}
+
+// begin executes the template (once) and returns the copy of d used
+// by a call of an exported method, to be ended with end. The copy has
+// its own buffer, source of fake values (seeded as configured, so that
+// the samples of each call are the same), timer and warnings.
+func (d *JSONDoc) begin() (*JSONDoc, error) {
+	if err := d.execute(); err != nil {
+		return nil, err
+	}
+	c := *d
+	c.b = bytes.Buffer{}
+	c.renderQueue = nil
+	c.rand = rand.New(rand.NewSource(d.seed))
+	c.timer = timer{}
+	return &c, nil
+}
+
+// end adds the times and the warnings of the call using c (returned by
+// begin) to those of d.
+func (d *JSONDoc) end(c *JSONDoc) {
+	s := d.calls
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.phases == nil {
+		s.phases = make(map[string]time.Duration)
+	}
+	for p, t := range c.timer.phases {
+		s.phases[p] += t
+	}
+	s.warnings += c.warnings - d.warnings
+}
@@ -0,0 +1,312 @@
+package jsondoc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"math/rand"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Capture is a net/http middleware recording sampled JSON request and
+// response bodies of real traffic (as JSON lines written to W) which
+// may then be used as examples in the documentation (given with
+// Options.Captures or -captures).
+type Capture struct {
+	W        io.Writer                  // destination of the captured exchanges
+	Rate     float64                    // fraction of requests captured (all if zero)
+	PerRoute int                        // maximum number of exchanges captured per route (unlimited if zero)
+	MaxBody  int                        // maximum size of captured bodies (64 KiB if zero)
+	Route    func(*http.Request) string // route of the request (such as "GET /item/{id}"), overrides Endpoints
+
+	// Endpoints lists the documented endpoints (as returned by
+	// JSONDoc.Endpoints, such as "GET /item/{id}"). If given only
+	// requests of them are captured and each endpoint is a route.
+	// PerRoute requires Endpoints or Route.
+	Endpoints []string
+
+	// Redact (if not nil) is called with each exchange before it is
+	// written, such as to remove passwords or personal data. Setting
+	// both of its bodies to nil drops the exchange.
+	Redact func(*CapturedExchange)
+
+	mu     sync.Mutex
+	counts map[string]int
+	err    error
+}
+
+// CapturedExchange is a request and the response to it recorded by
+// Capture.
+type CapturedExchange struct {
+	Method   string          `json:"method"`
+	Path     string          `json:"path"`
+	Status   int             `json:"status"`
+	Request  json.RawMessage `json:"request,omitempty"`
+	Response json.RawMessage `json:"response,omitempty"`
+}
+
+// Handler returns the handler recording the exchanges with next. It
+// panics if PerRoute is given without Endpoints or Route (each URL
+// path, such as of /item/1 and /item/2, would be a separate route).
+func (c *Capture) Handler(next http.Handler) http.Handler {
+	if c.PerRoute > 0 && c.Route == nil && len(c.Endpoints) == 0 {
+		panic("jsondoc: Capture.PerRoute requires Endpoints or Route")
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, ok := c.route(r)
+		if !ok || c.Rate > 0 && rand.Float64() >= c.Rate || c.full(route) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		max := c.MaxBody
+		if max == 0 {
+			max = 64 << 10
+		}
+		var req []byte
+		if r.Body != nil && isJSONContentType(r.Header.Get("Content-Type")) {
+			b, err := io.ReadAll(io.LimitReader(r.Body, int64(max)+1))
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(b), r.Body), r.Body}
+			if err == nil && len(b) <= max && json.Valid(b) {
+				req = b
+			}
+		}
+		rec := &capturingWriter{ResponseWriter: w, max: max}
+		next.ServeHTTP(rec, r)
+		x := CapturedExchange{Method: r.Method, Path: r.URL.Path, Status: rec.status, Request: compactJSON(req)}
+		if x.Status == 0 {
+			x.Status = http.StatusOK
+		}
+		if isJSONContentType(rec.Header().Get("Content-Type")) && rec.buf.Len() <= max && json.Valid(rec.buf.Bytes()) {
+			x.Response = compactJSON(rec.buf.Bytes())
+		}
+		if c.Redact != nil && (x.Request != nil || x.Response != nil) {
+			c.Redact(&x)
+		}
+		if x.Request == nil && x.Response == nil {
+			return
+		}
+		b, err := json.Marshal(x)
+		if err != nil {
+			return
+		}
+		c.record(route, append(b, '\n'))
+	})
+}
+
+// Err returns the first error writing to W. No exchanges are captured
+// after it.
+func (c *Capture) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// route returns the route of the request and reports whether it may
+// be captured (it is of one of the Endpoints, if given).
+func (c *Capture) route(r *http.Request) (string, bool) {
+	if c.Route != nil {
+		return c.Route(r), true
+	}
+	if len(c.Endpoints) == 0 {
+		return r.Method + " " + r.URL.Path, true
+	}
+	for _, e := range c.Endpoints {
+		if i := strings.IndexByte(e, ' '); i != -1 && e[:i] == r.Method && matchPath(e[i+1:], r.URL.Path) {
+			return e, true
+		}
+	}
+	return "", false
+}
+
+// full reports whether no more exchanges of the route may be captured.
+func (c *Capture) full(route string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err != nil || c.PerRoute > 0 && c.counts[route] >= c.PerRoute
+}
+
+// record writes the exchange (a JSON line) counting it against the
+// limit of its route (unless it was reached by concurrent requests).
+func (c *Capture) record(route string, line []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil || c.PerRoute > 0 && c.counts[route] >= c.PerRoute {
+		return
+	}
+	if _, err := c.W.Write(line); err != nil {
+		c.err = err
+		return
+	}
+	if c.counts == nil {
+		c.counts = make(map[string]int)
+	}
+	c.counts[route]++
+}
+
+// capturingWriter records the status and (the beginning of) the body
+// of the response.
+type capturingWriter struct {
+	http.ResponseWriter
+	status int
+	max    int
+	buf    bytes.Buffer
+}
+
+func (w *capturingWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *capturingWriter) Write(b []byte) (int, error) {
+	if n := w.max + 1 - w.buf.Len(); n > 0 {
+		if n > len(b) {
+			n = len(b)
+		}
+		w.buf.Write(b[:n])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends the buffered data to the client (such as of streamed
+// NDJSON outputs) if the underlying writer supports it.
+func (w *capturingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer (used by http.ResponseController).
+func (w *capturingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func isJSONContentType(s string) bool {
+	t, _, err := mime.ParseMediaType(s)
+	return err == nil && (t == "application/json" || strings.HasSuffix(t, "+json"))
+}
+
+func compactJSON(b []byte) json.RawMessage {
+	if b == nil {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// readCaptures reads the exchanges recorded by Capture from the named
+// file.
+func readCaptures(filename string) ([]CapturedExchange, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var xs []CapturedExchange
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 16<<20)
+	for line := 1; s.Scan(); line++ {
+		if len(bytes.TrimSpace(s.Bytes())) == 0 {
+			continue
+		}
+		var x CapturedExchange
+		if err := json.Unmarshal(s.Bytes(), &x); err != nil {
+			return nil, fmt.Errorf("captures %s:%d: %v", filename, line, err)
+		}
+		xs = append(xs, x)
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("captures %s: %v", filename, err)
+	}
+	return xs, nil
+}
+
+// captured returns the request (or the response if output is true)
+// body captured for the endpoint which conforms to its documented JSON
+// input (or output) type, or nil if there is none. Captured bodies
+// which do not conform are reported as warnings.
+func (d *JSONDoc) captured(e *endpoint, output bool) (interface{}, error) {
+	if e == nil || e.codec != nil || output && (e.Output == "" || e.OutputContentType != "") || !output && !e.jsonInput() {
+		return nil, nil
+	}
+	key := e.ID + "-input"
+	if output {
+		key = e.ID + "-output"
+	}
+	d.calls.cache.Lock()
+	v, ok := d.capturedValues[key]
+	d.calls.cache.Unlock()
+	if ok {
+		return v, nil
+	}
+	for _, x := range d.captures {
+		if x.Method != e.Method || !matchPath(e.Path, x.Path) {
+			continue
+		}
+		body, name, env, container, what := x.Request, e.Input, "", "", "request"
+		if output {
+			if x.Status < 200 || x.Status > 299 {
+				continue
+			}
+			body, name, env, container, what = x.Response, e.Output, e.Envelope, e.Container, "response"
+		}
+		if body == nil {
+			continue
+		}
+		b, err := decodeJSON(body)
+		if err != nil {
+			return nil, err
+		}
+		problems, err := d.checkByName(name, env, container, b)
+		if err != nil {
+			return nil, err
+		}
+		if len(problems) == 0 {
+			v = b
+			break
+		}
+		d.warnf("warning: %s: captured %s to %s does not match %s: %s\n", e.Title(), what, x.Path, typeIdent(name), strings.Join(problems, "; "))
+	}
+	d.calls.cache.Lock()
+	d.capturedValues[key] = v
+	d.calls.cache.Unlock()
+	return v, nil
+}
+
+// renderCaptured writes to d.b the captured example of the input (or
+// output) of the endpoint (if any).
+func (d *JSONDoc) renderCaptured(e *endpoint, output bool) error {
+	v, err := d.captured(e, output)
+	if err != nil || v == nil {
+		return err
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(&d.b, "<p>Example captured from real traffic:</p>\n<pre class=\"example\"><code>%s</code></pre>\n", html.EscapeString(string(b)))
+	return nil
+}
+
+// inputSample returns the captured input of the endpoint or a sample
+// of its input type.
+func (d *JSONDoc) inputSample(e *endpoint) (interface{}, error) {
+	if v, err := d.captured(e, false); err != nil || v != nil {
+		return v, err
+	}
+	return d.sampleByName(e.Input)
+}
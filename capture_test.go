@@ -0,0 +1,165 @@
+package jsondoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureLines returns the exchanges written by Capture.
+func captureLines(t *testing.T, b []byte) []CapturedExchange {
+	t.Helper()
+	var xs []CapturedExchange
+	for _, line := range bytes.Split(bytes.TrimSpace(b), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var x CapturedExchange
+		if err := json.Unmarshal(line, &x); err != nil {
+			t.Fatal(err)
+		}
+		xs = append(xs, x)
+	}
+	return xs
+}
+
+func TestCapturePerRoute(t *testing.T) {
+	var b bytes.Buffer
+	c := &Capture{W: &b, PerRoute: 2, Endpoints: []string{"GET /item/{id}"}}
+	h := c.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/item/")
+		if id == "text" {
+			w.Header().Set("Content-Type", "text/plain")
+			fmt.Fprint(w, "not JSON")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id": %q}`, id)
+	}))
+	for _, path := range []string{"/item/text", "/item/1", "/other", "/item/2", "/item/3"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	xs := captureLines(t, b.Bytes())
+	if len(xs) != 2 || xs[0].Path != "/item/1" || xs[1].Path != "/item/2" {
+		t.Fatalf("captured %+v, want /item/1 and /item/2", xs)
+	}
+	if string(xs[0].Response) != `{"id":"1"}` || xs[0].Status != http.StatusOK {
+		t.Errorf("captured response %d %s", xs[0].Status, xs[0].Response)
+	}
+}
+
+func TestCaptureRedact(t *testing.T) {
+	var b bytes.Buffer
+	c := &Capture{W: &b, Redact: func(x *CapturedExchange) {
+		if x.Path == "/login" {
+			x.Request = nil
+		}
+	}}
+	h := c.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"ok": true}`)
+	}))
+	r := httptest.NewRequest("POST", "/login", strings.NewReader(`{"user": "joe", "password": "secret"}`))
+	r.Header.Set("Content-Type", "application/json")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	xs := captureLines(t, b.Bytes())
+	if len(xs) != 1 || xs[0].Request != nil || string(xs[0].Response) != `{"ok":true}` || xs[0].Status != http.StatusCreated {
+		t.Fatalf("captured %+v, want only the response", xs)
+	}
+	if bytes.Contains(b.Bytes(), []byte("secret")) {
+		t.Errorf("redacted password written: %s", b.Bytes())
+	}
+}
+
+func TestCaptureFlush(t *testing.T) {
+	var b bytes.Buffer
+	c := &Capture{W: &b}
+	h := c.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprintln(w, `{"n": 1}`)
+		if _, ok := w.(http.Flusher); !ok {
+			t.Error("the captured response writer is not a http.Flusher")
+		}
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Error(err)
+		}
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/events", nil))
+	if !w.Flushed {
+		t.Error("the response was not flushed")
+	}
+}
+
+// failingWriter fails all writes.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestCaptureWriteError(t *testing.T) {
+	c := &Capture{W: failingWriter{}}
+	h := c.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{}`)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if err := c.Err(); err == nil || err.Error() != "disk full" {
+		t.Errorf("Err() = %v, want disk full", err)
+	}
+}
+
+func TestCapturePerRouteRequiresRoutes(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Handler did not panic on PerRoute without Endpoints or Route")
+		}
+	}()
+	(&Capture{W: &bytes.Buffer{}, PerRoute: 1}).Handler(http.NotFoundHandler())
+}
+
+func TestCapturedExamples(t *testing.T) {
+	captures := filepath.Join(t.TempDir(), "captures.jsonl")
+	lines := `{"method":"GET","path":"/item/1","status":200,"response":{"id":"wrong type"}}
+{"method":"GET","path":"/item/2","status":500,"response":{"id":5}}
+
+{"method":"GET","path":"/item/3","status":200,"response":{"id":7}}
+`
+	if err := os.WriteFile(captures, []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src := "package api\n\ntype Item struct {\n\tID int `json:\"id\"`\n}\n"
+	tmpl := "{{endpoint \"GET\" \"/item/{id}\"}}\n\n{{output \"Item\"}}\n"
+	d := newTestDoc(t, src, tmpl, Options{Captures: captures})
+	var b bytes.Buffer
+	if err := d.WriteMarkdown(&b); err != nil {
+		t.Fatal(err)
+	}
+	s := html.UnescapeString(b.String())
+	if !strings.Contains(s, "Example captured from real traffic") || !strings.Contains(s, `"id": 7`) {
+		t.Errorf("captured example of /item/3 not found in\n%s", s)
+	}
+	if d.Stats().Warnings != 1 {
+		t.Errorf("got %d warnings, want 1 (for the response of the wrong type)", d.Stats().Warnings)
+	}
+}
+
+func TestReadCapturesError(t *testing.T) {
+	captures := filepath.Join(t.TempDir(), "captures.jsonl")
+	if err := os.WriteFile(captures, []byte("{}\nnot JSON\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readCaptures(captures); err == nil || !strings.Contains(err.Error(), "captures.jsonl:2:") {
+		t.Errorf("readCaptures error = %v, want one at line 2", err)
+	}
+}
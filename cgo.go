@@ -0,0 +1,44 @@
+package jsondoc
+
+import (
+	"go/ast"
+)
+
+// Packages using cgo are parsed (not compiled) so their files are
+// documented even if cgo is disabled (see buildContext) and the types of
+// the "C" pseudo-package are not imported but described by their names:
+// the numeric C types are encoded as JSON numbers.
+
+// cNumeric lists the numeric types of the "C" pseudo-package (true for
+// the floating point ones).
+var cNumeric = map[string]bool{
+	"char": false, "schar": false, "uchar": false, "short": false, "ushort": false,
+	"int": false, "uint": false, "long": false, "ulong": false, "longlong": false, "ulonglong": false,
+	"size_t": false, "ssize_t": false, "intptr_t": false, "uintptr_t": false,
+	"int8_t": false, "int16_t": false, "int32_t": false, "int64_t": false,
+	"uint8_t": false, "uint16_t": false, "uint32_t": false, "uint64_t": false,
+	"float": true, "double": true,
+}
+
+// cType returns the name of the C type (such as "int" of C.int) if t
+// refers to the "C" pseudo-package of cgo.
+func (d *JSONDoc) cType(t ast.Expr, c *context) (string, bool) {
+	sel, ok := t.(*ast.SelectorExpr)
+	if !ok || c == nil || c.File == nil {
+		return "", false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	path, err := d.findImportIdent(c.File, ident.Name)
+	return sel.Sel.Name, err == nil && path == "C"
+}
+
+// cTypeJSON returns the JSON representation of the C type (HTML).
+func cTypeJSON(name string) string {
+	if _, ok := cNumeric[name]; ok {
+		return "number (C numeric type)"
+	}
+	return "unknown (C type declared in the cgo preamble)"
+}
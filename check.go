@@ -0,0 +1,255 @@
+package jsondoc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Fixture is the request sent to an endpoint by Check instead of the
+// one generated from the documentation.
+type Fixture struct {
+	Path   string          `json:"path"`   // URL path with the path parameters substituted (may include a query)
+	Body   json.RawMessage `json:"body"`   // JSON input (the sample of the input type if empty)
+	Status int             `json:"status"` // expected status (any 2xx status if zero)
+	Skip   bool            `json:"skip"`   // do not call the endpoint
+}
+
+// CheckOptions configure Check.
+type CheckOptions struct {
+	BaseURL     string             // base URL of the checked server
+	Fixtures    map[string]Fixture // map: endpoint (such as "GET /item/{id}") -> fixture
+	Header      http.Header        // headers of all the requests (such as Authorization)
+	Client      *http.Client       // a client with the Timeout if nil
+	Timeout     time.Duration      // timeout of the requests of the default client (30 seconds if zero)
+	StreamLines int                // number of lines of NDJSON outputs read and checked (10 if zero)
+
+	// Methods lists the unsafe methods (such as "POST" or "DELETE")
+	// of the endpoints called with generated requests. Endpoints
+	// with other methods than GET, HEAD and OPTIONS which are not
+	// listed are only called with a fixture, so that by default the
+	// state of the server is not changed.
+	Methods []string
+}
+
+// defaultCheckTimeout is the timeout of the requests of Check if not
+// given.
+const defaultCheckTimeout = 30 * time.Second
+
+// defaultStreamLines is the number of lines of NDJSON outputs checked
+// if not given.
+const defaultStreamLines = 10
+
+// CheckResult is the result of checking a single endpoint.
+type CheckResult struct {
+	Endpoint string   // such as "GET /item/{id}"
+	Status   int      // status of the response
+	Problems []string // differences from the documentation
+	Skipped  string   // the reason the endpoint was not called (if it was not)
+}
+
+// Check calls the documented endpoints of a live server (with the
+// generated requests or the given fixtures) and verifies that their
+// responses structurally match the documented outputs: unknown
+// fields, values of wrong types and missing required fields are
+// reported as problems.
+func (d *JSONDoc) Check(opts CheckOptions) ([]CheckResult, error) {
+	c, err := d.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer d.end(c)
+	return c.check(opts)
+}
+
+func (d *JSONDoc) check(opts CheckOptions) ([]CheckResult, error) {
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: opts.Timeout}
+		if client.Timeout == 0 {
+			client.Timeout = defaultCheckTimeout
+		}
+	}
+	var results []CheckResult
+	for _, e := range d.endpoints {
+		r, err := d.checkEndpoint(e, opts, client)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+func (d *JSONDoc) checkEndpoint(e *endpoint, opts CheckOptions, client *http.Client) (CheckResult, error) {
+	r := CheckResult{Endpoint: e.Title()}
+	f, fixture := opts.Fixtures[e.Title()]
+	switch {
+	case f.Skip:
+		r.Skipped = "skipped by the fixture"
+	case !fixture && !safeMethod(e.Method) && !containsString(opts.Methods, e.Method):
+		r.Skipped = e.Method + " is only called with a fixture (unless enabled with the methods option)"
+	case e.codec != nil:
+		r.Skipped = e.codec.ContentType + " is not supported"
+	case e.Input != "" && !e.jsonInput():
+		r.Skipped = e.InputContentType + " input is not supported"
+	case f.Path == "" && strings.Contains(e.Path, "{"):
+		r.Skipped = "path parameters require a fixture"
+	}
+	if r.Skipped != "" {
+		return r, nil
+	}
+	path := f.Path
+	if path == "" {
+		path = e.Path
+	}
+	var body io.Reader
+	if len(f.Body) > 0 {
+		body = bytes.NewReader(f.Body)
+	} else if e.Input != "" {
+		v, err := d.inputSample(e)
+		if err != nil {
+			return r, err
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return r, err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(e.Method, strings.TrimSuffix(opts.BaseURL, "/")+path, body)
+	if err != nil {
+		return r, err
+	}
+	for k, v := range opts.Header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		r.Problems = append(r.Problems, err.Error())
+		return r, nil
+	}
+	defer resp.Body.Close()
+	var b []byte
+	if e.OutputContentType == ndjsonContentType {
+		// the stream may stay open for a long time
+		b, err = readLines(resp.Body, opts.StreamLines)
+	} else {
+		b, err = io.ReadAll(resp.Body)
+	}
+	if err != nil {
+		r.Problems = append(r.Problems, err.Error())
+		return r, nil
+	}
+	r.Status = resp.StatusCode
+	success := r.Status >= 200 && r.Status <= 299
+	if f.Status == 0 && !success || f.Status != 0 && r.Status != f.Status {
+		r.Problems = append(r.Problems, fmt.Sprintf("unexpected status %d", r.Status))
+		return r, nil
+	}
+	switch {
+	case !success && e.errorStatus(r.Status):
+		problems, err := d.checkJSON(b, e.Error, "", "")
+		if err != nil {
+			return r, err
+		}
+		r.Problems = append(r.Problems, problems...)
+	case !success:
+		// an expected error response
+	case e.File != nil:
+		if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, e.File.ContentType) {
+			r.Problems = append(r.Problems, fmt.Sprintf("unexpected content type %q of the file (expected %s)", ct, e.File.ContentType))
+		}
+		if e.File.maxSize > 0 && int64(len(b)) > e.File.maxSize {
+			r.Problems = append(r.Problems, fmt.Sprintf("size %d of the file exceeds the maximum size %s", len(b), e.File.MaxSize))
+		}
+	case e.Output == "":
+		if e.NoOutputStatus != 0 && f.Status == 0 && r.Status != e.NoOutputStatus {
+			r.Problems = append(r.Problems, fmt.Sprintf("unexpected status %d (expected %d)", r.Status, e.NoOutputStatus))
+		}
+		if len(bytes.TrimSpace(b)) > 0 {
+			r.Problems = append(r.Problems, "unexpected response body (no output is documented)")
+		}
+	case e.OutputContentType == ndjsonContentType:
+		for i, line := range bytes.Split(b, []byte("\n")) {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			problems, err := d.checkJSON(line, e.Output, e.Envelope, e.Container)
+			if err != nil {
+				return r, err
+			}
+			for _, p := range problems {
+				r.Problems = append(r.Problems, fmt.Sprintf("line %d: %s", i+1, p))
+			}
+		}
+	case e.OutputContentType != "":
+		// only JSON (and NDJSON) outputs are checked
+	default:
+		problems, err := d.checkJSON(b, e.Output, e.Envelope, e.Container)
+		if err != nil {
+			return r, err
+		}
+		r.Problems = append(r.Problems, problems...)
+	}
+	return r, nil
+}
+
+// checkJSON returns the problems of the JSON response b with the value
+// of the named type (see checkByName).
+func (d *JSONDoc) checkJSON(b []byte, name, env, container string) ([]string, error) {
+	v, err := decodeJSON(b)
+	if err != nil {
+		return []string{"invalid JSON response: " + err.Error()}, nil
+	}
+	return d.checkByName(name, env, container, v)
+}
+
+// safeMethod reports whether requests of the method do not change the
+// state of the server.
+func safeMethod(method string) bool {
+	return method == "GET" || method == "HEAD" || method == "OPTIONS"
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, t := range list {
+		if t == s {
+			return true
+		}
+	}
+	return false
+}
+
+// readLines reads at most n lines (defaultStreamLines if n is zero) of
+// the stream r. It fails only if no complete line could be read (such
+// as when the timeout of the client expires first).
+func readLines(r io.Reader, n int) ([]byte, error) {
+	if n == 0 {
+		n = defaultStreamLines
+	}
+	var b []byte
+	br := bufio.NewReader(r)
+	for i := 0; i < n; i++ {
+		line, err := br.ReadBytes('\n')
+		if err == io.EOF {
+			return append(b, line...), nil
+		}
+		if err != nil {
+			if len(b) == 0 {
+				return nil, fmt.Errorf("no line of the stream read: %v", err)
+			}
+			break
+		}
+		b = append(b, line...)
+	}
+	return b, nil
+}
@@ -0,0 +1,135 @@
+package jsondoc
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const checkSrc = `package api
+
+type Item struct {
+	ID   int    ` + "`json:\"id\"`" + `
+	Name string ` + "`json:\"name\"`" + `
+}
+`
+
+const checkTmpl = `{{endpoint "GET" "/item"}}
+
+{{output "Item"}}
+
+{{endpoint "GET" "/changes"}}
+
+{{outputStream "Item" "the server never closes the connection."}}
+
+{{endpoint "GET" "/slow"}}
+
+{{output "Item"}}
+
+{{endpoint "POST" "/item"}}
+
+{{input "Item"}}
+
+{{output "Item"}}
+
+{{endpoint "DELETE" "/item"}}
+`
+
+// checkServer returns a server of the endpoints of checkTmpl with
+// posted counting the calls of the POST and DELETE endpoints.
+func checkServer(t *testing.T, posted *atomic.Int32) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/item", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			posted.Add(1)
+		}
+		if r.Method == "DELETE" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id": 1, "name": "pen", "price": 3}`)
+	})
+	mux.HandleFunc("/changes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprintln(w, `{"id": 1, "name": "pen"}`)
+		fmt.Fprintln(w, `{"id": "2", "name": "ink"}`)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done() // the stream stays open
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(10 * time.Second):
+		case <-r.Context().Done():
+		}
+	})
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestCheck(t *testing.T) {
+	var posted atomic.Int32
+	s := checkServer(t, &posted)
+	d := newTestDoc(t, checkSrc, checkTmpl, Options{})
+	start := time.Now()
+	results, err := d.Check(CheckOptions{BaseURL: s.URL, Timeout: 500 * time.Millisecond, StreamLines: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("Check took %v", time.Since(start))
+	}
+	got := make(map[string]CheckResult)
+	for _, r := range results {
+		got[r.Endpoint] = r
+	}
+	if r := got["GET /item"]; len(r.Problems) != 1 || !strings.Contains(r.Problems[0], "price") {
+		t.Errorf("GET /item: got problems %q, want the unknown price field", r.Problems)
+	}
+	if r := got["GET /changes"]; len(r.Problems) != 1 || !strings.HasPrefix(r.Problems[0], "line 2:") {
+		t.Errorf("GET /changes: got problems %q, want the id of line 2", r.Problems)
+	}
+	if r := got["GET /slow"]; len(r.Problems) != 1 || !strings.Contains(r.Problems[0], "Timeout") {
+		t.Errorf("GET /slow: got problems %q, want a timeout", r.Problems)
+	}
+	for _, e := range []string{"POST /item", "DELETE /item"} {
+		if got[e].Skipped == "" {
+			t.Errorf("%s: called without a fixture", e)
+		}
+	}
+	if n := posted.Load(); n != 0 {
+		t.Errorf("unsafe endpoints called %d times", n)
+	}
+}
+
+func TestCheckMethods(t *testing.T) {
+	var posted atomic.Int32
+	s := checkServer(t, &posted)
+	d := newTestDoc(t, checkSrc, checkTmpl, Options{})
+	opts := CheckOptions{
+		BaseURL:  s.URL,
+		Fixtures: map[string]Fixture{"DELETE /item": {Status: http.StatusNoContent}, "GET /slow": {Skip: true}},
+		Methods:  []string{"POST"},
+		Timeout:  500 * time.Millisecond, // the stream is read until the timeout
+	}
+	results, err := d.Check(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Endpoint == "GET /changes" && len(r.Problems) != 1 {
+			t.Errorf("GET /changes: got problems %q, want the id of line 2", r.Problems)
+		}
+		if (r.Endpoint == "POST /item" || r.Endpoint == "DELETE /item") && r.Skipped != "" {
+			t.Errorf("%s: skipped: %s", r.Endpoint, r.Skipped)
+		}
+	}
+	if n := posted.Load(); n != 2 {
+		t.Errorf("unsafe endpoints called %d times, want 2", n)
+	}
+}
@@ -0,0 +1,481 @@
+package jsondoc
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// WriteClient writes Go source of a client package (with the given
+// name) for the documented endpoints. The package contains (exported)
+// copies of the input and output types (and types they refer to) so
+// that it does not depend on the documented packages.
+func (d *JSONDoc) WriteClient(w io.Writer, pkgName string) error {
+	c, err := d.begin()
+	if err != nil {
+		return err
+	}
+	defer d.end(c)
+	return c.writeClient(w, pkgName)
+}
+
+func (d *JSONDoc) writeClient(w io.Writer, pkgName string) error {
+	g := &clientGen{d: d, names: make(map[*ast.TypeSpec]string), used: make(map[string]bool), override: make(map[ast.Expr]string),
+		imports:     map[string]string{"bytes": "", "context": "", "encoding/json": "", "fmt": "", "io": "", "net/http": ""},
+		importNames: map[string]bool{"bytes": true, "context": true, "json": true, "fmt": true, "io": true, "http": true, "url": true}}
+	var methods bytes.Buffer
+	usedMethods := make(map[string]bool)
+	for _, e := range d.endpoints {
+		if e.InputContentType != "" {
+			d.warnf("warning: %s: %s input is not supported, method not generated\n", e.Title(), e.InputContentType)
+			continue
+		}
+		if e.OutputContentType != "" {
+			d.warnf("warning: %s: %s output is not supported, method not generated\n", e.Title(), e.OutputContentType)
+			continue
+		}
+		in, out := "", ""
+		if e.Input != "" {
+			ts, c, err := d.lookupTypeName(e.Input)
+			if err != nil {
+				return err
+			}
+			in = g.typeName(ts, c)
+		}
+		if e.Output != "" {
+			ts, c, err := d.lookupTypeName(e.Output)
+			if err != nil {
+				return err
+			}
+			out = g.typeName(ts, c)
+			if e.Envelope != "" {
+				if out, err = g.envelopeName(e.Envelope, out, e.Container); err != nil {
+					return err
+				}
+			} else {
+				out = containerGoType(e.Container, out)
+			}
+		}
+		name := clientMethodName(e)
+		for i := 2; usedMethods[name]; i++ {
+			name = fmt.Sprintf("%s%d", clientMethodName(e), i)
+		}
+		usedMethods[name] = true
+		writeClientMethod(&methods, e, name, in, out)
+	}
+	for i := 0; i < len(g.queue); i++ {
+		if err := g.genType(g.queue[i].t, g.queue[i].c); err != nil {
+			return err
+		}
+	}
+	if usesPathParams(d.endpoints) {
+		g.imports["net/url"] = ""
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by jsondoc; DO NOT EDIT.\n\n// Package %s is a client of the API described in %s.\npackage %s\n\nimport (\n", pkgName, d.tmplName, pkgName)
+	var imports []string
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	for _, imp := range imports {
+		if name := g.imports[imp]; name != "" {
+			fmt.Fprintf(&b, "%s %q\n", name, imp)
+		} else {
+			fmt.Fprintf(&b, "%q\n", imp)
+		}
+	}
+	b.WriteString(")\n")
+	b.WriteString(clientHeader)
+	b.Write(methods.Bytes())
+	b.Write(g.b.Bytes())
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting generated code: %v", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+const clientHeader = `
+// Client sends requests to the API.
+type Client struct {
+	BaseURL    string       // such as "https://api.example.com"
+	HTTPClient *http.Client // http.DefaultClient is used if nil
+}
+
+// NewClient returns a client sending requests to the given base URL.
+func NewClient(baseURL string) *Client {
+	return &Client{BaseURL: baseURL}
+}
+
+// Error is returned for responses with status code other than 2xx.
+type Error struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), bytes.TrimSpace(e.Body))
+}
+
+// do sends the request with JSON encoded input (if in is not nil) and
+// decodes JSON output to out (if out is not nil).
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return &Error{resp.StatusCode, b}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+`
+
+// clientMethodName returns the name of the client method for the
+// endpoint, such as PostItemGet for "POST /item/get".
+func clientMethodName(e *endpoint) string {
+	return exportedName(strings.ToLower(e.Method) + " " + e.Path)
+}
+
+// exportedName returns an exported Go identifier composed of the
+// letters and digits of s (the letters following other characters
+// are capitalized).
+func exportedName(s string) string {
+	var b bytes.Buffer
+	upper := true
+	for _, c := range s {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			upper = true
+			continue
+		}
+		if b.Len() == 0 && unicode.IsDigit(c) {
+			b.WriteByte('X')
+		}
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// pathParams returns the names of the path parameters (in braces) of
+// the endpoint path.
+func pathParams(path string) []string {
+	var params []string
+	for _, s := range strings.Split(path, "/") {
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			params = append(params, s[1:len(s)-1])
+		}
+	}
+	return params
+}
+
+func usesPathParams(endpoints []*endpoint) bool {
+	for _, e := range endpoints {
+		if len(pathParams(e.Path)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func writeClientMethod(b *bytes.Buffer, e *endpoint, name, in, out string) {
+	var params []string
+	path := fmt.Sprintf("%q", e.Path)
+	if ps := pathParams(e.Path); len(ps) > 0 {
+		var args []string
+		format := e.Path
+		for _, p := range ps {
+			arg := goParamName(p)
+			params = append(params, arg+" string")
+			args = append(args, "url.PathEscape("+arg+")")
+			format = strings.Replace(format, "{"+p+"}", "%s", 1)
+		}
+		path = fmt.Sprintf("fmt.Sprintf(%q, %s)", format, strings.Join(args, ", "))
+	}
+	if in != "" {
+		params = append(params, "in *"+in)
+	}
+	fmt.Fprintf(b, "\n// %s sends %s request.\nfunc (c *Client) %s(ctx context.Context", name, e.Title(), name)
+	for _, p := range params {
+		b.WriteString(", " + p)
+	}
+	inArg := "nil"
+	if in != "" {
+		inArg = "in"
+	}
+	if out == "" {
+		fmt.Fprintf(b, ") error {\nreturn c.do(ctx, %q, %s, %s, nil)\n}\n", e.Method, path, inArg)
+		return
+	}
+	fmt.Fprintf(b, ") (*%s, error) {\nvar out %s\nif err := c.do(ctx, %q, %s, %s, &out); err != nil {\nreturn nil, err\n}\nreturn &out, nil\n}\n",
+		out, out, e.Method, path, inArg)
+}
+
+// goParamName returns a Go identifier for the path parameter name.
+func goParamName(s string) string {
+	s = exportedName(s)
+	if s == "" {
+		return "param"
+	}
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+type clientGen struct {
+	d       *JSONDoc
+	b       bytes.Buffer
+	names   map[*ast.TypeSpec]string // names of the copied types
+	used    map[string]bool
+	queue   []queueElem
+	imports map[string]string // import paths -> names given to them (empty for the package names)
+
+	// importNames are the names the imported packages are referred to.
+	importNames map[string]bool
+
+	// override maps type expressions to the Go types used instead of
+	// them (the data field of an envelope).
+	override map[ast.Expr]string
+}
+
+// typeName returns the (exported) name of the copy of the named type
+// scheduling its generation if needed.
+func (g *clientGen) typeName(t *ast.TypeSpec, c *context) string {
+	if s := g.names[t]; s != "" {
+		return s
+	}
+	name := exportedName(t.Name.Name)
+	if g.used[name] {
+		name += exportedName(g.d.packageName(c.Path))
+	}
+	for i := 2; g.used[name]; i++ {
+		name = fmt.Sprintf("%s%d", exportedName(t.Name.Name), i)
+	}
+	g.used[name] = true
+	g.names[t] = name
+	g.queue = append(g.queue, queueElem{t: t, c: c})
+	return name
+}
+
+// envelopeName returns the name of the copy of the envelope type env
+// in which the data field is of type out (in the container, if any)
+// generating it if needed.
+func (g *clientGen) envelopeName(env, out, container string) (string, error) {
+	t, c, err := g.d.lookupTypeName(env)
+	if err != nil {
+		return "", err
+	}
+	f, _, err := g.d.envelopeField(t, c)
+	if err != nil {
+		return "", err
+	}
+	name, typ := exportedName(t.Name.Name)+out, "*"+out
+	switch container {
+	case "array":
+		name, typ = name+"List", containerGoType(container, out)
+	case "object":
+		name, typ = name+"Map", containerGoType(container, out)
+	}
+	if g.used[name] {
+		return name, nil
+	}
+	g.used[name] = true
+	g.override[f.Type] = typ
+	s, err := g.goType(t.Type, c)
+	delete(g.override, f.Type)
+	if err != nil {
+		return "", fmt.Errorf("type %s: %v", t.Name.Name, err)
+	}
+	fmt.Fprintf(&g.b, "\n// %s is a copy of %s.%s with data of type %s.\ntype %s %s\n", name, g.d.packageName(c.Path), t.Name.Name, strings.TrimPrefix(typ, "*"), name, s)
+	return name, nil
+}
+
+// containerGoType returns the Go type of the values of type out in the
+// container (see endpoint.Container).
+func containerGoType(container, out string) string {
+	switch container {
+	case "array":
+		return "[]" + out
+	case "object":
+		return "map[string]" + out
+	}
+	return out
+}
+
+func (g *clientGen) genType(t *ast.TypeSpec, c *context) error {
+	name := g.names[t]
+	s, err := g.goType(t.Type, c)
+	if err != nil {
+		return fmt.Errorf("type %s: %v", t.Name.Name, err)
+	}
+	fmt.Fprintf(&g.b, "\n// %s is a copy of %s.%s.\ntype %s %s\n", name, g.d.packageName(c.Path), t.Name.Name, name, s)
+	return nil
+}
+
+// goType returns Go source of the type expression referring to the
+// copies of the named types.
+func (g *clientGen) goType(t ast.Expr, c *context) (string, error) {
+	if s, ok := g.override[t]; ok {
+		return s, nil
+	}
+	switch t := t.(type) {
+	case *ast.Ident:
+		if builtin[t.Name] {
+			return t.Name, nil
+		}
+		ts, tc, err := g.d.lookupType(t, c)
+		if err != nil {
+			return "", err
+		}
+		if ts == nil {
+			return t.Name, nil
+		}
+		return g.typeName(ts, tc), nil
+	case *ast.SelectorExpr:
+		if _, ok := g.d.cType(t, c); ok {
+			return "", fmt.Errorf("type %s: cgo types are not supported", types.ExprString(t))
+		}
+		if ident, ok := t.X.(*ast.Ident); ok {
+			path, err := g.d.findImportIdent(c.File, ident.Name)
+			if err != nil {
+				return "", err
+			}
+			if isStdPackage(path) {
+				name, err := g.importStd(path)
+				if err != nil {
+					return "", err
+				}
+				return name + "." + t.Sel.Name, nil
+			}
+		}
+		ts, tc, err := g.d.lookupType(t, c)
+		if err != nil {
+			return "", err
+		}
+		if ts == nil {
+			return "", fmt.Errorf("type %s: not found", types.ExprString(t))
+		}
+		return g.typeName(ts, tc), nil
+	case *ast.StarExpr:
+		s, err := g.goType(t.X, c)
+		return "*" + s, err
+	case *ast.ArrayType:
+		s, err := g.goType(t.Elt, c)
+		if t.Len == nil {
+			return "[]" + s, err
+		}
+		return "[" + types.ExprString(t.Len) + "]" + s, err
+	case *ast.MapType:
+		k, err := g.goType(t.Key, c)
+		if err != nil {
+			return "", err
+		}
+		v, err := g.goType(t.Value, c)
+		return "map[" + k + "]" + v, err
+	case *ast.InterfaceType:
+		return "interface{}", nil
+	case *ast.StructType:
+		var b bytes.Buffer
+		b.WriteString("struct {\n")
+		for _, f := range t.Fields.List {
+			s, err := g.goType(f.Type, c)
+			if err != nil {
+				return "", err
+			}
+			var names []string
+			for _, ident := range f.Names {
+				if ast.IsExported(ident.Name) {
+					names = append(names, ident.Name)
+				}
+			}
+			if len(f.Names) > 0 && len(names) == 0 {
+				continue
+			}
+			b.WriteString(strings.Join(names, ", "))
+			b.WriteString(" " + s)
+			if f.Tag != nil {
+				b.WriteString(" " + f.Tag.Value)
+			}
+			if text := parseFieldComment(f).Description; text != "" {
+				b.WriteString(" // " + strings.Replace(text, "\n", " ", -1))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("}")
+		return b.String(), nil
+	}
+	return "", fmt.Errorf("unsupported type %s", types.ExprString(t))
+}
+
+// importStd imports the standard library package with the given path
+// into the client and returns the name it is referred to (the name of
+// the package, such as rand for math/rand/v2, unless it is already
+// used by another package).
+func (g *clientGen) importStd(path string) (string, error) {
+	if name, ok := g.imports[path]; ok {
+		if name == "" {
+			name = path[strings.LastIndexByte(path, '/')+1:]
+		}
+		return name, nil
+	}
+	p, err := g.d.importPackage(path, 0)
+	if err != nil {
+		return "", err
+	}
+	name := p.Name
+	for i := 2; g.importNames[name]; i++ {
+		name = fmt.Sprintf("%s%d", p.Name, i)
+	}
+	g.importNames[name] = true
+	g.imports[path] = ""
+	if name != p.Name || name != path[strings.LastIndexByte(path, '/')+1:] {
+		g.imports[path] = name
+	}
+	return name, nil
+}
+
+// isStdPackage reports whether the package with the given import path
+// is a part of the standard library.
+func isStdPackage(path string) bool {
+	i := strings.IndexByte(path, '/')
+	if i == -1 {
+		i = len(path)
+	}
+	return !strings.Contains(path[:i], ".")
+}
@@ -0,0 +1,119 @@
+package jsondoc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const clientSrc = `package api
+
+import (
+	"math/rand"
+	randv2 "math/rand/v2"
+	"time"
+)
+
+type Item struct {
+	ID      int          ` + "`json:\"id\"`" + `
+	Name    string       ` + "`json:\"name\"`" + ` // name of the item
+	Updated time.Time    ` + "`json:\"updated\"`" + `
+	Tags    []Tag        ` + "`json:\"tags,omitempty\"`" + `
+	Source  *randv2.PCG  ` + "`json:\"-\"`" + `
+	Old     *rand.Rand   ` + "`json:\"-\"`" + `
+}
+
+type Tag string
+`
+
+const clientTmpl = `{{endpoint "POST" "/item"}}
+
+{{input "Item"}}
+
+{{output "Item"}}
+
+{{endpoint "GET" "/item/{id}"}}
+
+{{output "Item"}}
+
+{{endpoint "DELETE" "/item/{id}"}}
+`
+
+// clientTestSrc tests the client generated for clientSrc.
+const clientTestSrc = `package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /item":
+			var in Item
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+				t.Error(err)
+			}
+			in.ID = 7
+			json.NewEncoder(w).Encode(in)
+		case "GET /item/a b":
+			json.NewEncoder(w).Encode(Item{ID: 8, Tags: []Tag{"x"}})
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+	c := NewClient(s.URL)
+	out, err := c.PostItem(context.Background(), &Item{Name: "pen"})
+	if err != nil || out.ID != 7 || out.Name != "pen" {
+		t.Errorf("PostItem: %+v, %v", out, err)
+	}
+	out, err = c.GetItemId(context.Background(), "a b")
+	if err != nil || out.ID != 8 || len(out.Tags) != 1 {
+		t.Errorf("GetItemId: %+v, %v", out, err)
+	}
+	if err := c.DeleteItemId(context.Background(), "1"); err == nil || err.(*Error).StatusCode != http.StatusNotFound {
+		t.Errorf("DeleteItemId: got error %v, want 404", err)
+	}
+}
+`
+
+func TestWriteClient(t *testing.T) {
+	d, dir := newTestPackageDoc(t, clientSrc, clientTmpl, Options{})
+	var b bytes.Buffer
+	if err := d.WriteClient(&b, "client"); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"rand \"math/rand/v2\"", "rand2 \"math/rand\"", "*rand.PCG", "*rand2.Rand", "time.Time"} {
+		if !strings.Contains(b.String(), s) {
+			t.Errorf("%s not found in the client", s)
+		}
+	}
+	client := filepath.Join(dir, "client")
+	if err := os.Mkdir(client, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(client, "client.go"), b.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(client, "client_test.go"), []byte(clientTestSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGo(t, dir, "vet", "./client")
+	runGo(t, dir, "test", "-count=1", "./client")
+}
+
+func TestWriteClientCgo(t *testing.T) {
+	src := "package api\n\nimport \"C\"\n\ntype Input struct {\n\tN C.int `json:\"n\"`\n}\n"
+	d := newTestDoc(t, src, "{{endpoint \"POST\" \"/input\"}}\n\n{{input \"Input\"}}\n", Options{})
+	var b bytes.Buffer
+	if err := d.WriteClient(&b, "client"); err == nil || !strings.Contains(err.Error(), "cgo") {
+		t.Errorf("got error %v, want one about the cgo type", err)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lukpank/jsondoc"
+)
+
+// checkMain implements the check command verifying that the responses
+// of a live server match the documentation.
+func checkMain(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	baseURL := fs.String("base-url", "", "base URL of the checked server")
+	fixtures := fs.String("fixtures", "", `JSON file with the requests sent to endpoints (map: "METHOD /path" -> fixture)`)
+	seed := fs.Int64("seed", 1, "seed for fake values in generated requests")
+	captures := fs.String("captures", "", "file with requests recorded by jsondoc.Capture used as generated requests")
+	methods := fs.String("methods", "", `comma separated unsafe methods (such as "POST,PUT") of the endpoints called with generated requests (other than GET, HEAD and OPTIONS endpoints are only called with fixtures by default)`)
+	timeout := fs.Duration("timeout", 30*time.Second, "timeout of each request")
+	streamLines := fs.Int("stream-lines", 10, "number of lines of NDJSON outputs read and checked")
+	header := make(http.Header)
+	fs.Var(headerFlag(header), "header", `header added to all requests (such as "Authorization: Bearer token", may be repeated)`)
+	options := optionsFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: jsondoc check [flags] template.md")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatal("error: missing argument: a markdown template for the documentation")
+	}
+	if *baseURL == "" {
+		log.Fatal("error: missing -base-url of the checked server")
+	}
+	opts := jsondoc.CheckOptions{BaseURL: *baseURL, Header: header, Timeout: *timeout, StreamLines: *streamLines}
+	if *methods != "" {
+		opts.Methods = strings.Split(strings.ToUpper(*methods), ",")
+	}
+	if *fixtures != "" {
+		b, err := os.ReadFile(*fixtures)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := json.Unmarshal(b, &opts.Fixtures); err != nil {
+			log.Fatalf("fixtures %s: %v", *fixtures, err)
+		}
+	}
+	o := options()
+	o.Seed = *seed
+	o.Captures = *captures
+	d, err := jsondoc.New(fs.Arg(0), o)
+	if err != nil {
+		log.Fatal(err)
+	}
+	results, err := d.Check(opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	failed := 0
+	for _, r := range results {
+		switch {
+		case r.Skipped != "":
+			fmt.Printf("skip %s: %s\n", r.Endpoint, r.Skipped)
+		case len(r.Problems) == 0:
+			fmt.Printf("ok   %s (%d)\n", r.Endpoint, r.Status)
+		default:
+			failed++
+			fmt.Printf("FAIL %s (%d)\n", r.Endpoint, r.Status)
+			for _, p := range r.Problems {
+				fmt.Printf("    %s\n", p)
+			}
+		}
+	}
+	if failed > 0 {
+		log.Fatalf("%d of %d endpoints do not match the documentation", failed, len(results))
+	}
+}
+
+// headerFlag collects the values of a repeated "Name: value" flag.
+type headerFlag http.Header
+
+func (h headerFlag) String() string {
+	return ""
+}
+
+func (h headerFlag) Set(s string) error {
+	i := strings.IndexByte(s, ':')
+	if i <= 0 {
+		return fmt.Errorf("expected \"Name: value\", got %q", s)
+	}
+	http.Header(h).Add(strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:]))
+	return nil
+}
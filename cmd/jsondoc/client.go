@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/lukpank/jsondoc"
+)
+
+// clientMain implements the client command generating a Go client
+// package for the documented endpoints.
+func clientMain(args []string) {
+	fs := flag.NewFlagSet("client", flag.ExitOnError)
+	output := fs.String("o", "", "output file name")
+	pkg := fs.String("package", "client", "name of the generated package")
+	options := optionsFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: jsondoc client [flags] template.md")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatal("error: missing argument: a markdown template for the documentation")
+	}
+	d, err := jsondoc.New(fs.Arg(0), options())
+	if err != nil {
+		log.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := d.WriteClient(&b, *pkg); err != nil {
+		log.Fatal(err)
+	}
+	writeOutput(*output, b.Bytes())
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/lukpank/jsondoc"
+)
+
+// crossCheckMain implements the crosscheck command comparing an
+// existing OpenAPI description with the documented Go types.
+func crossCheckMain(args []string) {
+	fs := flag.NewFlagSet("crosscheck", flag.ExitOnError)
+	options := optionsFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: jsondoc crosscheck [flags] openapi.yaml template.md")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	d, err := jsondoc.New(fs.Arg(1), options())
+	if err != nil {
+		log.Fatal(err)
+	}
+	mismatches, err := d.CrossCheck(fs.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, m := range mismatches {
+		fmt.Println(m)
+	}
+	if len(mismatches) > 0 {
+		log.Fatalf("%d mismatches between %s and the Go types", len(mismatches), fs.Arg(0))
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/lukpank/jsondoc"
+)
+
+// embedMain implements the embed command writing the generated
+// documentation together with a Go source file embedding it (with
+// go:embed) so that a service may serve its own documentation.
+func embedMain(args []string) {
+	fs := flag.NewFlagSet("embed", flag.ExitOnError)
+	output := fs.String("o", "docs.go", "output Go file name (the HTML file is written to the same directory)")
+	htmlName := fs.String("html", "docs.html", "name of the HTML file with the documentation")
+	pkg := fs.String("package", "docs", "name of the package of the Go file")
+	varName := fs.String("var", "HTML", "name of the variable with the documentation")
+	seed := fs.Int64("seed", 1, "seed for fake values in samples")
+	minify := fs.Bool("minify", false, "minify the documentation")
+	mermaidJS := fs.String("mermaid-js", jsondoc.DefaultMermaidJS, "URL of the mermaid ES module or a local mermaid.min.js file to embed, used to render mermaid diagrams")
+	options := optionsFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: jsondoc embed [flags] template.md")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatal("error: missing argument: a markdown template for the documentation")
+	}
+	if !token.IsIdentifier(*pkg) || !token.IsIdentifier(*varName) {
+		log.Fatal("error: -package and -var must be Go identifiers")
+	}
+	if filepath.Base(*htmlName) != *htmlName {
+		log.Fatal("error: -html must be a file name (without a directory)")
+	}
+	opts := options()
+	opts.Seed = *seed
+	opts.MermaidJS = *mermaidJS
+	opts.Minify = *minify
+	d, err := jsondoc.New(fs.Arg(0), opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var b bytes.Buffer
+	if _, err := d.WriteTo(&b); err != nil {
+		log.Fatal(err)
+	}
+	writeOutput(filepath.Join(filepath.Dir(*output), *htmlName), b.Bytes())
+	src, err := embedSource(*pkg, *varName, *htmlName, filepath.Base(fs.Arg(0)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	writeOutput(*output, src)
+}
+
+// embedSource returns Go source of the package pkgName with the
+// variable varName holding the contents of the named HTML file
+// (embedded with go:embed).
+func embedSource(pkgName, varName, htmlName, tmplName string) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by jsondoc; DO NOT EDIT.\n\npackage %s\n\nimport _ \"embed\"\n\n", pkgName)
+	fmt.Fprintf(&b, "// %s is the HTML documentation generated from %s.\n//\n//go:embed %s\nvar %s []byte\n", varName, tmplName, htmlName, varName)
+	return format.Source(b.Bytes())
+}
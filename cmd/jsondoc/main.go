@@ -12,523 +12,288 @@
 package main
 
 import (
-	"bytes"
 	"errors"
 	"flag"
 	"fmt"
-	"go/ast"
-	"go/build"
-	"go/parser"
-	"go/token"
-	"html"
-	"io"
 	"log"
 	"os"
 	"path/filepath"
-	"reflect"
-	"strconv"
+	"runtime"
+	"runtime/pprof"
 	"strings"
-	"text/template"
 
-	"github.com/russross/blackfriday"
+	"github.com/lukpank/jsondoc"
 )
 
 func main() {
-	output := flag.String("o", "", "output file name")
-	flag.Parse()
 	log.SetFlags(0)
-	if flag.NArg() == 0 {
-		log.Fatal("error: missing argument: a markdown template for the documentation")
-	}
-	d, err := NewJSONDoc(flag.Arg(0))
-	if err != nil {
-		log.Fatal(err)
-	}
-	out := os.Stdout
-	if *output != "" {
-		out, err = os.Create(*output)
-		if err != nil {
-			log.Fatal("error: could not open output file: ", err)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "mock":
+			mockMain(os.Args[2:])
+			return
+		case "validators":
+			validatorsMain(os.Args[2:])
+			return
+		case "client":
+			clientMain(os.Args[2:])
+			return
+		case "embed":
+			embedMain(os.Args[2:])
+			return
+		case "check":
+			checkMain(os.Args[2:])
+			return
+		case "roundtrip":
+			roundTripMain(os.Args[2:])
+			return
+		case "validate":
+			validateMain(os.Args[2:])
+			return
+		case "semver":
+			semverMain(os.Args[2:])
+			return
+		case "crosscheck":
+			crossCheckMain(os.Args[2:])
+			return
 		}
 	}
-	if _, err := d.WriteTo(out); err != nil {
+	if err := run(); err != nil {
 		log.Fatal(err)
 	}
 }
 
-type JSONDoc struct {
-	imports      map[string]string       // map: local in template name -> package path
-	packages     map[string]*ast.Package // map: package path -> package AST
-	packageNames map[string]string       // map: package path -> package name (may be obtained without parsing the package)
-	t            *template.Template
-	tmplName     string
-	table        *template.Template
-	b            bytes.Buffer
-	rendered     map[renderedElem]string
-	renderQueue  []queueElem
-	links        map[string]map[ast.Expr]int
-	title        string
-}
-
-type queueElem struct {
-	t  *ast.TypeSpec
-	c  *context
-	id string
-}
-
-type renderedElem struct {
-	Name string
-	Obj  *ast.Object
-}
-
-func NewJSONDoc(filename string) (*JSONDoc, error) {
-	d := &JSONDoc{rendered: make(map[renderedElem]string), links: make(map[string]map[ast.Expr]int),
-		packages: make(map[string]*ast.Package), packageNames: make(map[string]string), imports: make(map[string]string)}
-	d.t = template.New("").Funcs(template.FuncMap{"input": d.input, "output": d.output, "title": d.setTitle, "import": d.importPkg})
-	if _, err := d.t.ParseFiles(filename); err != nil {
-		return nil, err
-	}
-	d.table = template.New("table")
-	if _, err := d.table.Parse(table); err != nil {
-		return nil, err
-	}
-	d.tmplName = filepath.Base(filename)
-	return d, nil
-}
-
-const htmlFlags = blackfriday.HTML_TOC
-
-const commonExtensions = 0 |
-	blackfriday.EXTENSION_NO_INTRA_EMPHASIS |
-	blackfriday.EXTENSION_TABLES |
-	blackfriday.EXTENSION_FENCED_CODE |
-	blackfriday.EXTENSION_AUTOLINK |
-	blackfriday.EXTENSION_STRIKETHROUGH |
-	blackfriday.EXTENSION_SPACE_HEADERS |
-	blackfriday.EXTENSION_HEADER_IDS |
-	blackfriday.EXTENSION_BACKSLASH_LINE_BREAK |
-	blackfriday.EXTENSION_DEFINITION_LISTS
-
-func (d *JSONDoc) WriteTo(w io.Writer) (int64, error) {
-	var b bytes.Buffer
-	if err := d.t.ExecuteTemplate(&b, d.tmplName, nil); err != nil {
-		return 0, err
-	}
-	out := blackfriday.Markdown(b.Bytes(), blackfriday.HtmlRenderer(htmlFlags, "", ""), commonExtensions)
-	b.Reset()
-	err := htmlHeaderTmpl.Execute(&b, html.EscapeString(d.title))
-	var n, m, o int
-	if err == nil {
-		n, err = w.Write(b.Bytes())
-	}
-	if err == nil {
-		m, err = w.Write(out)
+// run generates the documentation as given by the command line flags.
+// The profiles are written (by the deferred calls) also if it fails.
+func run() (err error) {
+	output := flag.String("o", "", "output file name")
+	anchors := flag.String("anchors", "", "also write a JSON manifest of all anchors to the given file")
+	try := flag.Bool("try", false, `embed a "Try it" console sending requests to endpoints`)
+	baseURL := flag.String("base-url", "", `base URL of the API used by the "Try it" console`)
+	seed := flag.Int64("seed", 1, "seed for fake values in samples")
+	stampFlag := flag.Bool("stamp", false, "embed generation metadata (jsondoc version, git commit, time) in the output")
+	commit := flag.String("commit", "", "git commit of the documented module for -stamp (obtained with git if empty)")
+	components := flag.Bool("components", false, `render types referenced from many endpoints once in "Common objects"`)
+	openapi := flag.String("openapi", "", "also write an OpenAPI description of the endpoints to the given file")
+	openapiViewer := flag.String("openapi-viewer", "", `also write a page browsing the -openapi description with the given viewer ("redoc", "swagger-ui" or "elements" for Stoplight Elements with the description inlined) to the file of the same name with the .html extension`)
+	har := flag.String("har", "", "also write an HTTP Archive (HAR) of sample requests and responses of the endpoints to the given file")
+	markdown := flag.String("markdown", "", "also write the markdown document (with the documented types as HTML blocks) to the given file")
+	engine := flag.String("engine", "goldmark", `markdown engine: "goldmark" (CommonMark) or "blackfriday" (compatible with older versions)`)
+	mermaidJS := flag.String("mermaid-js", jsondoc.DefaultMermaidJS, "URL of the mermaid ES module or a local mermaid.min.js file to embed, used to render mermaid diagrams")
+	minify := flag.Bool("minify", false, "minify the output requiring all assets (styles and scripts) to be embedded in it")
+	cover := flag.Bool("cover", false, "generate a cover page (with the title, the API version and the date) of the printed documentation")
+	layout := flag.String("layout", "table", `presentation of the fields of objects: "table", "annotated" (a JSON skeleton with a comment per field) or "list" (a definition list for narrow layouts)`)
+	jsonv2 := flag.Bool("jsonv2", false, "interpret the json struct tags as encoding/json/v2: single-quoted keys, the inline, unknown, format and case:ignore options and keys matched case-sensitively")
+	qualifyTypes := flag.Bool("qualify-types", false, `qualify the headings and anchors of types with the import name of their package in the template (such as "Type items.size") to distinguish types of the same name`)
+	swag := flag.Bool("swag", false, "read the swaggo/swag annotations (@Summary, @Description, @Param, @Success, @Failure and @Router) of the handlers documented with the handler action")
+	logo := flag.String("logo", "", "URL or local `file` (embedded) of the logo image shown in the header bar (overrides the configuration)")
+	header := flag.String("header", "", "`HTML` of the header bar (overrides the configuration)")
+	footer := flag.String("footer", "", "`HTML` of the footer, such as legal notices and support links (overrides the configuration)")
+	highContrast := flag.Bool("high-contrast", false, "high-contrast theme (for readers with low vision)")
+	rtl := flag.Bool("rtl", false, "right-to-left layout (mirrored navigation and tables) for documentation in languages such as Arabic and Hebrew")
+	fragment := flag.Bool("fragment", false, "write only the content of the body (without the head and styles) to be embedded in another page")
+	captures := flag.String("captures", "", "file with requests and responses recorded by jsondoc.Capture used as examples")
+	stats := flag.Bool("stats", false, "print statistics of the generated documentation (endpoints, types, fields without description, external types and warnings)")
+	timings := flag.Bool("timings", false, "print the times of the phases of the generation (parsing and type checking the packages, resolving the types and rendering)")
+	cpuProfile := flag.String("cpuprofile", "", "write a CPU profile (for go tool pprof) to the given file")
+	memProfile := flag.String("memprofile", "", "write a memory (heap) profile (for go tool pprof) to the given file")
+	embedStats := flag.Bool("embed-stats", false, "embed statistics of the generated documentation in the footer of the HTML output")
+	dot := flag.String("dot", "", "also write the dependency graph of the documented types in the DOT language (of Graphviz) to the given file")
+	dumpGraph := flag.Bool("dump-graph", false, "print the dependency graph of the endpoints and the types they refer to instead of the documentation")
+	unused := flag.Bool("unused", false, "after rendering report imports and exported types of the imported packages which are not referenced")
+	options := optionsFlags(flag.CommandLine)
+	flag.Parse()
+	if flag.NArg() == 0 {
+		return errors.New("error: missing argument: a markdown template for the documentation")
 	}
-	if err == nil {
-		o, err = io.WriteString(w, htmlFooter)
+	if *cpuProfile != "" {
+		f, err := os.Create(*cpuProfile)
+		if err != nil {
+			return fmt.Errorf("error: could not open CPU profile file: %v", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return err
+		}
+		defer pprof.StopCPUProfile()
 	}
-	return int64(n) + int64(m) + int64(o), err
-}
-
-func (d *JSONDoc) setTitle(title string) string {
-	d.title = title
-	return ""
-}
-
-func (d *JSONDoc) importPkg(name, path string) (string, error) {
-	if d.imports[name] != "" {
-		return "", fmt.Errorf("name %s already imported", name)
+	if *memProfile != "" {
+		defer func() {
+			if merr := writeMemProfile(*memProfile); err == nil {
+				err = merr
+			}
+		}()
 	}
-	if _, err := d.parsedPackage(path); err != nil {
-		return "", err
+	viewerFile := ""
+	if *openapiViewer != "" {
+		if *openapi == "" {
+			return errors.New("error: -openapi-viewer requires -openapi")
+		}
+		if !contains(jsondoc.OpenAPIViewers(), *openapiViewer) {
+			return fmt.Errorf("error: unknown OpenAPI viewer %q (supported: %s)", *openapiViewer, strings.Join(jsondoc.OpenAPIViewers(), ", "))
+		}
+		viewerFile = strings.TrimSuffix(*openapi, filepath.Ext(*openapi)) + ".html"
+		if viewerFile == *output {
+			return fmt.Errorf("error: the OpenAPI viewer page %s would overwrite the output", viewerFile)
+		}
 	}
-	d.imports[name] = path
-	return "", nil
-}
-
-func (d *JSONDoc) input(name string) (string, error) {
-	d.b.Reset()
-	fmt.Fprintf(&d.b, "### Input (%s)\n<div>\n", markdownEscapeString(name))
-	if err := d.renderTypes(name); err != nil {
-		return "", err
+	opts := options()
+	opts.Try = *try
+	opts.BaseURL = *baseURL
+	opts.Seed = *seed
+	opts.Commit = *commit
+	opts.Captures = *captures
+	opts.Components = *components
+	opts.Engine = *engine
+	opts.MermaidJS = *mermaidJS
+	opts.Minify = *minify
+	opts.Fragment = *fragment
+	opts.Stamp = *stampFlag
+	opts.Stats = *embedStats
+	opts.RTL = *rtl
+	opts.HighContrast = *highContrast
+	opts.Cover = *cover
+	opts.Logo = *logo
+	opts.Layout = *layout
+	opts.Header = *header
+	opts.Footer = *footer
+	opts.JSONv2 = *jsonv2
+	opts.QualifyTypes = *qualifyTypes
+	opts.Swag = *swag
+	d, err := jsondoc.New(flag.Arg(0), opts)
+	if err != nil {
+		return err
 	}
-	d.b.WriteString("</div>\n")
-	return d.b.String(), nil
-}
-
-func (d *JSONDoc) output(name string) (string, error) {
-	d.b.Reset()
-	ident := name
-	if i := strings.LastIndexByte(name, '.'); i != -1 {
-		ident = name[i+1:]
+	if *dumpGraph {
+		return d.WriteGraph(os.Stdout)
 	}
-	fmt.Fprintf(&d.b, "### Output (%s)\n<div>\n", markdownEscapeString(ident))
-	if err := d.renderTypes(name); err != nil {
-		return "", err
+	out := os.Stdout
+	if *output != "" {
+		out, err = os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("error: could not open output file: %v", err)
+		}
 	}
-	d.b.WriteString("</div>\n")
-	return d.b.String(), nil
-}
-
-func (d *JSONDoc) renderTypes(name string) error {
-	if err := d.renderTypeByName(name); err != nil {
+	if _, err := d.WriteTo(out); err != nil {
 		return err
 	}
-	for i := 0; i < len(d.renderQueue); i++ {
-		q := d.renderQueue[i]
-		fmt.Fprintf(&d.b, "<h4 id=\"%s\">Type %s</h4>\n", html.EscapeString(q.id), html.EscapeString(q.t.Name.Name))
-		if err := d.renderType(q.t, q.c); err != nil {
+	if out != os.Stdout {
+		if err := out.Close(); err != nil {
 			return err
 		}
 	}
-	d.renderQueue = d.renderQueue[:0]
-	return nil
-}
-
-func (d *JSONDoc) renderTypeByName(name string) error {
-	pkgName := "."
-	i := strings.LastIndexByte(name, '.')
-	if i != -1 {
-		pkgName = name[:i]
-		name = name[i+1:]
-	}
-	path := d.imports[pkgName]
-	if path == "" {
-		return fmt.Errorf("name %s mast be imported to access %s", pkgName, name)
-	}
-	o, c, err := d.findObject(name, d.packages[path], path)
-	if o == nil {
-		return fmt.Errorf("Type %s error: %v", name, err)
-	}
-	t, ok := o.Decl.(*ast.TypeSpec)
-	if !ok {
-		return fmt.Errorf("Object named %s is not a type", name)
-	}
-	return d.renderType(t, c)
-}
-
-type field struct {
-	Name, Type, Description string
-}
-
-func (d *JSONDoc) renderType(typ *ast.TypeSpec, c *context) error {
-	return d.renderType1(typ.Type, c, "")
-}
-
-func (d *JSONDoc) renderType1(typ ast.Expr, c *context, prefix string) error {
-	switch t := typ.(type) {
-	case *ast.StructType:
-		fields, err := d.appendFields(nil, t, c)
+	if *unused {
+		imports, types, err := d.Unused()
 		if err != nil {
 			return err
 		}
-		s := ""
-		if prefix != "" {
-			s = "s"
+		for _, name := range imports {
+			log.Printf("warning: package of import %s is not referenced", name)
 		}
-		if len(fields) > 0 {
-			type data struct {
-				Prefix, S string
-				Fields    []field
-			}
-			d.table.ExecuteTemplate(&d.b, "table", data{prefix, s, fields})
-		} else {
-			fmt.Fprintf(&d.b, "<p>JSON %sobject%s with no fields.</p>\n", prefix, s)
+		for _, name := range types {
+			log.Printf("warning: type %s is not referenced", name)
 		}
-	case *ast.MapType:
-		ident, ok := t.Key.(*ast.Ident)
-		if !ok || ident.Name != "string" {
-			return errors.New("only maps with string keys are supported")
+	}
+	if *anchors != "" {
+		f, err := os.Create(*anchors)
+		if err != nil {
+			return fmt.Errorf("error: could not open anchors file: %v", err)
 		}
-		if prefix == "" {
-			prefix = "object of "
-		} else {
-			prefix = prefix + " objects of "
+		if err := d.WriteAnchors(f); err != nil {
+			return err
 		}
-		return d.renderType1(t.Value, c, prefix)
-	case *ast.ArrayType:
-		if prefix == "" {
-			prefix = "array of "
-		} else {
-			prefix = prefix + " arrays of "
+		if err := f.Close(); err != nil {
+			return err
 		}
-		return d.renderType1(t.Elt, c, prefix)
 	}
-	return nil
-}
-
-func (d *JSONDoc) appendFields(fields []field, t *ast.StructType, c *context) ([]field, error) {
-	for _, f := range t.Fields.List {
-		if len(f.Names) == 0 {
-			o, c, err := d.findObject(f.Type.(*ast.Ident).Name, c.Package, c.Path)
-			if err != nil {
-				return nil, err
-			}
-			if o == nil {
-				continue
-			}
-			t, ok := o.Decl.(*ast.TypeSpec)
-			if !ok {
-				continue
-			}
-			if t, ok := t.Type.(*ast.StructType); ok {
-				var err error
-				fields, err = d.appendFields(fields, t, c)
-				if err != nil {
-					return nil, err
-				}
-			}
+	if *openapi != "" {
+		f, err := os.Create(*openapi)
+		if err != nil {
+			return fmt.Errorf("error: could not open OpenAPI file: %v", err)
 		}
-		for _, indent := range f.Names {
-			name, err := tagToName(indent.Name, f.Tag)
-			if err != nil {
-				if err == NotExported {
-					continue
-				}
-				return nil, err
-			}
-			fields = append(fields, field{html.EscapeString(name), d.typeLink(f.Type, c, name, ""),
-				html.EscapeString(strings.TrimSpace(f.Comment.Text()))})
+		if err := d.WriteOpenAPI(f); err != nil {
+			return err
 		}
-	}
-	return fields, nil
-}
-
-type context struct {
-	Path    string
-	Package *ast.Package
-	File    *ast.File
-}
-
-func (d *JSONDoc) findObject(name string, pkg *ast.Package, path string) (*ast.Object, *context, error) {
-	for _, f := range pkg.Files {
-		if o := f.Scope.Objects[name]; o != nil {
-			return o, &context{path, pkg, f}, nil
+		if err := f.Close(); err != nil {
+			return err
 		}
 	}
-	if builtin[name] {
-		return nil, nil, nil
-	}
-	return nil, nil, fmt.Errorf("identifier %s not found in package %s", name, path)
-}
-
-func (d *JSONDoc) parsedPackage(path string) (*ast.Package, error) {
-	if pkg := d.packages[path]; pkg != nil {
-		return pkg, nil
-	}
-	p, err := build.Import(path, "", 0)
-	if err != nil {
-		return nil, err
-	}
-	fset := token.NewFileSet()
-	pkg, err := parser.ParseDir(fset, filepath.Join(p.SrcRoot, path), notTest, parser.ParseComments)
-	if err != nil {
-		return nil, err
-	}
-	if len(pkg) > 1 {
-		return nil, fmt.Errorf("more than one package in directory %s", path)
-	}
-	for _, p := range pkg {
-		d.packages[path] = p
-		d.packageNames[path] = p.Name
-		return p, nil
-	}
-	return nil, fmt.Errorf("package %s is empty", path)
-}
-
-func notTest(info os.FileInfo) bool {
-	return !strings.HasSuffix(info.Name(), "_test.go")
-}
-
-var builtin = map[string]bool{
-	"bool":       true,
-	"byte":       true,
-	"complex128": true,
-	"complex64":  true,
-	"error":      true,
-	"float32":    true,
-	"float64":    true,
-	"int":        true,
-	"int16":      true,
-	"int32":      true,
-	"int64":      true,
-	"int8":       true,
-	"rune":       true,
-	"string":     true,
-	"uint":       true,
-	"uint16":     true,
-	"uint32":     true,
-	"uint64":     true,
-	"uint8":      true,
-	"uintptr":    true,
-}
-
-func (d *JSONDoc) typeLink(t ast.Expr, c *context, name string, suffix string) string {
-	switch t := t.(type) {
-	case *ast.ArrayType:
-		if !strings.HasSuffix(name, "-element") {
-			name = name + "-element"
-		}
-		return fmt.Sprintf("array%s of %s", suffix, d.typeLink(t.Elt, c, name, "s"))
-	case *ast.MapType:
-		ident, ok := t.Key.(*ast.Ident)
-		if !ok || ident.Name != "string" {
-			return "(error: only maps with string keys are supported)"
+	if viewerFile != "" {
+		f, err := os.Create(viewerFile)
+		if err != nil {
+			return fmt.Errorf("error: could not open OpenAPI viewer file: %v", err)
 		}
-		if !strings.HasSuffix(name, "-element") {
-			name = name + "-element"
+		if err := d.WriteOpenAPIViewer(f, *openapiViewer, filepath.Base(*openapi)); err != nil {
+			return err
 		}
-		return fmt.Sprintf("object%s of %s", suffix, d.typeLink(t.Value, c, name, "s"))
-	case *ast.Ident:
-		if ID := d.renderLater(t.Name, nil, c); ID != "" {
-			return fmt.Sprintf(`<a href="#%s">%s</a>`, html.EscapeString(ID), html.EscapeString(t.Name))
+		if err := f.Close(); err != nil {
+			return err
 		}
-		return html.EscapeString(t.Name)
-	case *ast.StructType:
-		if strings.HasSuffix(name, "-element") {
-			ID := d.renderLater(name, t, c)
-			return fmt.Sprintf(`<a href="#%s">%s</a>`, html.EscapeString(ID), html.EscapeString(name))
+	}
+	if *har != "" {
+		f, err := os.Create(*har)
+		if err != nil {
+			return fmt.Errorf("error: could not open HAR file: %v", err)
 		}
-		ID := d.renderLater("of "+name, t, c)
-		return fmt.Sprintf(`<a href="#%s">type of %s</a>`, html.EscapeString(ID), html.EscapeString(name))
-	case *ast.SelectorExpr:
-		ident, ok := t.X.(*ast.Ident)
-		if !ok {
-			fmt.Fprintf(os.Stderr, "type %v: expected identifier before '.'\n", t)
-			return html.EscapeString(fmt.Sprint(t))
+		if err := d.WriteHAR(f); err != nil {
+			return err
 		}
-		path, err := d.findImportIdent(c.File, ident.Name)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "type %s.%s: %v\n", ident.Name, t.Sel.Name, err)
-			return html.EscapeString(fmt.Sprintf("%s.%s", ident.Name, t.Sel.Name))
+		if err := f.Close(); err != nil {
+			return err
 		}
-		pkg, err := d.parsedPackage(path)
+	}
+	if *markdown != "" {
+		f, err := os.Create(*markdown)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "type %s.%s: %v\n", ident.Name, t.Sel.Name, err)
-			return html.EscapeString(fmt.Sprintf("%s.%s", ident.Name, t.Sel.Name))
+			return fmt.Errorf("error: could not open markdown file: %v", err)
 		}
-		_, c, err := d.findObject(t.Sel.Name, pkg, path)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "type %s.%s: %v\n", ident.Name, t.Sel.Name, err)
-			return html.EscapeString(fmt.Sprintf("%s.%s", ident.Name, t.Sel.Name))
+		if err := d.WriteMarkdown(f); err != nil {
+			return err
 		}
-		if ID := d.renderLater(t.Sel.Name, nil, c); ID != "" {
-			return fmt.Sprintf(`<a href="#%s">%s</a>`, html.EscapeString(ID), html.EscapeString(t.Sel.Name))
+		if err := f.Close(); err != nil {
+			return err
 		}
-		return html.EscapeString(fmt.Sprintf("%s.%s", ident.Name, t.Sel.Name))
-	default:
-		return html.EscapeString(fmt.Sprint(t))
 	}
-}
-
-func (d *JSONDoc) findImportIdent(file *ast.File, name string) (string, error) {
-	for _, imp := range file.Imports {
-		path := imp.Path.Value[1 : len(imp.Path.Value)-1]
-		if imp.Name != nil {
-			if imp.Name.Name == name {
-				return path, nil
-			}
-			continue
-		}
-		s := d.packageNames[path]
-		if s == "" {
-			p, err := build.Import(path, "", 0)
-			if err != nil {
-				return "", err
-			}
-			s = p.Name
-			d.packageNames[path] = s
+	if *dot != "" {
+		f, err := os.Create(*dot)
+		if err != nil {
+			return fmt.Errorf("error: could not open DOT file: %v", err)
 		}
-		if s == name {
-			return path, nil
+		if err := d.WriteDOT(f); err != nil {
+			return err
 		}
-	}
-	return "", fmt.Errorf(`package named %s not found (may need to run "go build -i")`, name)
-}
-
-func (d *JSONDoc) renderLater(name string, t ast.Expr, c *context) string {
-	if t != nil {
-		s := "type-" + strings.Replace(name, " ", "-", -1)
-		if d.links[s] == nil {
-			d.links[s] = make(map[ast.Expr]int)
+		if err := f.Close(); err != nil {
+			return err
 		}
-		i := len(d.links[s]) + 1
-		d.links[s][t] = i
-		s = fmt.Sprintf("%s-%d", s, i)
-		d.renderQueue = append(d.renderQueue, queueElem{&ast.TypeSpec{Name: &ast.Ident{Name: name}, Type: t}, c, s})
-		return s
 	}
-	o, c, err := d.findObject(name, c.Package, c.Path)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return ""
-	}
-	if o == nil {
-		return ""
+	if *stats {
+		log.Printf("jsondoc: %v", d.Stats())
 	}
-	if s := d.rendered[renderedElem{name, o}]; s != "" {
-		return s
+	if *timings {
+		log.Printf("jsondoc: %v", d.Timings())
 	}
-	if t, ok := o.Decl.(*ast.TypeSpec); ok {
-		s := "type-" + name
-		if d.links[s] == nil {
-			d.links[s] = make(map[ast.Expr]int)
-		}
-		i := len(d.links[s]) + 1
-		d.links[s][t.Type] = i
-		s = fmt.Sprintf("%s-%d", s, i)
-		d.renderQueue = append(d.renderQueue, queueElem{t, c, s})
-		d.rendered[renderedElem{name, o}] = s
-		return s
-	}
-	return ""
+	return nil
 }
 
-var NotExported = errors.New("Not exported")
-
-func tagToName(name string, tag *ast.BasicLit) (string, error) {
-	if !ast.IsExported(name) {
-		return "", NotExported
-	}
-	if tag != nil {
-		s, err := strconv.Unquote(tag.Value)
-		if err != nil {
-			return "", err
-		}
-		s = reflect.StructTag(s).Get("json")
-		if s == "" {
-			return strconv.Quote(name), nil
-		}
-		fields := strings.Split(s, ",")
-		if fields[0] == "-" {
-			return "", NotExported
-		}
-		suffix := ""
-		for _, f := range fields[1:] {
-			if f == "omitempty" {
-				suffix = " (optional)"
-			}
-		}
-		return strconv.Quote(fields[0]) + suffix, nil
+// writeMemProfile writes the heap profile to the named file.
+func writeMemProfile(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error: could not open memory profile file: %v", err)
 	}
-	return strconv.Quote(name), nil
-}
-
-var isASCIIPunctuation [128]bool
-
-func init() {
-	for _, c := range "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~." {
-		isASCIIPunctuation[c] = true
+	runtime.GC() // up-to-date statistics
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		f.Close()
+		return err
 	}
+	return f.Close()
 }
 
-func markdownEscapeString(s string) string {
-	var b bytes.Buffer
-	for _, c := range s {
-		if c < 128 && isASCIIPunctuation[c] {
-			b.WriteByte('\\')
+// contains reports whether the list contains s.
+func contains(list []string, s string) bool {
+	for _, t := range list {
+		if t == s {
+			return true
 		}
-		b.WriteRune(c)
 	}
-	return b.String()
+	return false
 }
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/lukpank/jsondoc"
+)
+
+// mockMain implements the mock command serving sample responses for
+// all the endpoints documented in the given template.
+func mockMain(args []string) {
+	fs := flag.NewFlagSet("mock", flag.ExitOnError)
+	addr := fs.String("addr", ":9090", "address to listen on")
+	seed := fs.Int64("seed", 1, "seed for fake values in samples")
+	captures := fs.String("captures", "", "file with requests and responses recorded by jsondoc.Capture served as outputs")
+	options := optionsFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: jsondoc mock [flags] template.md")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatal("error: missing argument: a markdown template for the documentation")
+	}
+	opts := options()
+	opts.Seed = *seed
+	opts.Captures = *captures
+	d, err := jsondoc.New(fs.Arg(0), opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	h, err := d.MockHandler()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("serving %d endpoints on %s", len(d.Endpoints()), *addr)
+	log.Fatal(http.ListenAndServe(*addr, h))
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/lukpank/jsondoc"
+)
+
+// optionsFlags defines the flags of the options common to all commands
+// (loading the packages and the configuration of the documentation) in
+// fs and returns a function returning the options given by them after
+// fs is parsed.
+func optionsFlags(fs *flag.FlagSet) func() jsondoc.Options {
+	config := fs.String("config", "", "JSON file with project configuration")
+	partials := fs.String("partials", "", "directory of templates parsed together with the documentation template (for use with the template action)")
+	pkg := fs.String("pkg", "", `import path or directory of the package imported as "." unless the template imports it (the package in the current directory by default)`)
+	tests := fs.Bool("tests", false, "also parse _test.go files of the imported packages (external test packages are imported with the _test suffix)")
+	module := fs.String("module", "", "root directory of the documenting module: packages are resolved from it and its internal packages may be imported")
+	download := fs.Bool("download", false, "download dependencies of the -module missing from the module cache (with go mod download)")
+	at := fs.String("at", "", "git revision (such as a tag or commit) of the -module the documented packages are loaded at")
+	return func() jsondoc.Options {
+		return jsondoc.Options{Config: *config, Partials: *partials, Package: *pkg, Tests: *tests, Module: *module, Download: *download, At: *at}
+	}
+}
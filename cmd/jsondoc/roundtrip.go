@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/lukpank/jsondoc"
+)
+
+// roundTripMain implements the roundtrip command generating a test
+// verifying that encoding/json produces the documented keys.
+func roundTripMain(args []string) {
+	fs := flag.NewFlagSet("roundtrip", flag.ExitOnError)
+	output := fs.String("o", "", "output file name (such as jsondoc_roundtrip_test.go in the directory of the package)")
+	importName := fs.String("import", ".", "template import name of the package to generate the test for")
+	options := optionsFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: jsondoc roundtrip [flags] template.md")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatal("error: missing argument: a markdown template for the documentation")
+	}
+	d, err := jsondoc.New(fs.Arg(0), options())
+	if err != nil {
+		log.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := d.WriteRoundTripTest(&b, *importName); err != nil {
+		log.Fatal(err)
+	}
+	writeOutput(*output, b.Bytes())
+}
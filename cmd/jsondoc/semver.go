@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/lukpank/jsondoc"
+)
+
+// semverMain implements the semver command classifying the changes of
+// the API since the previous release and suggesting the semantic
+// version of the new one.
+func semverMain(args []string) {
+	fs := flag.NewFlagSet("semver", flag.ExitOnError)
+	check := fs.Bool("check", false, "fail if the version of the new API (version of the configuration) is lower than the suggested one")
+	options := optionsFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: jsondoc semver [flags] old-openapi.json (template.md | new-openapi.json)")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	var a *jsondoc.APIDiff
+	if strings.HasSuffix(fs.Arg(1), ".json") {
+		old, err := os.ReadFile(fs.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		new, err := os.ReadFile(fs.Arg(1))
+		if err != nil {
+			log.Fatal(err)
+		}
+		if a, err = jsondoc.DiffOpenAPI(old, new); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		d, err := jsondoc.New(fs.Arg(1), options())
+		if err != nil {
+			log.Fatal(err)
+		}
+		if a, err = d.Diff(fs.Arg(0)); err != nil {
+			log.Fatal(err)
+		}
+	}
+	for _, c := range a.Changes {
+		fmt.Println(c)
+	}
+	if len(a.Changes) == 0 {
+		fmt.Printf("no changes since version %s\n", a.OldVersion)
+		return
+	}
+	suggested, err := a.Suggested()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("suggested version: %s (%s bump since version %s)\n", suggested, a.Bump(), a.OldVersion)
+	if *check {
+		ok, err := a.VersionOK()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !ok {
+			log.Fatalf("error: version %s is lower than the suggested version %s", a.NewVersion, suggested)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/lukpank/jsondoc"
+)
+
+// validateMain implements the validate command reporting all the
+// problems of the template at once (without rendering the
+// documentation).
+func validateMain(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	options := optionsFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: jsondoc validate [flags] template.md")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatal("error: missing argument: a markdown template for the documentation")
+	}
+	d, err := jsondoc.New(fs.Arg(0), options())
+	if err != nil {
+		log.Fatal(err)
+	}
+	errs := d.Validate()
+	for _, err := range errs {
+		log.Printf("error: %v", err)
+	}
+	if len(errs) > 0 {
+		log.Fatalf("%s: %d problem(s) found", fs.Arg(0), len(errs))
+	}
+}
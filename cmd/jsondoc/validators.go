@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/lukpank/jsondoc"
+)
+
+// validatorsMain implements the validators command generating Go
+// validation code for the input types of the documented endpoints.
+func validatorsMain(args []string) {
+	fs := flag.NewFlagSet("validators", flag.ExitOnError)
+	output := fs.String("o", "", "output file name")
+	importName := fs.String("import", ".", "template import name of the package to generate validators for")
+	options := optionsFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: jsondoc validators [flags] template.md")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatal("error: missing argument: a markdown template for the documentation")
+	}
+	d, err := jsondoc.New(fs.Arg(0), options())
+	if err != nil {
+		log.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := d.WriteValidators(&b, *importName); err != nil {
+		log.Fatal(err)
+	}
+	writeOutput(*output, b.Bytes())
+}
+
+// writeOutput writes b to the named file (or to the standard output if
+// name is empty) exiting on errors.
+func writeOutput(name string, b []byte) {
+	if name == "" {
+		os.Stdout.Write(b)
+		return
+	}
+	if err := os.WriteFile(name, b, 0666); err != nil {
+		log.Fatal("error: could not write output file: ", err)
+	}
+}
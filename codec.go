@@ -0,0 +1,274 @@
+package jsondoc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"html"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// codec is a binary encoding of inputs and outputs of endpoints (other
+// than JSON) documented with the same tables as JSON.
+type codec struct {
+	Name        string // shown in the tables, such as "MessagePack"
+	ContentType string
+	Tag         string // struct tag with the object keys
+}
+
+// codecs lists the supported encodings.
+var codecs = []*codec{
+	{"MessagePack", "application/msgpack", "msgpack"},
+	{"MessagePack", "application/x-msgpack", "msgpack"},
+	{"MessagePack", "application/vnd.msgpack", "msgpack"},
+	{"CBOR", "application/cbor", "cbor"},
+}
+
+func findCodec(contentType string) *codec {
+	for _, c := range codecs {
+		if c.ContentType == contentType {
+			return c
+		}
+	}
+	return nil
+}
+
+// Content types of inputs and outputs (other than the codecs) which
+// are not single JSON values.
+const (
+	ndjsonContentType = "application/x-ndjson"
+	csvContentType    = "text/csv"
+	binaryContentType = "application/octet-stream"
+)
+
+// payloadNotes describe how the values of the documented types are
+// sent with the content types.
+var payloadNotes = map[string]string{
+	ndjsonContentType: "Newline delimited JSON: a stream of the JSON values described below (one per line).",
+	csvContentType:    "CSV: a header row with the keys followed by a row for each of the values described below.",
+	binaryContentType: "Raw binary data.",
+}
+
+// contentType sets the content type of the input and output of the
+// current endpoint (application/json if not used) or, if given two
+// content types, of the input and of the output. Object keys of
+// MessagePack and CBOR are taken from the msgpack and cbor struct tags
+// (or the json tags if not present).
+func (d *JSONDoc) contentType(contentTypes ...string) (string, error) {
+	e := d.currentEndpoint()
+	if e == nil {
+		return "", errors.New("contentType must follow an endpoint")
+	}
+	if len(contentTypes) == 0 || len(contentTypes) > 2 {
+		return "", errors.New("contentType: expected the content type (or the content types of the input and output)")
+	}
+	in, out := contentTypes[0], contentTypes[len(contentTypes)-1]
+	e.codec, e.InputContentType, e.OutputContentType = nil, "", ""
+	for i, contentType := range []string{in, out} {
+		switch c := findCodec(contentType); {
+		case contentType == "application/json":
+		case payloadNotes[contentType] != "" && i == 0:
+			e.InputContentType = contentType
+		case payloadNotes[contentType] != "":
+			e.OutputContentType = contentType
+		case c != nil && in != out:
+			return "", fmt.Errorf("contentType: %s must be the content type of both the input and the output", contentType)
+		case c != nil:
+			e.codec = c
+		default:
+			return "", fmt.Errorf("contentType: unsupported content type %q", contentType)
+		}
+	}
+	return "", nil
+}
+
+// outputStream documents the output of the current endpoint as a
+// stream of NDJSON values of type name (sent as they become available,
+// such as with the chunked transfer encoding). The optional argument
+// describes how the stream ends.
+func (d *JSONDoc) outputStream(name string, termination ...string) (string, error) {
+	e := d.currentEndpoint()
+	if e == nil {
+		return "", errors.New("outputStream must follow an endpoint")
+	}
+	if e.codec != nil {
+		return "", fmt.Errorf("outputStream %s: endpoint %s uses %s", name, e.Title(), e.codec.ContentType)
+	}
+	if len(termination) > 1 {
+		return "", fmt.Errorf("outputStream %s: expected at most one description of the end of the stream", name)
+	}
+	e.OutputContentType, e.Termination = ndjsonContentType, strings.Join(termination, "")
+	return d.renderOutput(name, "", "", nil)
+}
+
+// streamLines is the number of values in the example streams.
+const streamLines = 3
+
+// sampleStream returns a sample NDJSON stream of the output of the
+// endpoint.
+func (d *JSONDoc) sampleStream(e *endpoint) ([]byte, error) {
+	var b []byte
+	for i := 0; i < streamLines; i++ {
+		v, err := d.outputSample(e)
+		if err != nil {
+			return nil, err
+		}
+		line, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		b = append(append(b, line...), '\n')
+	}
+	return b, nil
+}
+
+// sampleCSV returns a sample CSV output of the endpoint: a header row
+// with the keys of the sample values (if they are objects, empty
+// optional fields being left out of some of them) followed by a row for
+// each of the values.
+func (d *JSONDoc) sampleCSV(e *endpoint) ([]byte, error) {
+	var values []interface{}
+	var header []string
+	columns := make(map[string]bool)
+	for i := 0; i < streamLines; i++ {
+		v, err := d.sampleByName(e.Output)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		o, _ := v.(object)
+		for _, m := range o {
+			if !columns[m.Key] {
+				columns[m.Key] = true
+				header = append(header, m.Key)
+			}
+		}
+	}
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	if header != nil {
+		w.Write(header)
+	}
+	for _, v := range values {
+		o, ok := v.(object)
+		if !ok {
+			w.Write([]string{textValue(v)})
+			continue
+		}
+		row := make([]string, len(header))
+		for i, key := range header {
+			for _, m := range o {
+				if m.Key == key {
+					row[i] = textValue(m.Value)
+				}
+			}
+		}
+		w.Write(row)
+	}
+	w.Flush()
+	return b.Bytes(), w.Error()
+}
+
+// renderStream writes to d.b how the NDJSON output stream of the
+// endpoint ends (if described) and an example stream.
+func (d *JSONDoc) renderStream(e *endpoint) error {
+	if e.Termination != "" {
+		fmt.Fprintf(&d.b, "<p>End of the stream: %s</p>\n", d.linkTerms(html.EscapeString(e.Termination)))
+	}
+	b, err := d.sampleStream(e)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(&d.b, "<p>Example stream:</p>\n<pre class=\"example\"><code>%s</code></pre>\n", html.EscapeString(string(b)))
+	return nil
+}
+
+// writeContentType writes to d.b the badge with the content type of the
+// input or output (if it is not JSON) and the note on how its values
+// are sent. It reports whether the tables of its type are to be
+// rendered (not for binary data).
+func (d *JSONDoc) writeContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	fmt.Fprintf(&d.b, "<p><span class=\"badge\">%s</span></p>\n", html.EscapeString(contentType))
+	if note := payloadNotes[contentType]; note != "" {
+		fmt.Fprintf(&d.b, "<p>%s</p>\n", note)
+	}
+	return contentType != binaryContentType
+}
+
+// format returns the name of the encoding of the values being rendered.
+func (d *JSONDoc) format() string {
+	if d.codec != nil {
+		return d.codec.Name
+	}
+	return "JSON"
+}
+
+// fieldName returns the object key of the struct field (quoted unless
+// it is an integer key of CBOR) in the encoding of the values being
+// rendered, followed by " (optional)" if it is marked with omitempty
+// (or omitzero).
+func (d *JSONDoc) fieldName(name string, tag *ast.BasicLit) (string, error) {
+	if d.codec == nil {
+		return d.tagToName(name, tag)
+	}
+	key, omitempty, err := d.codec.key(name, tag)
+	if err != nil {
+		return "", err
+	}
+	if !d.codec.intKey(tag) {
+		key = strconv.Quote(key)
+	}
+	if omitempty {
+		key += " (optional)"
+	}
+	return key, nil
+}
+
+// key returns the object key of the struct field in the encoding and
+// whether it is marked with omitempty or omitzero (the json tag is used
+// if the tag of the encoding is not present).
+func (c *codec) key(name string, tag *ast.BasicLit) (key string, omitempty bool, err error) {
+	return tagKey(name, tag, c.tagName(tag))
+}
+
+// tagName returns the key of the struct tag with the object key of the
+// field in the encoding (json if the tag of the encoding is not
+// present).
+func (c *codec) tagName(tag *ast.BasicLit) string {
+	if tag != nil {
+		s, err := strconv.Unquote(tag.Value)
+		if err == nil {
+			if _, ok := reflect.StructTag(s).Lookup(c.Tag); ok {
+				return c.Tag
+			}
+		}
+	}
+	return "json"
+}
+
+// intKey reports whether the field is encoded with an integer key (the
+// keyasint option of the cbor tag).
+func (c *codec) intKey(tag *ast.BasicLit) bool {
+	if c.Tag != "cbor" || tag == nil {
+		return false
+	}
+	s, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return false
+	}
+	opts := strings.Split(reflect.StructTag(s).Get("cbor"), ",")
+	for _, o := range opts[1:] {
+		if o == "keyasint" {
+			return true
+		}
+	}
+	return false
+}
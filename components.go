@@ -0,0 +1,78 @@
+package jsondoc
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+)
+
+// In the components mode (-components) named types referenced from
+// more than one endpoint are rendered once in the "Common objects"
+// chapter (at the place of the components action or at the end of the
+// document) instead of in the section of the endpoint which references
+// them first. To do so the sections of named types are rendered into
+// separate chunks leaving placeholders (HTML comments) in the place
+// they would be rendered in, which are replaced when the whole
+// template was executed and all the references are known.
+
+const componentsPlaceholder = "<!--jsondoc-components-->"
+
+var typePlaceholderRe = regexp.MustCompile(`<!--jsondoc-type:([^>]*)-->\n`)
+
+func typePlaceholder(id string) string {
+	return fmt.Sprintf("<!--jsondoc-type:%s-->\n", id)
+}
+
+// components marks the place of the "Common objects" chapter.
+func (d *JSONDoc) components() string {
+	d.componentsUsed = true
+	return heading(d.headingLevel()) + " Common objects {#components}\n\n<div>\n" + componentsPlaceholder + "\n</div>\n"
+}
+
+// addTypeRef records that the type section with the given id is
+// referenced from the current section.
+func (d *JSONDoc) addTypeRef(id string) {
+	if d.typeRefs[id] == nil {
+		d.typeRefs[id] = make(map[string]bool)
+	}
+	d.typeRefs[id][d.section] = true
+}
+
+// shared reports whether the type section with the given id is to be
+// rendered in the "Common objects" chapter.
+func (d *JSONDoc) shared(id string) bool {
+	return len(d.typeRefs[id]) > 1
+}
+
+// expandChunk replaces type placeholders in b with the sections of the
+// types which are not shared.
+func (d *JSONDoc) expandChunk(b []byte) []byte {
+	return typePlaceholderRe.ReplaceAllFunc(b, func(p []byte) []byte {
+		id := string(typePlaceholderRe.FindSubmatch(p)[1])
+		if d.shared(id) {
+			return nil
+		}
+		return d.expandChunk(d.chunks[id])
+	})
+}
+
+// resolveComponents returns the markdown with the type placeholders
+// replaced and the "Common objects" chapter inserted.
+func (d *JSONDoc) resolveComponents(md []byte) []byte {
+	var common bytes.Buffer
+	for _, id := range d.chunkOrder {
+		if d.shared(id) {
+			common.Write(d.expandChunk(d.chunks[id]))
+		}
+	}
+	md = d.expandChunk(md)
+	if d.componentsUsed {
+		return bytes.Replace(md, []byte(componentsPlaceholder), common.Bytes(), 1)
+	}
+	if common.Len() == 0 {
+		return md
+	}
+	md = append(md, "\n"+heading(d.headingLevel())+" Common objects {#components}\n\n<div>\n"...)
+	md = append(md, common.Bytes()...)
+	return append(md, "</div>\n"...)
+}
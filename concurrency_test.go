@@ -0,0 +1,115 @@
+package jsondoc
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentCalls calls the methods of the same documentation
+// concurrently (run with -race) and checks that all the calls of each
+// of them return the same output.
+func TestConcurrentCalls(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000") // the time of the HAR entries
+	spec := filepath.Join(t.TempDir(), "openapi.json")
+	d, err := New("example/index.md", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.WriteOpenAPI(f); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	d, err = New("example/index.md", Options{}) // not executed yet
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := []struct {
+		name string
+		call func(*bytes.Buffer) error
+	}{
+		{"WriteTo", func(b *bytes.Buffer) error { _, err := d.WriteTo(b); return err }},
+		{"WriteMarkdown", func(b *bytes.Buffer) error { return d.WriteMarkdown(b) }},
+		{"WriteOpenAPI", func(b *bytes.Buffer) error { return d.WriteOpenAPI(b) }},
+		{"WriteOpenAPIViewer", func(b *bytes.Buffer) error { return d.WriteOpenAPIViewer(b, "elements", "") }},
+		{"WriteHAR", func(b *bytes.Buffer) error { return d.WriteHAR(b) }},
+		{"WriteAnchors", func(b *bytes.Buffer) error { return d.WriteAnchors(b) }},
+		{"WriteGraph", func(b *bytes.Buffer) error { return d.WriteGraph(b) }},
+		{"WriteDOT", func(b *bytes.Buffer) error { return d.WriteDOT(b) }},
+		{"WriteClient", func(b *bytes.Buffer) error { return d.WriteClient(b, "client") }},
+		{"WriteValidators", func(b *bytes.Buffer) error { return d.WriteValidators(b, ".") }},
+		{"WriteRoundTripTest", func(b *bytes.Buffer) error { return d.WriteRoundTripTest(b, ".") }},
+		{"Unused", func(b *bytes.Buffer) error {
+			imports, typeNames, err := d.Unused()
+			fmt.Fprint(b, imports, typeNames)
+			return err
+		}},
+		{"Diff", func(b *bytes.Buffer) error {
+			a, err := d.Diff(spec)
+			if err == nil {
+				fmt.Fprint(b, len(a.Changes))
+			}
+			return err
+		}},
+		{"CrossCheck", func(b *bytes.Buffer) error {
+			ms, err := d.CrossCheck(spec)
+			fmt.Fprint(b, ms)
+			return err
+		}},
+		{"MockHandler", func(b *bytes.Buffer) error { _, err := d.MockHandler(); return err }},
+		{"Stats", func(b *bytes.Buffer) error { d.Stats(); d.Timings(); d.Endpoints(); return nil }},
+	}
+	const n = 4
+	outputs := make([][n]string, len(calls))
+	var wg sync.WaitGroup
+	for i, c := range calls {
+		for j := 0; j < n; j++ {
+			wg.Add(1)
+			go func(i, j int, call func(*bytes.Buffer) error) {
+				defer wg.Done()
+				var b bytes.Buffer
+				if err := call(&b); err != nil {
+					t.Errorf("%s: %v", calls[i].name, err)
+					return
+				}
+				outputs[i][j] = b.String()
+			}(i, j, c.call)
+		}
+	}
+	wg.Wait()
+	for i, c := range calls {
+		for j := 1; j < n; j++ {
+			if outputs[i][j] != outputs[i][0] {
+				t.Errorf("%s: outputs of concurrent calls differ", c.name)
+				break
+			}
+		}
+	}
+	if s := d.Stats(); s.Endpoints != len(d.Endpoints()) || s.Endpoints == 0 {
+		t.Errorf("Stats() = %+v for %d endpoints", s, len(d.Endpoints()))
+	}
+}
+
+// TestValidateExecuted checks that Validate reports the template
+// executed already by another method.
+func TestValidateExecuted(t *testing.T) {
+	d, err := New("example/index.md", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.WriteOpenAPI(&bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	if errs := d.Validate(); len(errs) != 1 {
+		t.Errorf("Validate() = %v, want the error of the template executed already", errs)
+	}
+}
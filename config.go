@@ -0,0 +1,120 @@
+package jsondoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Config holds per project settings read from a JSON file (given with
+// -config).
+type Config struct {
+	// Snippets lists languages of request snippets (names as in
+	// snippetLangs) in the order of tabs. All languages except
+	// "httpie" are used if empty.
+	Snippets []string `json:"snippets"`
+
+	// Envelope is the name of the envelope type (as in the envelope
+	// action) all outputs of endpoints are wrapped in.
+	Envelope string `json:"envelope"`
+
+	// Version is the version of the API in the OpenAPI output
+	// ("1.0.0" if empty).
+	Version string `json:"version"`
+
+	// MarkdownExtensions lists the extensions of the goldmark markdown
+	// engine (names as in markdownExtensions). Tables,
+	// strikethrough, linkify, definition lists and footnotes are used
+	// if nil.
+	MarkdownExtensions []string `json:"markdownExtensions"`
+
+	// HeadingLevel is the level of the headings of endpoints and of
+	// the generated chapters (2 if zero). Input and output sections
+	// are one level deeper and types two levels deeper.
+	HeadingLevel int `json:"headingLevel"`
+
+	// Locale (such as "de-DE", names as in locales) selects the
+	// names, addresses and phone numbers of fake values in samples
+	// and the decimal separator and the date format in prose.
+	Locale string `json:"locale"`
+
+	// Logo is the URL or the local file (embedded in the output) of the
+	// logo image shown in the header bar.
+	Logo string `json:"logo"`
+
+	// Header is the HTML of the header bar (next to the logo).
+	Header string `json:"header"`
+
+	// Footer is the HTML of the footer of the page (such as legal
+	// notices and support links).
+	Footer string `json:"footer"`
+
+	// Favicon is the URL or the local file (embedded in the output) of
+	// the icon of the page.
+	Favicon string `json:"favicon"`
+
+	// Description is the description of the page for search engines
+	// and link previews.
+	Description string `json:"description"`
+
+	// URL is the canonical URL of the published documentation.
+	URL string `json:"url"`
+
+	// Image is the absolute URL of the image of link previews (the
+	// Open Graph image).
+	Image string `json:"image"`
+
+	// HandlerPattern is the regular expression matched against the
+	// doc comments of the handlers documented with the handler action
+	// giving the method and the path of their endpoints (with the
+	// submatches named method and path or, otherwise, the first two).
+	// Comments such as "GetItem handles GET /items/{id}" are matched
+	// if empty.
+	HandlerPattern string `json:"handlerPattern"`
+
+	handlerRe *regexp.Regexp // compiled HandlerPattern
+}
+
+// readConfig reads the configuration from the named JSON file.
+func readConfig(filename string) (*Config, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("config %s: %v", filename, err)
+	}
+	for _, s := range c.Snippets {
+		if findSnippetLang(s) == nil {
+			return nil, fmt.Errorf("config %s: unknown snippet language %q", filename, s)
+		}
+	}
+	for _, s := range c.MarkdownExtensions {
+		if markdownExtensions[s] == nil {
+			return nil, fmt.Errorf("config %s: unknown markdown extension %q", filename, s)
+		}
+	}
+	if c.Locale != "" && locales[c.Locale] == nil {
+		return nil, fmt.Errorf("config %s: unknown locale %q (supported: %s)", filename, c.Locale, strings.Join(localeNames(), ", "))
+	}
+	if c.Image != "" && !strings.Contains(c.Image, "://") {
+		return nil, fmt.Errorf("config %s: image %q is not an absolute URL", filename, c.Image)
+	}
+	if c.HeadingLevel < 0 || c.HeadingLevel > maxEndpointLevel {
+		return nil, fmt.Errorf("config %s: heading level %d out of range 1 to %d", filename, c.HeadingLevel, maxEndpointLevel)
+	}
+	if c.HandlerPattern != "" {
+		re, err := regexp.Compile(c.HandlerPattern)
+		if err != nil {
+			return nil, fmt.Errorf("config %s: handler pattern: %v", filename, err)
+		}
+		if re.NumSubexp() < 2 {
+			return nil, fmt.Errorf("config %s: handler pattern %q must have submatches of the method and the path", filename, c.HandlerPattern)
+		}
+		c.handlerRe = re
+	}
+	return &c, nil
+}
@@ -0,0 +1,292 @@
+package jsondoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"io"
+	"math"
+	"time"
+)
+
+// decodeJSON decodes the JSON value preserving the order of object
+// keys (objects are decoded as object, numbers as float64).
+func decodeJSON(b []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	v, err := decodeValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	return v, nil
+}
+
+func decodeValue(dec *json.Decoder) (interface{}, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case json.Delim('{'):
+		o := object{}
+		for dec.More() {
+			k, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			o = append(o, member{k.(string), v})
+		}
+		_, err := dec.Token()
+		return o, err
+	case json.Delim('['):
+		a := []interface{}{}
+		for dec.More() {
+			v, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		_, err := dec.Token()
+		return a, err
+	}
+	return t, nil
+}
+
+// conformance checks whether JSON values (decoded with decodeJSON)
+// have the structure of the documented types.
+type conformance struct {
+	d        *JSONDoc
+	problems []string
+
+	// dataField of the envelope holds values of the type data (in
+	// the container, if any, see endpoint.Container).
+	dataField *ast.Field
+	container string
+	data      *ast.TypeSpec
+	dataCtx   *context
+}
+
+func (k *conformance) problem(path, format string, args ...interface{}) {
+	k.problems = append(k.problems, path+": "+fmt.Sprintf(format, args...))
+}
+
+// checkByName returns the problems of the value v of the named type (in
+// the container, if any, see endpoint.Container) wrapped in the
+// envelope type env (if not empty).
+func (d *JSONDoc) checkByName(name, env, container string, v interface{}) ([]string, error) {
+	k := &conformance{d: d, container: container}
+	t, c, err := d.lookupTypeName(name)
+	if err != nil {
+		return nil, err
+	}
+	if env == "" {
+		k.checkContainer(t, c, v, "$")
+		return k.problems, nil
+	}
+	k.data, k.dataCtx = t, c
+	if t, c, err = d.lookupTypeName(env); err != nil {
+		return nil, err
+	}
+	if k.dataField, _, err = d.envelopeField(t, c); err != nil {
+		return nil, err
+	}
+	k.check(t.Type, c, v, "$")
+	return k.problems, nil
+}
+
+// checkContainer checks the value v holding the values of the named
+// type t in the container.
+func (k *conformance) checkContainer(t *ast.TypeSpec, c *context, v interface{}, path string) {
+	switch k.container {
+	case "array":
+		if k.expect("array", v, path) {
+			for i, e := range v.([]interface{}) {
+				k.check(t.Type, c, e, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	case "object":
+		if k.expect("object", v, path) {
+			for _, m := range v.(object) {
+				k.check(t.Type, c, m.Value, path+"."+m.Key)
+			}
+		}
+	default:
+		k.check(t.Type, c, v, path)
+	}
+}
+
+// jsonKind returns the name of the kind of the JSON value.
+func jsonKind(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64, json.Number:
+		return "number"
+	case string:
+		return "string"
+	case object:
+		return "object"
+	}
+	return "array"
+}
+
+func (k *conformance) expect(kind string, v interface{}, path string) bool {
+	if got := jsonKind(v); got != kind {
+		k.problem(path, "expected %s, got %s", kind, got)
+		return false
+	}
+	return true
+}
+
+func (k *conformance) check(t ast.Expr, c *context, v interface{}, path string) {
+	switch t := t.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string", "error":
+			k.expect("string", v, path)
+			return
+		case "bool":
+			k.expect("boolean", v, path)
+			return
+		case "float32", "float64":
+			k.expect("number", v, path)
+			return
+		case "int", "int8", "int16", "int32", "int64", "rune", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte":
+			if k.expect("number", v, path) {
+				if f, ok := v.(float64); ok && f != math.Trunc(f) {
+					k.problem(path, "expected integer, got %v", f)
+				}
+			}
+			return
+		case "any":
+			return
+		}
+		k.named(t, c, v, path)
+	case *ast.SelectorExpr:
+		if ident, ok := t.X.(*ast.Ident); ok {
+			if p, err := k.d.findImportIdent(c.File, ident.Name); err == nil {
+				switch p + "." + t.Sel.Name {
+				case "time.Time":
+					if k.expect("string", v, path) {
+						if _, err := time.Parse(time.RFC3339Nano, v.(string)); err != nil {
+							k.problem(path, "expected RFC 3339 time, got %q", v)
+						}
+					}
+					return
+				case "time.Duration":
+					k.expect("number", v, path)
+					return
+				case "encoding/json.RawMessage":
+					return
+				}
+			}
+		}
+		k.named(t, c, v, path)
+	case *ast.StarExpr:
+		if v != nil {
+			k.check(t.X, c, v, path)
+		}
+	case *ast.ArrayType:
+		if v == nil {
+			return
+		}
+		if ident, ok := t.Elt.(*ast.Ident); ok && (ident.Name == "byte" || ident.Name == "uint8") {
+			k.expect("string", v, path)
+			return
+		}
+		if k.expect("array", v, path) {
+			for i, e := range v.([]interface{}) {
+				k.check(t.Elt, c, e, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	case *ast.MapType:
+		if v == nil {
+			return
+		}
+		if k.expect("object", v, path) {
+			for _, m := range v.(object) {
+				k.check(t.Value, c, m.Value, path+"."+m.Key)
+			}
+		}
+	case *ast.StructType:
+		if v == nil {
+			return
+		}
+		if k.expect("object", v, path) {
+			o := v.(object)
+			seen := make(map[string]bool)
+			k.fields(t, c, o, seen, path)
+			for _, m := range o {
+				if !seen[m.Key] {
+					k.problem(path, "unknown field %q", m.Key)
+				}
+			}
+		}
+	}
+}
+
+func (k *conformance) named(t ast.Expr, c *context, v interface{}, path string) {
+	ts, c, err := k.d.lookupType(t, c)
+	if err != nil {
+		k.problem(path, "%v", err)
+		return
+	}
+	if ts != nil {
+		k.check(ts.Type, c, v, path)
+	}
+}
+
+// fields checks the members of the object o corresponding to the
+// fields of the struct type (including the fields of embedded structs)
+// recording their keys in seen.
+func (k *conformance) fields(t *ast.StructType, c *context, o object, seen map[string]bool, path string) {
+	for _, f := range t.Fields.List {
+		if typ := k.d.inlined(f); typ != nil {
+			if s, ok := typ.(*ast.StarExpr); ok {
+				typ = s.X
+			}
+			ts, c, err := k.d.lookupType(typ, c)
+			if err != nil || ts == nil {
+				continue
+			}
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				k.fields(st, c, o, seen, path)
+			}
+			continue
+		}
+		for _, ident := range f.Names {
+			key, omitempty, err := k.d.jsonKey(ident.Name, f.Tag)
+			if err != nil {
+				continue
+			}
+			seen[key] = true
+			i := o.index(key)
+			if opts, _ := k.d.jsonTag(ident.Name, f.Tag); i == -1 && opts.CaseIgnore {
+				if i = o.indexFold(key); i != -1 {
+					seen[o[i].Key] = true
+				}
+			}
+			if i == -1 {
+				if !omitempty {
+					k.problem(path, "missing required field %q", key)
+				}
+				continue
+			}
+			if f == k.dataField {
+				k.checkContainer(k.data, k.dataCtx, o[i].Value, path+"."+key)
+			} else {
+				k.check(f.Type, c, o[i].Value, path+"."+key)
+			}
+		}
+	}
+}
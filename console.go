@@ -0,0 +1,74 @@
+package jsondoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+)
+
+// consoleField is an input element of the "Try it" console form.
+type consoleField struct {
+	Key   string // JSON object key
+	Kind  string // "string", "number", "boolean" or "json"
+	Value string // initial value of the element
+}
+
+// renderConsole writes to d.b the "Try it" console form for the
+// endpoint e (its input fields are built from the sample of the
+// endpoint input).
+func (d *JSONDoc) renderConsole(e *endpoint) error {
+	type data struct {
+		Method, Path string
+		Fields       []consoleField
+		Body         string // whole JSON body if the input is not a JSON object
+	}
+	v := data{Method: e.Method, Path: e.Path}
+	if e.Input != "" {
+		s, err := d.inputSample(e)
+		if err != nil {
+			return err
+		}
+		if o, ok := s.(object); ok {
+			for _, m := range o {
+				v.Fields = append(v.Fields, newConsoleField(m))
+			}
+		} else {
+			b, err := json.MarshalIndent(s, "", "  ")
+			if err != nil {
+				return err
+			}
+			v.Body = string(b)
+		}
+	}
+	return consoleTmpl.Execute(&d.b, v)
+}
+
+func newConsoleField(m member) consoleField {
+	switch v := m.Value.(type) {
+	case string:
+		return consoleField{m.Key, "string", v}
+	case int, float64:
+		return consoleField{m.Key, "number", fmt.Sprint(v)}
+	case bool:
+		return consoleField{m.Key, "boolean", ""}
+	}
+	b, _ := json.MarshalIndent(m.Value, "", "  ")
+	return consoleField{m.Key, "json", string(b)}
+}
+
+// consoleScript returns the script used by the "Try it" consoles
+// sending requests to the given base URL.
+func consoleScript(baseURL string) (string, error) {
+	u, err := json.Marshal(baseURL)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	err = consoleScriptTmpl.Execute(&b, string(u))
+	return b.String(), err
+}
+
+var consoleTmpl = template.Must(template.New("console").Parse(console))
+
+var consoleScriptTmpl = template.Must(template.New("consoleScript").Parse(consoleJS))
@@ -0,0 +1,227 @@
+package jsondoc
+
+import (
+	"fmt"
+	"go/ast"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// constraint is a single validation rule from the validate struct tag
+// (such as "required", "min=1" or "oneof=a b c").
+type constraint struct {
+	Name  string
+	Param string
+}
+
+// knownConstraints lists the supported validation rules and whether
+// they require a parameter.
+var knownConstraints = map[string]bool{
+	"omitempty": false,
+	"required":  false,
+	"min":       true,
+	"max":       true,
+	"len":       true,
+	"oneof":     true,
+	"email":     false,
+}
+
+// parseConstraints returns the validation rules from the validate key
+// of the given struct tag.
+func parseConstraints(tag *ast.BasicLit) ([]constraint, error) {
+	if tag == nil {
+		return nil, nil
+	}
+	s, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return nil, err
+	}
+	s = reflect.StructTag(s).Get("validate")
+	if s == "" {
+		return nil, nil
+	}
+	var cs []constraint
+	for _, r := range strings.Split(s, ",") {
+		name, param := r, ""
+		if i := strings.IndexByte(r, '='); i != -1 {
+			name, param = r[:i], r[i+1:]
+		}
+		needsParam, ok := knownConstraints[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported validation rule %q", r)
+		}
+		if needsParam != (param != "") {
+			return nil, fmt.Errorf("invalid validation rule %q", r)
+		}
+		if needsParam && name != "oneof" {
+			if _, err := parseLimit(param); err != nil {
+				return nil, fmt.Errorf("invalid validation rule %q: %v", r, err)
+			}
+		}
+		cs = append(cs, constraint{name, param})
+	}
+	return cs, nil
+}
+
+// parseLimit returns the value of the parameter of a min, max or len
+// rule which must be a finite number.
+func parseLimit(s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return 0, fmt.Errorf("%s is not a finite number", s)
+	}
+	return v, nil
+}
+
+// limits are the numeric and length constraints of a field: limits of
+// its numeric values, of the lengths of its strings, of the numbers of
+// elements of its arrays or of the numbers of members of its objects
+// (depending on the kind of its values).
+type limits struct {
+	Min, Max *float64 // nil if not limited
+}
+
+// fieldLimits returns the limits of the field given with the min, max
+// and len rules of its validate struct tag (up to the dive rule which
+// starts the rules of its elements) or the min, max and len directives
+// of its comments (which take precedence).
+func fieldLimits(f *ast.Field, fc fieldComment) (limits, error) {
+	var l limits
+	var rules [][2]string
+	if f.Tag != nil {
+		tag, err := strconv.Unquote(f.Tag.Value)
+		if err != nil {
+			return l, err
+		}
+		for _, r := range strings.Split(reflect.StructTag(tag).Get("validate"), ",") {
+			if r == "dive" {
+				break
+			}
+			if i := strings.IndexByte(r, '='); i != -1 {
+				rules = append(rules, [2]string{r[:i], r[i+1:]})
+			}
+		}
+	}
+	for _, r := range [][2]string{{"min", fc.Min}, {"max", fc.Max}, {"len", fc.Len}} {
+		if r[1] != "" {
+			rules = append(rules, r)
+		}
+	}
+	for _, r := range rules {
+		switch r[0] {
+		case "min", "max", "len":
+		default:
+			continue
+		}
+		v, err := parseLimit(r[1])
+		if err != nil {
+			return l, fmt.Errorf("invalid %s limit %q", r[0], r[1])
+		}
+		switch r[0] {
+		case "min":
+			l.Min = &v
+		case "max":
+			l.Max = &v
+		case "len":
+			l.Min, l.Max = &v, &v
+		}
+	}
+	return l, nil
+}
+
+// valueKind returns the kind of the JSON values of the type ("string",
+// "number", "boolean", "array", "object" or "" if unknown).
+func (d *JSONDoc) valueKind(t ast.Expr, c *context) string {
+	switch t := t.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string", "error":
+			return "string"
+		case "bool":
+			return "boolean"
+		case "float32", "float64", "int", "int8", "int16", "int32", "int64", "rune", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte":
+			return "number"
+		}
+	case *ast.SelectorExpr:
+		if name, ok := d.cType(t, c); ok {
+			if _, ok := cNumeric[name]; ok {
+				return "number"
+			}
+			return ""
+		}
+		if ident, ok := t.X.(*ast.Ident); ok {
+			if path, err := d.findImportIdent(c.File, ident.Name); err == nil {
+				switch path + "." + t.Sel.Name {
+				case "time.Time":
+					return "string"
+				case "time.Duration":
+					return "number"
+				}
+			}
+		}
+	case *ast.StarExpr:
+		return d.valueKind(t.X, c)
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && (ident.Name == "byte" || ident.Name == "uint8") {
+			return "string"
+		}
+		return "array"
+	case *ast.MapType, *ast.StructType:
+		return "object"
+	default:
+		return ""
+	}
+	if d.stdType(t, c) {
+		return ""
+	}
+	ts, c, err := d.lookupType(t, c)
+	if err != nil || ts == nil {
+		return ""
+	}
+	return d.valueKind(ts.Type, c)
+}
+
+// limitKeywords are the JSON Schema keywords of the minimum and the
+// maximum of the limited values of the given kinds.
+var limitKeywords = map[string][2]string{
+	"number": {"minimum", "maximum"},
+	"string": {"minLength", "maxLength"},
+	"array":  {"minItems", "maxItems"},
+	"object": {"minProperties", "maxProperties"},
+}
+
+// limitNames are the names of the limited properties of the values of
+// the given kinds shown in the documentation.
+var limitNames = map[string]string{
+	"number": "value",
+	"string": "length",
+	"array":  "elements",
+	"object": "members",
+}
+
+// describe returns the description of the limits of the values of the
+// given kind (such as "length: 1–100") with the numbers using the
+// decimal separator.
+func (l limits) describe(kind, decimalSeparator string) string {
+	name := limitNames[kind]
+	if name == "" || l.Min == nil && l.Max == nil {
+		return ""
+	}
+	f := func(v *float64) string {
+		return strings.Replace(strconv.FormatFloat(*v, 'g', -1, 64), ".", decimalSeparator, 1)
+	}
+	switch {
+	case l.Max == nil:
+		return fmt.Sprintf("%s: ≥ %s", name, f(l.Min))
+	case l.Min == nil:
+		return fmt.Sprintf("%s: ≤ %s", name, f(l.Max))
+	case *l.Min == *l.Max:
+		return fmt.Sprintf("%s: %s", name, f(l.Min))
+	}
+	return fmt.Sprintf("%s: %s–%s", name, f(l.Min), f(l.Max))
+}
@@ -0,0 +1,210 @@
+package jsondoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Mismatch is a difference between an OpenAPI description and the
+// documented Go types found by CrossCheck.
+type Mismatch struct {
+	Where string // such as "POST /item input" or "schema itemGetOutput .size"
+	What  string
+}
+
+func (m Mismatch) String() string {
+	return m.Where + ": " + m.What
+}
+
+// CrossCheck compares the OpenAPI description (in JSON or YAML) read
+// from the named file (such as the spec of a project migrating from
+// spec-first to code-first documentation) with the one derived from
+// the documented Go types. It reports the endpoints, the inputs and
+// the fields (of the schemas of the endpoints and of the schemas of
+// the same names) present in only one of them.
+func (d *JSONDoc) CrossCheck(spec string) ([]Mismatch, error) {
+	c, err := d.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer d.end(c)
+	return c.crossCheck(spec)
+}
+
+func (d *JSONDoc) crossCheck(spec string) ([]Mismatch, error) {
+	b, err := os.ReadFile(spec)
+	if err != nil {
+		return nil, err
+	}
+	s, err := decodeSpec(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", spec, err)
+	}
+	var buf bytes.Buffer
+	if err := d.writeOpenAPI(&buf); err != nil {
+		return nil, err
+	}
+	var g map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &g); err != nil {
+		return nil, err
+	}
+	cc := &crossChecker{
+		specDefs: mapAt(s, "components", "schemas"),
+		goDefs:   mapAt(g, "components", "schemas"),
+		seen:     make(map[string]bool),
+	}
+	cc.paths(mapAt(s, "paths"), mapAt(g, "paths"))
+	for _, name := range unionKeys(cc.specDefs, cc.goDefs) {
+		if cc.specDefs[name] != nil && cc.goDefs[name] != nil {
+			ref := map[string]interface{}{"$ref": "#/components/schemas/" + name}
+			cc.schema("schema "+name, ref, ref)
+		}
+	}
+	return cc.mismatches, nil
+}
+
+// crossChecker collects the mismatches between the spec and the
+// OpenAPI description of the Go types.
+type crossChecker struct {
+	specDefs, goDefs map[string]interface{} // components/schemas
+	seen             map[string]bool        // compared pairs of referenced schemas
+	mismatches       []Mismatch
+}
+
+func (cc *crossChecker) add(where, format string, args ...interface{}) {
+	cc.mismatches = append(cc.mismatches, Mismatch{where, fmt.Sprintf(format, args...)})
+}
+
+var pathParamRe = regexp.MustCompile(`\{[^}]*\}`)
+
+// paths compares the operations of the paths matched ignoring the
+// names of the path parameters.
+func (cc *crossChecker) paths(spec, goPaths map[string]interface{}) {
+	norm := func(paths map[string]interface{}) map[string]interface{} {
+		m := make(map[string]interface{})
+		for p, v := range paths {
+			m[pathParamRe.ReplaceAllString(p, "{}")] = v
+		}
+		return m
+	}
+	names := make(map[string]string) // map: normalized path -> path (as in the spec if present)
+	for _, paths := range []map[string]interface{}{goPaths, spec} {
+		for p := range paths {
+			names[pathParamRe.ReplaceAllString(p, "{}")] = p
+		}
+	}
+	s, g := norm(spec), norm(goPaths)
+	for _, path := range unionKeys(s, g) {
+		sp, gp := mapAt(s, path), mapAt(g, path)
+		for _, m := range httpMethods {
+			where := strings.ToUpper(m) + " " + names[path]
+			sop, specOK := sp[m].(map[string]interface{})
+			gop, goOK := gp[m].(map[string]interface{})
+			switch {
+			case specOK && !goOK:
+				cc.add(where, "endpoint only in the spec")
+			case !specOK && goOK:
+				cc.add(where, "endpoint only in the Go types")
+			case specOK && goOK:
+				cc.operation(where, sop, gop)
+			}
+		}
+	}
+}
+
+func (cc *crossChecker) operation(where string, spec, goOp map[string]interface{}) {
+	sb, gb := mapAt(spec, "requestBody", "content"), mapAt(goOp, "requestBody", "content")
+	switch {
+	case len(sb) > 0 && len(gb) == 0:
+		cc.add(where+" input", "input only in the spec")
+	case len(sb) == 0 && len(gb) > 0:
+		cc.add(where+" input", "input only in the Go types")
+	default:
+		cc.content(where+" input", sb, gb)
+	}
+	sr, gr := mapAt(spec, "responses"), mapAt(goOp, "responses")
+	for _, status := range unionKeys(sr, gr) {
+		if sr[status] != nil && gr[status] != nil {
+			cc.content(where+" "+status, mapAt(sr, status, "content"), mapAt(gr, status, "content"))
+		}
+	}
+}
+
+// content compares the schemas of the media types given in both.
+func (cc *crossChecker) content(where string, spec, goContent map[string]interface{}) {
+	for _, ct := range unionKeys(spec, goContent) {
+		s, specOK := spec[ct].(map[string]interface{})
+		g, goOK := goContent[ct].(map[string]interface{})
+		if specOK && goOK {
+			cc.schema(where, s["schema"], g["schema"])
+		}
+	}
+}
+
+// schema compares the fields of the schemas (with the fields of their
+// allOf schemas) and of the schemas of their fields, array elements and
+// map values.
+func (cc *crossChecker) schema(where string, spec, goSchema interface{}) {
+	s, sname := resolve(cc.specDefs, spec)
+	g, gname := resolve(cc.goDefs, goSchema)
+	if sname != "" && gname != "" {
+		key := sname + " " + gname
+		if cc.seen[key] {
+			return
+		}
+		cc.seen[key] = true
+	}
+	sp, gp := properties(cc.specDefs, s, map[string]bool{}), properties(cc.goDefs, g, map[string]bool{})
+	for _, name := range unionKeys(sp, gp) {
+		_, specOK := sp[name]
+		_, goOK := gp[name]
+		switch {
+		case specOK && !goOK && len(gp) > 0:
+			cc.add(where, "field %q only in the spec", name)
+		case !specOK && goOK && len(sp) > 0:
+			cc.add(where, "field %q only in the Go types", name)
+		case specOK && goOK:
+			cc.schema(where+" ."+name, sp[name], gp[name])
+		}
+	}
+	if len(sp) > 0 && len(gp) == 0 && schemaType(g) != "object" && schemaType(g) != "any" {
+		cc.add(where, "object in the spec, %s in the Go types", schemaType(g))
+	} else if len(sp) == 0 && len(gp) > 0 && schemaType(s) != "object" && schemaType(s) != "any" {
+		cc.add(where, "%s in the spec, object in the Go types", schemaType(s))
+	}
+	if s["items"] != nil && g["items"] != nil {
+		cc.schema(where+" []", s["items"], g["items"])
+	}
+	_, specMap := s["additionalProperties"].(map[string]interface{})
+	_, goMap := g["additionalProperties"].(map[string]interface{})
+	if specMap && goMap {
+		cc.schema(where+" {}", s["additionalProperties"], g["additionalProperties"])
+	}
+}
+
+// properties returns the properties of the schema including the ones
+// of its allOf schemas (the referenced schemas being expanded are
+// given with visiting).
+func properties(defs, s map[string]interface{}, visiting map[string]bool) map[string]interface{} {
+	p := make(map[string]interface{})
+	for k, v := range mapAt(s, "properties") {
+		p[k] = v
+	}
+	all, _ := s["allOf"].([]interface{})
+	for _, a := range all {
+		sub, name := resolve(defs, a)
+		if name != "" && visiting[name] {
+			continue // recursive schema
+		}
+		visiting[name] = name != ""
+		for k, v := range properties(defs, sub, visiting) {
+			p[k] = v
+		}
+		delete(visiting, name)
+	}
+	return p
+}
@@ -0,0 +1,102 @@
+package jsondoc
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const crossCheckSrc = `package api
+
+type Item struct {
+	ID   int   ` + "`json:\"id\"`" + `
+	Tags []Tag ` + "`json:\"tags\"`" + `
+}
+
+type Tag struct {
+	Name string ` + "`json:\"name\"`" + `
+}
+`
+
+const crossCheckTmpl = `{{endpoint "POST" "/item"}}
+
+{{input "Item"}}
+
+{{output "Item"}}
+
+{{endpoint "GET" "/item/{id}"}}
+
+{{output "Item"}}
+
+{{endpoint "DELETE" "/item/{id}"}}
+`
+
+const crossCheckSpec = `openapi: 3.0.3
+paths:
+  /item:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Item'
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Item'
+  /item/{itemId}:  # matched ignoring the names of the parameters
+    get:
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  id: {type: integer}
+  /item/{itemId}/tags:
+    get: {}
+components:
+  schemas:
+    Item:
+      type: object
+      properties:
+        id: {type: integer}
+        name: {type: string}
+        tags:
+          type: array
+          items:
+            type: object
+            properties:
+              name: {type: string}
+              color: {type: string}
+`
+
+func TestCrossCheck(t *testing.T) {
+	d := newTestDoc(t, crossCheckSrc, crossCheckTmpl, Options{})
+	spec := filepath.Join(t.TempDir(), "openapi.yaml")
+	if err := os.WriteFile(spec, []byte(crossCheckSpec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ms, err := d.CrossCheck(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range ms {
+		got = append(got, m.String())
+	}
+	want := []string{
+		`POST /item input: field "name" only in the spec`,
+		`POST /item input .tags []: field "color" only in the spec`,
+		`GET /item/{itemId} 200: field "tags" only in the Go types`,
+		`DELETE /item/{itemId}: endpoint only in the Go types`,
+		`GET /item/{itemId}/tags: endpoint only in the spec`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got mismatches:\n%q\nwant:\n%q", got, want)
+	}
+}
@@ -0,0 +1,530 @@
+package jsondoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind is the impact of a change of the API on its clients.
+type ChangeKind int
+
+const (
+	DocOnly  ChangeKind = iota // only the documentation changed
+	Additive                   // backward compatible addition
+	Breaking                   // existing clients may break
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case DocOnly:
+		return "doc-only"
+	case Additive:
+		return "additive"
+	}
+	return "breaking"
+}
+
+// Change is a difference between two versions of the API.
+type Change struct {
+	Kind  ChangeKind
+	Where string // such as "POST /item input" or "POST /item output .name"
+	What  string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s: %s", c.Kind, c.Where, c.What)
+}
+
+// APIDiff is the difference between two versions of the API.
+type APIDiff struct {
+	OldVersion string // version of the old OpenAPI description
+	NewVersion string // version of the new OpenAPI description
+	Changes    []Change
+}
+
+// DiffOpenAPI compares two OpenAPI descriptions (as written by
+// WriteOpenAPI) and classifies the changes of the endpoints and of their
+// input and output schemas.
+func DiffOpenAPI(old, new []byte) (*APIDiff, error) {
+	var o, n map[string]interface{}
+	if err := json.Unmarshal(old, &o); err != nil {
+		return nil, fmt.Errorf("old OpenAPI description: %v", err)
+	}
+	if err := json.Unmarshal(new, &n); err != nil {
+		return nil, fmt.Errorf("new OpenAPI description: %v", err)
+	}
+	df := &differ{
+		oldDefs: mapAt(o, "components", "schemas"),
+		newDefs: mapAt(n, "components", "schemas"),
+		seen:    make(map[string]bool),
+	}
+	oi, ni := mapAt(o, "info"), mapAt(n, "info")
+	df.text("API", "title", oi["title"], ni["title"])
+	df.paths(mapAt(o, "paths"), mapAt(n, "paths"))
+	a := &APIDiff{Changes: df.changes}
+	a.OldVersion, _ = oi["version"].(string)
+	a.NewVersion, _ = ni["version"].(string)
+	return a, nil
+}
+
+// Diff compares the OpenAPI description of the documented API with the
+// old one read from the named file (such as written with -openapi for
+// the previous release).
+func (d *JSONDoc) Diff(oldOpenAPI string) (*APIDiff, error) {
+	c, err := d.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer d.end(c)
+	return c.diff(oldOpenAPI)
+}
+
+func (d *JSONDoc) diff(oldOpenAPI string) (*APIDiff, error) {
+	old, err := os.ReadFile(oldOpenAPI)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := d.writeOpenAPI(&b); err != nil {
+		return nil, err
+	}
+	return DiffOpenAPI(old, b.Bytes())
+}
+
+// Impact returns the most severe kind of the changes (and false if
+// there are no changes).
+func (a *APIDiff) Impact() (ChangeKind, bool) {
+	if len(a.Changes) == 0 {
+		return DocOnly, false
+	}
+	k := DocOnly
+	for _, c := range a.Changes {
+		if c.Kind > k {
+			k = c.Kind
+		}
+	}
+	return k, true
+}
+
+// Bump returns the part of the semantic version which should be
+// incremented: "major" for breaking changes, "minor" for additions,
+// "patch" for changes of the documentation only and "" if there are no
+// changes.
+func (a *APIDiff) Bump() string {
+	k, ok := a.Impact()
+	switch {
+	case !ok:
+		return ""
+	case k == Breaking:
+		return "major"
+	case k == Additive:
+		return "minor"
+	}
+	return "patch"
+}
+
+// Suggested returns the old version with the part given by Bump
+// incremented (such as "1.3.0" for "1.2.5" and additions).
+func (a *APIDiff) Suggested() (string, error) {
+	v, err := parseSemver(a.OldVersion)
+	if err != nil {
+		return "", err
+	}
+	switch a.Bump() {
+	case "major":
+		v = [3]int{v[0] + 1, 0, 0}
+	case "minor":
+		v = [3]int{v[0], v[1] + 1, 0}
+	case "patch":
+		v[2]++
+	}
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2]), nil
+}
+
+// VersionOK reports whether the new version is at least the suggested
+// one.
+func (a *APIDiff) VersionOK() (bool, error) {
+	s, err := a.Suggested()
+	if err != nil {
+		return false, err
+	}
+	n, err := parseSemver(a.NewVersion)
+	if err != nil {
+		return false, err
+	}
+	v, _ := parseSemver(s)
+	for i := range n {
+		if n[i] != v[i] {
+			return n[i] > v[i], nil
+		}
+	}
+	return true, nil
+}
+
+// parseSemver returns the major, minor and patch numbers of the
+// version (with an optional "v" prefix and ignoring the pre-release
+// and build parts).
+func parseSemver(s string) ([3]int, error) {
+	var v [3]int
+	t := strings.TrimPrefix(s, "v")
+	if i := strings.IndexAny(t, "-+"); i != -1 {
+		t = t[:i]
+	}
+	parts := strings.Split(t, ".")
+	if len(parts) != 3 {
+		return v, fmt.Errorf("invalid semantic version %q", s)
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid semantic version %q", s)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+// differ collects the changes between two OpenAPI descriptions.
+type differ struct {
+	oldDefs, newDefs map[string]interface{} // components/schemas
+	seen             map[string]bool        // compared pairs of referenced schemas
+	changes          []Change
+}
+
+func (df *differ) add(kind ChangeKind, where, format string, args ...interface{}) {
+	df.changes = append(df.changes, Change{kind, where, fmt.Sprintf(format, args...)})
+}
+
+// text records a changed description (or other prose).
+func (df *differ) text(where, name string, old, new interface{}) {
+	if !reflect.DeepEqual(old, new) {
+		df.add(DocOnly, where, "%s changed", name)
+	}
+}
+
+// httpMethods are the methods of the operations in the order of
+// comparison.
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+func (df *differ) paths(old, new map[string]interface{}) {
+	for _, path := range unionKeys(old, new) {
+		o, n := mapAt(old, path), mapAt(new, path)
+		for _, m := range httpMethods {
+			where := strings.ToUpper(m) + " " + path
+			oop, oldOK := o[m].(map[string]interface{})
+			nop, newOK := n[m].(map[string]interface{})
+			switch {
+			case oldOK && !newOK:
+				df.add(Breaking, where, "endpoint removed")
+			case !oldOK && newOK:
+				df.add(Additive, where, "endpoint added")
+			case oldOK && newOK:
+				df.operation(where, oop, nop)
+			}
+		}
+	}
+}
+
+func (df *differ) operation(where string, old, new map[string]interface{}) {
+	df.text(where, "summary", old["summary"], new["summary"])
+	df.text(where, "description", old["description"], new["description"])
+	ob, nb := mapAt(old, "requestBody"), mapAt(new, "requestBody")
+	switch {
+	case len(ob) > 0 && len(nb) == 0:
+		df.add(Additive, where+" input", "input no longer expected")
+	case len(ob) == 0 && len(nb) > 0:
+		df.add(Breaking, where+" input", "input required")
+	case len(ob) > 0:
+		df.content(where+" input", true, mapAt(ob, "content"), mapAt(nb, "content"))
+	}
+	or, nr := mapAt(old, "responses"), mapAt(new, "responses")
+	for _, status := range unionKeys(or, nr) {
+		o, oldOK := or[status].(map[string]interface{})
+		n, newOK := nr[status].(map[string]interface{})
+		w := where + " " + status
+		switch {
+		case oldOK && !newOK:
+			df.add(Breaking, w, "response removed")
+		case !oldOK && newOK:
+			df.add(Additive, w, "response added")
+		default:
+			df.text(w, "description", o["description"], n["description"])
+			df.content(w, false, mapAt(o, "content"), mapAt(n, "content"))
+		}
+	}
+}
+
+// content compares the media types of an input (sent by the clients)
+// or an output (received by the clients).
+func (df *differ) content(where string, input bool, old, new map[string]interface{}) {
+	for _, ct := range unionKeys(old, new) {
+		o, oldOK := old[ct].(map[string]interface{})
+		n, newOK := new[ct].(map[string]interface{})
+		switch {
+		case oldOK && !newOK:
+			df.add(Breaking, where, "content type %s removed", ct)
+		case !oldOK && newOK:
+			df.add(Additive, where, "content type %s added", ct)
+		default:
+			df.schema(where, input, o["schema"], n["schema"])
+		}
+	}
+}
+
+// resolve returns the schema referenced by s (with $ref) or s itself
+// and the name of the referenced schema (if any).
+func resolve(defs map[string]interface{}, s interface{}) (map[string]interface{}, string) {
+	m, _ := s.(map[string]interface{})
+	if ref, ok := m["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		def, _ := defs[name].(map[string]interface{})
+		return def, name
+	}
+	return m, ""
+}
+
+// schema compares the schemas of an input or an output. Removed fields
+// are breaking in both directions, fields added to outputs are
+// additions while required fields added to inputs are breaking.
+func (df *differ) schema(where string, input bool, old, new interface{}) {
+	o, oname := resolve(df.oldDefs, old)
+	n, nname := resolve(df.newDefs, new)
+	if oname != "" && nname != "" {
+		key := fmt.Sprintf("%t %s %s", input, oname, nname)
+		if df.seen[key] {
+			return
+		}
+		df.seen[key] = true
+	}
+	df.text(where, "description", o["description"], n["description"])
+	if ot, nt := schemaType(o), schemaType(n); ot != nt {
+		df.add(Breaking, where, "type changed from %s to %s", ot, nt)
+		return
+	}
+	if !reflect.DeepEqual(o["format"], n["format"]) {
+		df.add(Breaking, where, "format changed from %v to %v", o["format"], n["format"])
+	}
+	df.enum(where, input, o["enum"], n["enum"])
+	df.constraints(where, input, o, n)
+	oreq, nreq := stringSet(o["required"]), stringSet(n["required"])
+	op, np := mapAt(o, "properties"), mapAt(n, "properties")
+	for _, name := range unionKeys(op, np) {
+		_, oldOK := op[name]
+		_, newOK := np[name]
+		switch {
+		case oldOK && !newOK:
+			df.add(Breaking, where, "field %q removed", name)
+		case !oldOK && newOK && input && nreq[name]:
+			df.add(Breaking, where, "required field %q added", name)
+		case !oldOK && newOK:
+			df.add(Additive, where, "field %q added", name)
+		default:
+			switch {
+			case input && !oreq[name] && nreq[name]:
+				df.add(Breaking, where, "field %q became required", name)
+			case input && oreq[name] && !nreq[name]:
+				df.add(Additive, where, "field %q became optional", name)
+			case !input && oreq[name] && !nreq[name]:
+				df.add(Breaking, where, "field %q may be omitted", name)
+			}
+			df.schema(where+" ."+name, input, op[name], np[name])
+		}
+	}
+	if o["items"] != nil && n["items"] != nil {
+		df.schema(where+" []", input, o["items"], n["items"])
+	}
+	if _, ok := o["additionalProperties"].(map[string]interface{}); ok {
+		df.schema(where+" {}", input, o["additionalProperties"], n["additionalProperties"])
+	}
+	oa, _ := o["allOf"].([]interface{})
+	na, _ := n["allOf"].([]interface{})
+	if len(oa) != len(na) {
+		df.add(Breaking, where, "structure changed")
+		return
+	}
+	for i := range oa {
+		df.schema(where, input, oa[i], na[i])
+	}
+}
+
+// enum compares the allowed values: restricting inputs and extending
+// outputs (with values the clients may not handle) are breaking.
+func (df *differ) enum(where string, input bool, old, new interface{}) {
+	oe, _ := old.([]interface{})
+	ne, _ := new.([]interface{})
+	switch {
+	case len(oe) == 0 && len(ne) == 0:
+		return
+	case len(ne) == 0 && input:
+		df.add(Additive, where, "values no longer restricted")
+		return
+	case len(ne) == 0:
+		df.add(Breaking, where, "values no longer restricted")
+		return
+	case len(oe) == 0 && input:
+		df.add(Breaking, where, "values restricted")
+		return
+	case len(oe) == 0:
+		df.add(Additive, where, "values restricted")
+		return
+	}
+	oldValues, newValues := valueSet(oe), valueSet(ne)
+	for _, v := range unionKeys(oldValues, newValues) {
+		_, oldOK := oldValues[v]
+		_, newOK := newValues[v]
+		switch {
+		case oldOK && !newOK && input:
+			df.add(Breaking, where, "value %s no longer allowed", v)
+		case oldOK && !newOK:
+			df.add(Additive, where, "value %s no longer returned", v)
+		case !oldOK && newOK && input:
+			df.add(Additive, where, "value %s allowed", v)
+		case !oldOK && newOK:
+			df.add(Breaking, where, "value %s may be returned", v)
+		}
+	}
+}
+
+// constraints compares the limits of the values: stricter inputs and
+// looser outputs are breaking.
+func (df *differ) constraints(where string, input bool, old, new map[string]interface{}) {
+	for _, c := range []struct {
+		key string
+		min bool // a greater value is stricter
+	}{{"minimum", true}, {"maximum", false}, {"minLength", true}, {"maxLength", false}, {"minItems", true}, {"maxItems", false}} {
+		o, oldOK := old[c.key].(float64)
+		n, newOK := new[c.key].(float64)
+		if (!oldOK && !newOK) || (oldOK && newOK && o == n) {
+			continue
+		}
+		stricter := !oldOK || (newOK && (n > o) == c.min)
+		kind := DocOnly
+		if stricter == input {
+			kind = Breaking
+		}
+		switch {
+		case !oldOK:
+			df.add(kind, where, "%s %v added", c.key, n)
+		case !newOK:
+			df.add(kind, where, "%s %v removed", c.key, o)
+		default:
+			df.add(kind, where, "%s changed from %v to %v", c.key, o, n)
+		}
+	}
+	if !reflect.DeepEqual(old["pattern"], new["pattern"]) {
+		kind := DocOnly
+		if input || new["pattern"] == nil {
+			kind = Breaking
+		}
+		df.add(kind, where, "pattern changed")
+	}
+}
+
+// schemaType returns the type of the schema ("object" for schemas with
+// properties and "any" if not given).
+func schemaType(s map[string]interface{}) string {
+	if t, ok := s["type"].(string); ok {
+		return t
+	}
+	if s["properties"] != nil || s["allOf"] != nil {
+		return "object"
+	}
+	return "any"
+}
+
+// mapAt returns the JSON object at the path of keys (nil if missing).
+func mapAt(m map[string]interface{}, keys ...string) map[string]interface{} {
+	for _, k := range keys {
+		m, _ = m[k].(map[string]interface{})
+	}
+	return m
+}
+
+// unionKeys returns the sorted keys of both maps.
+func unionKeys(a, b map[string]interface{}) []string {
+	var keys []string
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func stringSet(v interface{}) map[string]bool {
+	a, _ := v.([]interface{})
+	m := make(map[string]bool)
+	for _, s := range a {
+		if s, ok := s.(string); ok {
+			m[s] = true
+		}
+	}
+	return m
+}
+
+// valueSet returns the JSON values (as JSON) of the enum.
+func valueSet(a []interface{}) map[string]interface{} {
+	m := make(map[string]interface{})
+	for _, v := range a {
+		b, _ := json.Marshal(v)
+		m[string(b)] = v
+	}
+	return m
+}
+
+const apiChangesPlaceholder = "<!--jsondoc-api-changes-->"
+
+// apiChanges marks the place of the "API changes" chapter listing the
+// changes since the version of the API described in the named OpenAPI
+// file (such as written with -openapi for the previous release) and
+// the suggested semantic version.
+func (d *JSONDoc) apiChanges(oldOpenAPI string) (string, error) {
+	if d.changesSince != "" {
+		return "", fmt.Errorf("apiChanges %s: only one apiChanges chapter is supported", oldOpenAPI)
+	}
+	if _, err := os.Stat(oldOpenAPI); err != nil {
+		return "", fmt.Errorf("apiChanges: %v", err)
+	}
+	d.changesSince = oldOpenAPI
+	return heading(d.headingLevel()) + " API changes {#api-changes}\n\n<div>\n" + apiChangesPlaceholder + "\n</div>\n", nil
+}
+
+// resolveAPIChanges returns the markdown with the changes of the API
+// inserted in the "API changes" chapter.
+func (d *JSONDoc) resolveAPIChanges(md []byte) ([]byte, error) {
+	if d.changesSince == "" {
+		return md, nil
+	}
+	a, err := d.diff(d.changesSince)
+	if err != nil {
+		return nil, fmt.Errorf("apiChanges: %v", err)
+	}
+	var b bytes.Buffer
+	if len(a.Changes) == 0 {
+		fmt.Fprintf(&b, "<p>No changes since version %s.</p>", html.EscapeString(a.OldVersion))
+		return bytes.Replace(md, []byte(apiChangesPlaceholder), b.Bytes(), 1), nil
+	}
+	fmt.Fprintf(&b, "<p>Changes since version %s", html.EscapeString(a.OldVersion))
+	if s, err := a.Suggested(); err == nil {
+		fmt.Fprintf(&b, " (suggested version: %s, a %s bump)", html.EscapeString(s), a.Bump())
+	}
+	b.WriteString(".</p>\n<table>\n<caption>API changes</caption>\n<tr>\n<th scope=\"col\">Impact</th>\n<th scope=\"col\">Where</th>\n<th scope=\"col\">Change</th>\n</tr>\n")
+	cs := append([]Change(nil), a.Changes...)
+	sort.SliceStable(cs, func(i, j int) bool { return cs[i].Kind > cs[j].Kind })
+	for _, c := range cs {
+		fmt.Fprintf(&b, "<tr>\n<td>%s</td>\n<td>%s</td>\n<td>%s</td>\n</tr>\n", c.Kind, html.EscapeString(c.Where), html.EscapeString(c.What))
+	}
+	b.WriteString("</table>")
+	return bytes.Replace(md, []byte(apiChangesPlaceholder), b.Bytes(), 1), nil
+}
@@ -0,0 +1,132 @@
+package jsondoc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// diffSpec returns the OpenAPI description with the given paths.
+func diffSpec(paths string) string {
+	return `{"info": {"title": "API", "version": "1.0.0"}, "paths": {` + paths + `}, "components": {"schemas": {"Tag": {"type": "string"}}}}`
+}
+
+// diffOutput returns the path /a with the GET endpoint returning the
+// schema.
+func diffOutput(schema string) string {
+	return `"/a": {"get": {"responses": {"200": {"content": {"application/json": {"schema": ` + schema + `}}}}}}`
+}
+
+// diffInput returns the path /a with the POST endpoint expecting the
+// schema.
+func diffInput(schema string) string {
+	return `"/a": {"post": {"requestBody": {"content": {"application/json": {"schema": ` + schema + `}}}}}`
+}
+
+func TestDiffOpenAPI(t *testing.T) {
+	const (
+		obj    = `{"type": "object", "properties": {"a": {"type": "string"}}, "required": ["a"]}`
+		objB   = `{"type": "object", "properties": {"a": {"type": "string"}, "b": {"type": "string"}}, "required": ["a"]}`
+		objReq = `{"type": "object", "properties": {"a": {"type": "string"}, "b": {"type": "string"}}, "required": ["a", "b"]}`
+	)
+	for _, c := range []struct {
+		name, old, new string
+		want           []string
+	}{
+		{"no changes", diffOutput(obj), diffOutput(obj), nil},
+		{"endpoint added", ``, diffOutput(obj), []string{"additive: GET /a: endpoint added"}},
+		{"endpoint removed", diffOutput(obj), ``, []string{"breaking: GET /a: endpoint removed"}},
+		{"method changed", diffOutput(obj), `"/a": {"put": {}}`, []string{"breaking: GET /a: endpoint removed", "additive: PUT /a: endpoint added"}},
+		{"summary changed", `"/a": {"get": {"summary": "x"}}`, `"/a": {"get": {"summary": "y"}}`, []string{"doc-only: GET /a: summary changed"}},
+		{"response added", `"/a": {"get": {"responses": {}}}`, diffOutput(obj), []string{"additive: GET /a 200: response added"}},
+		{"output field added", diffOutput(obj), diffOutput(objB), []string{`additive: GET /a 200: field "b" added`}},
+		{"output field removed", diffOutput(objB), diffOutput(obj), []string{`breaking: GET /a 200: field "b" removed`}},
+		{"output field may be omitted", diffOutput(objReq), diffOutput(objB), []string{`breaking: GET /a 200: field "b" may be omitted`}},
+		{"field type changed", diffOutput(obj), diffOutput(`{"type": "object", "properties": {"a": {"type": "integer"}}, "required": ["a"]}`),
+			[]string{"breaking: GET /a 200 .a: type changed from string to integer"}},
+		{"field description changed", diffOutput(obj), diffOutput(`{"type": "object", "properties": {"a": {"type": "string", "description": "A."}}, "required": ["a"]}`),
+			[]string{"doc-only: GET /a 200 .a: description changed"}},
+		{"referenced field changed", diffOutput(`{"type": "array", "items": {"$ref": "#/components/schemas/Tag"}}`), diffOutput(`{"type": "array", "items": {"type": "integer"}}`),
+			[]string{"breaking: GET /a 200 []: type changed from string to integer"}},
+		{"output value added", diffOutput(`{"type": "string", "enum": ["x"]}`), diffOutput(`{"type": "string", "enum": ["x", "y"]}`),
+			[]string{`breaking: GET /a 200: value "y" may be returned`}},
+		{"input field added", diffInput(obj), diffInput(objB), []string{`additive: POST /a input: field "b" added`}},
+		{"input required field added", diffInput(obj), diffInput(objReq), []string{`breaking: POST /a input: required field "b" added`}},
+		{"input field became required", diffInput(objB), diffInput(objReq), []string{`breaking: POST /a input: field "b" became required`}},
+		{"input field removed", diffInput(objB), diffInput(obj), []string{`breaking: POST /a input: field "b" removed`}},
+		{"input limit stricter", diffInput(`{"type": "string", "maxLength": 10}`), diffInput(`{"type": "string", "maxLength": 5}`),
+			[]string{"breaking: POST /a input: maxLength changed from 10 to 5"}},
+		{"input required", `"/a": {"post": {}}`, diffInput(obj), []string{"breaking: POST /a input: input required"}},
+		{"input no longer expected", diffInput(obj), `"/a": {"post": {}}`, []string{"additive: POST /a input: input no longer expected"}},
+	} {
+		a, err := DiffOpenAPI([]byte(diffSpec(c.old)), []byte(diffSpec(c.new)))
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		var got []string
+		for _, ch := range a.Changes {
+			got = append(got, ch.String())
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got changes %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestAPIDiffVersion(t *testing.T) {
+	for _, c := range []struct {
+		kinds          []ChangeKind
+		old, new, bump string
+		suggested      string
+		ok             bool
+	}{
+		{nil, "1.2.5", "1.2.5", "", "1.2.5", true},
+		{[]ChangeKind{DocOnly}, "1.2.5", "1.2.5", "patch", "1.2.6", false},
+		{[]ChangeKind{DocOnly, Additive}, "v1.2.5", "1.3.0", "minor", "1.3.0", true},
+		{[]ChangeKind{Breaking, Additive}, "1.2.5", "1.3.0", "major", "2.0.0", false},
+		{[]ChangeKind{Breaking}, "1.2.5-rc.1", "3.0.0", "major", "2.0.0", true},
+	} {
+		a := &APIDiff{OldVersion: c.old, NewVersion: c.new}
+		for _, k := range c.kinds {
+			a.Changes = append(a.Changes, Change{Kind: k})
+		}
+		s, err := a.Suggested()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, err := a.VersionOK()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Bump() != c.bump || s != c.suggested || ok != c.ok {
+			t.Errorf("%v %s -> %s: got %q, %s, %t, want %q, %s, %t", c.kinds, c.old, c.new, a.Bump(), s, ok, c.bump, c.suggested, c.ok)
+		}
+	}
+	if _, err := (&APIDiff{OldVersion: "1.2"}).Suggested(); err == nil {
+		t.Error("got no error for an invalid version")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	const tmpl = "{{endpoint \"GET\" \"/item\"}}\n\n{{output \"Item\"}}\n"
+	old := newTestDoc(t, "package api\n\ntype Item struct {\n\tID int `json:\"id\"`\n}\n", tmpl, Options{})
+	var b bytes.Buffer
+	if err := old.WriteOpenAPI(&b); err != nil {
+		t.Fatal(err)
+	}
+	spec := filepath.Join(t.TempDir(), "openapi.json")
+	if err := os.WriteFile(spec, b.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	d := newTestDoc(t, "package api\n\ntype Item struct {\n\tID int `json:\"id\"`\n\tName string `json:\"name\"`\n}\n", tmpl, Options{})
+	a, err := d.Diff(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Change{{Additive, "GET /item 200", `field "name" added`}}
+	if !reflect.DeepEqual(a.Changes, want) {
+		t.Errorf("got changes %v, want %v", a.Changes, want)
+	}
+}
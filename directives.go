@@ -0,0 +1,225 @@
+package jsondoc
+
+import (
+	"fmt"
+	"go/ast"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// fieldComment is the comment of a struct field: its description (the
+// comment following the field) and the directives given in the lines
+// of its comments (such as "format: email").
+type fieldComment struct {
+	Description string
+	Format      string // format of the values (as the JSON Schema format keyword)
+	Min, Max    string // limits of the values, lengths or numbers of elements (see limits)
+	Len         string // exact length or number of elements
+
+	RequiredWith []string // names of the fields whose presence requires the field
+	PresentWhen  string   // condition of the presence of the field (such as status == "error")
+}
+
+var directiveRe = regexp.MustCompile(`^([a-z][a-z-]*):\s*(\S.*)$`)
+
+// knownDirectives lists the directives of field comments (lines of
+// other forms, or with other names, are part of the description).
+var knownDirectives = map[string]bool{
+	"format": true,
+	"min":    true,
+	"max":    true,
+	"len":    true,
+
+	"required-with": true,
+	"present-when":  true,
+}
+
+// parseFieldComment returns the description and the directives of the
+// comments of the field.
+func parseFieldComment(f *ast.Field) fieldComment {
+	var fc fieldComment
+	var desc []string
+	for _, g := range []*ast.CommentGroup{f.Doc, f.Comment} {
+		for _, line := range strings.Split(strings.TrimSpace(g.Text()), "\n") {
+			m := directiveRe.FindStringSubmatch(strings.TrimSpace(line))
+			if m == nil || !knownDirectives[m[1]] {
+				if g == f.Comment {
+					desc = append(desc, line)
+				}
+				continue
+			}
+			switch m[1] {
+			case "format":
+				fc.Format = strings.TrimSpace(m[2])
+			case "min":
+				fc.Min = strings.TrimSpace(m[2])
+			case "max":
+				fc.Max = strings.TrimSpace(m[2])
+			case "len":
+				fc.Len = strings.TrimSpace(m[2])
+			case "required-with":
+				fc.RequiredWith = directiveList(m[2])
+			case "present-when":
+				fc.PresentWhen = strings.TrimSpace(m[2])
+			}
+		}
+	}
+	fc.Description = strings.TrimSpace(strings.Join(desc, "\n"))
+	return fc
+}
+
+// schemaDescription returns the description of the field in JSON
+// Schema (noting the condition of its presence).
+func (fc fieldComment) schemaDescription() string {
+	if fc.PresentWhen == "" {
+		return fc.Description
+	}
+	note := "Present when: " + fc.PresentWhen
+	if fc.Description == "" {
+		return note
+	}
+	return fc.Description + "\n\n" + note
+}
+
+// directiveList returns the names listed (separated with commas or
+// spaces) in the argument of a directive.
+func directiveList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+}
+
+// typeDirectives are the directives of the doc comment of a struct
+// type relating its fields.
+var typeDirectives = map[string]string{
+	"mutually-exclusive": "At most one of %s may be present.",
+	"exactly-one-of":     "Exactly one of %s must be present.",
+}
+
+// typeSpecDoc returns the doc comment of the type declaration (which
+// is the doc comment of the whole declaration if it declares a single
+// type).
+func typeSpecDoc(t *ast.TypeSpec, c *context) *ast.CommentGroup {
+	if t.Doc != nil || c == nil || c.File == nil {
+		return t.Doc
+	}
+	for _, decl := range c.File.Decls {
+		if g, ok := decl.(*ast.GenDecl); ok && len(g.Specs) == 1 && g.Specs[0] == t {
+			return g.Doc
+		}
+	}
+	return nil
+}
+
+// renderNotes writes to d.b the notes on the relationships among the
+// fields of the struct type given with the directives of its doc
+// comment (such as "exactly-one-of: Email, Phone") and of the comments
+// of its fields ("required-with: Email"). Fields are referred to by
+// their Go names or keys.
+func (d *JSONDoc) renderNotes(t *ast.TypeSpec, c *context) error {
+	st, ok := t.Type.(*ast.StructType)
+	if !ok {
+		return nil
+	}
+	keys := make(map[string]string) // map: Go name or key -> displayed key
+	var required [][]string         // field and the fields requiring it
+	if err := d.noteFields(st, c, keys, &required); err != nil {
+		return err
+	}
+	key := func(name string) (string, error) {
+		k, ok := keys[name]
+		if !ok {
+			return "", fmt.Errorf("type %s: unknown field %s in a directive", t.Name.Name, name)
+		}
+		return k, nil
+	}
+	list := func(names []string, conj string) (string, error) {
+		var ks []string
+		for _, name := range names {
+			k, err := key(name)
+			if err != nil {
+				return "", err
+			}
+			ks = append(ks, k)
+		}
+		if len(ks) == 1 {
+			return ks[0], nil
+		}
+		return strings.Join(ks[:len(ks)-1], ", ") + " " + conj + " " + ks[len(ks)-1], nil
+	}
+	var notes []string
+	for _, line := range strings.Split(typeSpecDoc(t, c).Text(), "\n") {
+		m := directiveRe.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil || typeDirectives[m[1]] == "" {
+			continue
+		}
+		s, err := list(directiveList(m[2]), "and")
+		if err != nil {
+			return err
+		}
+		notes = append(notes, fmt.Sprintf(typeDirectives[m[1]], s))
+	}
+	for _, r := range required {
+		s, err := list(r[1:], "or")
+		if err != nil {
+			return err
+		}
+		notes = append(notes, fmt.Sprintf("%s is required when %s is present.", r[0], s))
+	}
+	if len(notes) == 0 {
+		return nil
+	}
+	d.b.WriteString("<p>Notes:</p>\n<ul>\n")
+	for _, n := range notes {
+		fmt.Fprintf(&d.b, "<li>%s</li>\n", html.EscapeString(n))
+	}
+	d.b.WriteString("</ul>\n")
+	return nil
+}
+
+// noteFields records the keys of the fields of the struct type
+// (including the fields of embedded structs) under their Go names and
+// keys and the fields with the required-with directive.
+func (d *JSONDoc) noteFields(t *ast.StructType, c *context, keys map[string]string, required *[][]string) error {
+	for _, f := range t.Fields.List {
+		if typ := d.inlined(f); typ != nil {
+			if s, ok := typ.(*ast.StarExpr); ok {
+				typ = s.X
+			}
+			ts, c, err := d.lookupType(typ, c)
+			if err != nil {
+				return err
+			}
+			if ts == nil {
+				continue
+			}
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				if err := d.noteFields(st, c, keys, required); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		for _, ident := range f.Names {
+			key, _, err := d.jsonKey(ident.Name, f.Tag)
+			if d.codec != nil {
+				key, _, err = d.codec.key(ident.Name, f.Tag)
+			}
+			if err == NotExported {
+				continue
+			} else if err != nil {
+				return err
+			}
+			name := key
+			if d.codec == nil || !d.codec.intKey(f.Tag) {
+				name = strconv.Quote(key)
+			}
+			keys[ident.Name] = name
+			keys[key] = name
+			if fc := parseFieldComment(f); len(fc.RequiredWith) > 0 {
+				*required = append(*required, append([]string{name}, fc.RequiredWith...))
+			}
+		}
+	}
+	return nil
+}
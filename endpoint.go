@@ -0,0 +1,121 @@
+package jsondoc
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// endpoint describes a single documented HTTP endpoint. Input and
+// Output hold the type names given to the input and output template
+// actions following the endpoint action. Envelope is the type name of
+// the envelope the output is wrapped in (if any) and Container is
+// "array" or "object" if the output (or the data of the envelope) is an
+// array or a map of values of the output type (such as a page of a
+// paginated list). Error is the type name of the standard
+// error object of the responses with the status codes ErrorCodes (given
+// to the stdError action). File is the file the endpoint responds with
+// (instead of an output) and NoOutputStatus the status of the responses
+// with no body (given to the noOutput action). Termination describes
+// how the stream of an NDJSON output ends. InputContentType and
+// OutputContentType are the content types of the input and output if
+// they are not JSON.
+type endpoint struct {
+	Method, Path      string
+	ID                string
+	Input             string
+	InputContentType  string
+	Output            string
+	OutputContentType string
+	Envelope          string
+	Container         string
+	Error             string
+	ErrorCodes        []string
+	File              *fileOutput
+	ResponseHeaders   []responseHeader
+	NoOutputStatus    int
+	Termination       string
+	codec             *codec // encoding of the input and output (nil for JSON)
+	start             int    // offset of the endpoint header in JSONDoc.md
+	level             int    // level of the endpoint header
+	handler           bool   // introduced by the handler action (see handlerRoute)
+}
+
+func (e *endpoint) Title() string {
+	return e.Method + " " + e.Path
+}
+
+// jsonInput reports whether the endpoint has a JSON input.
+func (e *endpoint) jsonInput() bool {
+	return e.Input != "" && e.InputContentType == ""
+}
+
+// endpoint introduces a new endpoint: it returns the markdown header
+// for it (with a stable id) and makes it the current endpoint for the
+// following input and output actions. The level of the header may be
+// given as the optional last argument.
+func (d *JSONDoc) endpoint(method, path string, level ...int) (string, error) {
+	method = strings.ToUpper(method)
+	if path == "" {
+		return "", fmt.Errorf("endpoint %s: empty path", method)
+	}
+	l, err := optLevel(level, d.headingLevel(), maxEndpointLevel)
+	if err != nil {
+		return "", fmt.Errorf("endpoint %s %s: %v", method, path, err)
+	}
+	e := &endpoint{Method: method, Path: path, start: d.md.Len(), level: l}
+	e.ID = d.uniqueID("endpoint-" + idFromString(method+path))
+	d.endpoints = append(d.endpoints, e)
+	d.addAnchor(anchor{ID: e.ID, Kind: "endpoint", Title: e.Title(), Method: e.Method, Path: e.Path})
+	return fmt.Sprintf("%s %s `%s` {#%s}\n", heading(l), method, path, e.ID), nil
+}
+
+// currentEndpoint returns the endpoint the input and output actions
+// currently refer to. An endpoint ends with the next markdown header of
+// the same or higher level (such as a regular section of the document).
+func (d *JSONDoc) currentEndpoint() *endpoint {
+	if len(d.endpoints) == 0 {
+		return nil
+	}
+	e := d.endpoints[len(d.endpoints)-1]
+	text := d.md.Bytes()[e.start:]
+	if i := bytes.IndexByte(text, '\n'); i != -1 {
+		text = text[i:] // skip the endpoint header itself
+	}
+	for l := 1; l <= e.level; l++ {
+		if bytes.Contains(text, []byte("\n"+heading(l)+" ")) {
+			return nil
+		}
+	}
+	return e
+}
+
+// idFromString returns a string suitable for use as an HTML id
+// containing only lower case letters, digits and dashes.
+func idFromString(s string) string {
+	var b bytes.Buffer
+	dash := false
+	for _, c := range strings.ToLower(s) {
+		if c >= 'a' && c <= 'z' || c >= '0' && c <= '9' {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(c)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// uniqueID returns id or, if it was already used, id with a numeric
+// suffix making it unique.
+func (d *JSONDoc) uniqueID(id string) string {
+	s := id
+	for i := 1; d.ids[s]; i++ {
+		s = fmt.Sprintf("%s-%d", id, i)
+	}
+	d.ids[s] = true
+	return s
+}
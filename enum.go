@@ -0,0 +1,290 @@
+package jsondoc
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"html"
+	"strings"
+)
+
+// enumValue is a constant of a named type.
+type enumValue struct {
+	Name        string // Go name of the constant
+	Value       string // value as in JSON (strings are quoted)
+	Description string // doc comment of the constant
+
+	bits  uint64 // value of an integer constant (zero if negative or not an integer)
+	shift bool   // declared with a shift (such as 1 << iota)
+}
+
+// enumValues returns the constants declared in the package of the
+// named type with this type (in the order of declaration). Their
+// values are obtained from the type checker (or, if it could not
+// evaluate them, from their expressions).
+func (d *JSONDoc) enumValues(t *ast.TypeSpec, c *context) ([]enumValue, error) {
+	if _, err := d.checkedPackage(c.Path); err != nil {
+		return nil, err
+	}
+	tn, ok := d.typeDef(t.Name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %s was not type checked", t.Name.Name)
+	}
+	var values []enumValue
+	for _, f := range sortedFiles(c.Package) {
+		for _, decl := range f.Decls {
+			g, ok := decl.(*ast.GenDecl)
+			if !ok || g.Tok != token.CONST {
+				continue
+			}
+			var exprs []ast.Expr
+			for iota, spec := range g.Specs {
+				s := spec.(*ast.ValueSpec)
+				if s.Values != nil {
+					exprs = s.Values
+				}
+				doc := s.Doc
+				if doc == nil {
+					doc = s.Comment
+				}
+				if doc == nil && len(g.Specs) == 1 {
+					doc = g.Doc
+				}
+				for i, name := range s.Names {
+					o, ok := d.typeDef(name).(*types.Const)
+					if !ok || name.Name == "_" || !types.Identical(o.Type(), tn.Type()) {
+						continue
+					}
+					val := o.Val()
+					if val.Kind() == constant.Unknown && i < len(exprs) {
+						val = d.evalConst(exprs[i], iota)
+					}
+					if val.Kind() == constant.Unknown {
+						d.warnf("warning: value of constant %s of type %s is unknown\n", name.Name, t.Name.Name)
+						continue
+					}
+					v := enumValue{Name: name.Name, Value: val.ExactString(), Description: strings.TrimSpace(doc.Text())}
+					if u, ok := constant.Uint64Val(constant.ToInt(val)); ok {
+						v.bits = u
+					}
+					v.shift = i < len(exprs) && hasShift(exprs[i])
+					values = append(values, v)
+				}
+			}
+		}
+	}
+	return values, nil
+}
+
+// evalConst returns the value of the constant expression (with the
+// given value of iota) or an unknown value if it cannot be evaluated.
+// Implicitly repeated expressions of constant declarations are
+// evaluated with the iota of the repeating declaration.
+func (d *JSONDoc) evalConst(e ast.Expr, iota int) (v constant.Value) {
+	defer func() {
+		if recover() != nil {
+			// an invalid operation (such as division by zero)
+			v = constant.MakeUnknown()
+		}
+	}()
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		return constant.MakeFromLiteral(e.Value, e.Kind, 0)
+	case *ast.Ident:
+		switch e.Name {
+		case "iota":
+			return constant.MakeInt64(int64(iota))
+		case "true", "false":
+			return constant.MakeBool(e.Name == "true")
+		}
+		if o, ok := d.typeUse(e).(*types.Const); ok {
+			return o.Val()
+		}
+	case *ast.SelectorExpr:
+		if o, ok := d.typeUse(e.Sel).(*types.Const); ok {
+			return o.Val()
+		}
+	case *ast.ParenExpr:
+		return d.evalConst(e.X, iota)
+	case *ast.CallExpr:
+		// conversion to a constant type
+		if len(e.Args) == 1 {
+			return d.evalConst(e.Args[0], iota)
+		}
+	case *ast.UnaryExpr:
+		return constant.UnaryOp(e.Op, d.evalConst(e.X, iota), 0)
+	case *ast.BinaryExpr:
+		x, y := d.evalConst(e.X, iota), d.evalConst(e.Y, iota)
+		if x.Kind() == constant.Unknown || y.Kind() == constant.Unknown {
+			break
+		}
+		switch e.Op {
+		case token.SHL, token.SHR:
+			if s, ok := constant.Uint64Val(y); ok {
+				return constant.Shift(x, e.Op, uint(s))
+			}
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			return constant.MakeBool(constant.Compare(x, e.Op, y))
+		case token.QUO:
+			if x.Kind() == constant.Int && y.Kind() == constant.Int {
+				return constant.BinaryOp(x, token.QUO_ASSIGN, y)
+			}
+			return constant.BinaryOp(x, e.Op, y)
+		default:
+			return constant.BinaryOp(x, e.Op, y)
+		}
+	}
+	return constant.MakeUnknown()
+}
+
+// constsID returns the id of the table of the constants of the named
+// type (as given to the consts action).
+func constsID(name string) string {
+	return "consts-" + idFromString(name)
+}
+
+// consts returns the table of the constants of the named type (as
+// given to the input and output actions) with their values, Go names
+// and descriptions.
+func (d *JSONDoc) consts(name string) (string, error) {
+	t, c, err := d.lookupTypeName(name)
+	if err != nil {
+		return "", err
+	}
+	values, err := d.enumValues(t, c)
+	if err != nil {
+		return "", fmt.Errorf("consts %s: %v", name, err)
+	}
+	if len(values) == 0 {
+		return "", fmt.Errorf("consts %s: no constants of the type", name)
+	}
+	var b bytes.Buffer
+	b.WriteString("<div>\n")
+	if isBitmask(values) {
+		fmt.Fprintf(&b, "<p>%s values are bitwise ORs of the following flags.</p>\n", d.format())
+	}
+	d.writeConstsTable(&b, constsID(name), values)
+	b.WriteString("</div>\n")
+	return b.String(), nil
+}
+
+// writeConstsTable writes the table of the constants (with the given
+// id if not empty).
+func (d *JSONDoc) writeConstsTable(b *bytes.Buffer, id string, values []enumValue) {
+	if id != "" {
+		fmt.Fprintf(b, "<table id=\"%s\">\n", id)
+	} else {
+		b.WriteString("<table>\n")
+	}
+	b.WriteString("<caption>Values of the constants</caption>\n")
+	b.WriteString("<tr>\n<th scope=\"col\">Value</th>\n<th scope=\"col\">Name</th>\n<th scope=\"col\">Description</th>\n</tr>\n")
+	for _, v := range values {
+		fmt.Fprintf(b, "<tr>\n<td><code>%s</code></td>\n<td>%s</td>\n<td>%s</td>\n</tr>\n", html.EscapeString(v.Value), html.EscapeString(v.Name), d.linkTerms(html.EscapeString(v.Description)))
+	}
+	b.WriteString("</table>\n")
+}
+
+// hasShift reports whether the constant expression contains a shift
+// to the left.
+func hasShift(e ast.Expr) bool {
+	found := false
+	ast.Inspect(e, func(n ast.Node) bool {
+		if b, ok := n.(*ast.BinaryExpr); ok && b.Op == token.SHL {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// isBitmask reports whether the constants are flags: integers declared
+// with shifts (such as 1 << iota) each of which (except for zero) has a
+// single distinct bit set. The values alone do not tell flags from
+// enumerations (the values of iota are 0, 1 and 2).
+func isBitmask(values []enumValue) bool {
+	flags := 0
+	var seen uint64
+	for _, v := range values {
+		if v.bits == 0 {
+			if v.Value != "0" {
+				return false
+			}
+			continue
+		}
+		if !v.shift || v.bits&(v.bits-1) != 0 || seen&v.bits != 0 {
+			return false
+		}
+		seen |= v.bits
+		flags++
+	}
+	return flags >= 2
+}
+
+// renderEnum writes to d.b the table of the constants of the named
+// type (if it has any) noting that its values are bitwise ORs of them
+// if they are flags.
+func (d *JSONDoc) renderEnum(t *ast.TypeSpec, c *context) {
+	if _, ok := t.Type.(*ast.Ident); !ok || t.Name.Obj == nil {
+		return
+	}
+	values, err := d.enumValues(t, c)
+	if err != nil || len(values) == 0 {
+		return
+	}
+	if isBitmask(values) {
+		fmt.Fprintf(&d.b, "<p>%s values are bitwise ORs of the following flags.</p>\n", d.format())
+	} else {
+		fmt.Fprintf(&d.b, "<p>Allowed values:</p>\n")
+	}
+	d.writeConstsTable(&d.b, "", values)
+}
+
+// maxInlineValues is the maximum number of the allowed values listed
+// in the rows of fields.
+const maxInlineValues = 5
+
+// allowedValues returns the list of the allowed values of the type of
+// a field (if it is a named type with constants) to be appended to its
+// type in the row of the field. Long lists are truncated with a link
+// to the section of the type.
+func (d *JSONDoc) allowedValues(t ast.Expr, c *context) string {
+	if s, ok := t.(*ast.StarExpr); ok {
+		t = s.X
+	}
+	switch t.(type) {
+	case *ast.Ident, *ast.SelectorExpr:
+	default:
+		return ""
+	}
+	if d.stdType(t, c) {
+		return ""
+	}
+	ts, c, err := d.lookupType(t, c)
+	if err != nil || ts == nil {
+		return ""
+	}
+	if _, ok := ts.Type.(*ast.Ident); !ok || ts.Name.Obj == nil {
+		return ""
+	}
+	values, err := d.enumValues(ts, c)
+	if err != nil || len(values) == 0 {
+		return ""
+	}
+	s := "one of"
+	if isBitmask(values) {
+		s = "bitwise OR of"
+	}
+	var list []string
+	for i, v := range values {
+		if i == maxInlineValues {
+			id := d.renderLater(ts.Name.Name, nil, c)
+			list = append(list, fmt.Sprintf(`… (<a href="#%s">all %d values</a>)`, html.EscapeString(id), len(values)))
+			break
+		}
+		list = append(list, "<code>"+html.EscapeString(v.Value)+"</code>")
+	}
+	return "<br>" + s + ": " + strings.Join(list, ", ")
+}
@@ -0,0 +1,31 @@
+package jsondoc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBitmask(t *testing.T) {
+	tests := []struct {
+		name, decl string
+		bitmask    bool
+	}{
+		{"iota", "Red Color = iota\n\tGreen\n\tBlue", false},
+		{"iota+1", "Red Color = iota + 1\n\tGreen", false},
+		{"literals", "Red Color = 1\n\tGreen Color = 2\n\tBlue Color = 4", false},
+		{"shift", "Red Color = 1 << iota\n\tGreen\n\tBlue", true},
+		{"none and shift", "None Color = 0\n\tRed Color = 1 << iota\n\tGreen", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "package api\n\ntype Color int\n\nconst (\n\t" + tt.decl + "\n)\n\ntype Paint struct {\n\tColor Color `json:\"color\"`\n}\n"
+			md := renderMarkdown(t, src, "{{output \"Paint\"}}\n{{consts \"Color\"}}\n")
+			if got := strings.Contains(md, "bitwise OR"); got != tt.bitmask {
+				t.Errorf("bitwise OR in the documentation = %v, want %v\n%s", got, tt.bitmask, md)
+			}
+			if !tt.bitmask && !strings.Contains(md, "one of") {
+				t.Errorf("allowed values not listed\n%s", md)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package jsondoc
+
+import (
+	"fmt"
+	"go/ast"
+	"html"
+	"strings"
+)
+
+// envelopeField returns the field of the envelope struct type holding
+// the wrapped data (the first field of type interface{}, any or
+// json.RawMessage) and its JSON key.
+func (d *JSONDoc) envelopeField(t *ast.TypeSpec, c *context) (*ast.Field, string, error) {
+	st, ok := t.Type.(*ast.StructType)
+	if !ok {
+		return nil, "", fmt.Errorf("envelope type %s is not a struct", t.Name.Name)
+	}
+	for _, f := range st.Fields.List {
+		if len(f.Names) != 1 || !d.isEnvelopeData(f.Type, c) {
+			continue
+		}
+		key, _, err := d.jsonKey(f.Names[0].Name, f.Tag)
+		if err != nil {
+			continue
+		}
+		return f, key, nil
+	}
+	return nil, "", fmt.Errorf("envelope type %s has no field of type interface{} or json.RawMessage for the data", t.Name.Name)
+}
+
+func (d *JSONDoc) isEnvelopeData(t ast.Expr, c *context) bool {
+	if s, ok := t.(*ast.StarExpr); ok {
+		t = s.X
+	}
+	switch t := t.(type) {
+	case *ast.InterfaceType:
+		return t.Methods == nil || len(t.Methods.List) == 0
+	case *ast.Ident:
+		return t.Name == "any"
+	case *ast.SelectorExpr:
+		ident, ok := t.X.(*ast.Ident)
+		if !ok || t.Sel.Name != "RawMessage" {
+			return false
+		}
+		path, err := d.findImportIdent(c.File, ident.Name)
+		return err == nil && path == "encoding/json"
+	}
+	return false
+}
+
+// envelope documents the output of type name wrapped in the data field
+// of the envelope type env.
+func (d *JSONDoc) envelope(env, name string, level ...int) (string, error) {
+	return d.renderOutput(name, env, "", level)
+}
+
+// paginated documents the output of a list endpoint returning the
+// values of type name in pages: an array of the values in the data
+// field of the page type (an envelope type with the fields of the
+// pagination, such as the cursor of the next page).
+func (d *JSONDoc) paginated(page, name string, level ...int) (string, error) {
+	return d.renderOutput(name, page, "array", level)
+}
+
+// outputList documents the output of the current endpoint as a JSON
+// array of values of type name.
+func (d *JSONDoc) outputList(name string, level ...int) (string, error) {
+	return d.renderOutput(name, "", "array", level)
+}
+
+// outputMap documents the output of the current endpoint as a JSON
+// object mapping (arbitrary) keys to values of type name.
+func (d *JSONDoc) outputMap(name string, level ...int) (string, error) {
+	return d.renderOutput(name, "", "object", level)
+}
+
+// containerTitle returns the title of the values of type name in the
+// container (see endpoint.Container).
+func containerTitle(container, name string) string {
+	switch container {
+	case "array":
+		return "array of " + name
+	case "object":
+		return "map of " + name
+	}
+	return name
+}
+
+// wrapSample returns the sample v of the type of the values in the
+// container (see endpoint.Container).
+func wrapSample(container string, v interface{}) interface{} {
+	switch container {
+	case "array":
+		return []interface{}{v}
+	case "object":
+		return object{{"key", v}}
+	}
+	return v
+}
+
+// renderEnvelope renders (in the section with the given id) the
+// envelope type env with its data field linking to the type name (in
+// the container, if any) rendered below it.
+func (d *JSONDoc) renderEnvelope(env, name, container, id string) error {
+	d.graphParent = id
+	t, c, err := d.lookupTypeName(env)
+	if err != nil {
+		return err
+	}
+	f, _, err := d.envelopeField(t, c)
+	if err != nil {
+		return err
+	}
+	dt, dc, err := d.lookupTypeName(name)
+	if err != nil {
+		return err
+	}
+	link := html.EscapeString(dt.Name.Name)
+	if id := d.renderLater(dt.Name.Name, nil, dc); id != "" {
+		link = fmt.Sprintf(`<a href="#%s">%s</a>`, html.EscapeString(id), link)
+	}
+	d.dataField, d.dataLink = f, containerTitle(container, link)
+	err = d.renderType(t, c)
+	d.dataField, d.dataLink = nil, ""
+	if err != nil {
+		return err
+	}
+	return d.renderQueued()
+}
+
+// sampleEnvelope returns a sample of the envelope type env with the
+// sample of type name (in the container, if any) as its data.
+func (d *JSONDoc) sampleEnvelope(env, name, container string) (interface{}, error) {
+	t, c, err := d.lookupTypeName(env)
+	if err != nil {
+		return nil, err
+	}
+	_, key, err := d.envelopeField(t, c)
+	if err != nil {
+		return nil, err
+	}
+	v, err := d.sampleByName(env)
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.sampleByName(name)
+	if err != nil {
+		return nil, err
+	}
+	data = wrapSample(container, data)
+	o := v.(object)
+	for i := range o {
+		if o[i].Key == key {
+			o[i].Value = data
+		}
+	}
+	return o, nil
+}
+
+// outputSample returns the captured output of the endpoint or a sample
+// output (nil if the endpoint has no output).
+func (d *JSONDoc) outputSample(e *endpoint) (interface{}, error) {
+	if e.Output == "" {
+		return nil, nil
+	}
+	if v, err := d.captured(e, true); err != nil || v != nil {
+		return v, err
+	}
+	if e.Envelope != "" {
+		return d.sampleEnvelope(e.Envelope, e.Output, e.Container)
+	}
+	v, err := d.sampleByName(e.Output)
+	if err != nil {
+		return nil, err
+	}
+	return wrapSample(e.Container, v), nil
+}
+
+// typeIdent returns the type name without the package name.
+func typeIdent(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i != -1 {
+		return name[i+1:]
+	}
+	return name
+}
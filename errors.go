@@ -0,0 +1,60 @@
+package jsondoc
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// stdError documents the standard error object of type name: its table
+// is rendered once (at the first use) and the following uses link to
+// it. In an endpoint the status codes of the errors of the endpoint are
+// given as the following arguments (and the error responses are added
+// to the OpenAPI description and checked by Check).
+func (d *JSONDoc) stdError(name string, codes ...string) (string, error) {
+	d.b.Reset()
+	e := d.currentEndpoint()
+	if e != nil && len(codes) == 0 {
+		return "", fmt.Errorf("stdError %s: no status codes of the errors of endpoint %s", name, e.Title())
+	} else if e == nil && len(codes) > 0 {
+		return "", fmt.Errorf("stdError %s: status codes given outside of an endpoint", name)
+	}
+	var statuses, normalized []string
+	for _, code := range codes {
+		n, err := strconv.Atoi(code)
+		if err != nil || n < 400 || n > 599 {
+			return "", fmt.Errorf("stdError %s: invalid status code %q of an error", name, code)
+		}
+		normalized = append(normalized, strconv.Itoa(n))
+		statuses = append(statuses, "<code>"+html.EscapeString(statusLine(n))+"</code>")
+	}
+	l, err := d.sectionLevel(e, nil)
+	if err != nil {
+		return "", fmt.Errorf("stdError %s: %v", name, err)
+	}
+	id := d.sectionID(e, "errors", name)
+	if e != nil {
+		e.ErrorCodes = normalized
+	}
+	fmt.Fprintf(&d.b, "%s Errors (%s) {#%s}\n<div>\n", heading(l), markdownEscapeString(typeIdent(name)), id)
+	if len(statuses) > 0 {
+		fmt.Fprintf(&d.b, "<p>Status codes of the errors: %s.</p>\n", strings.Join(statuses, ", "))
+	}
+	if err := d.renderTypes(name, id); err != nil {
+		return "", err
+	}
+	d.b.WriteString("</div>\n")
+	return d.b.String(), nil
+}
+
+// errorStatus reports whether the status is a status code of an error
+// of the endpoint reported with the standard error object.
+func (e *endpoint) errorStatus(status int) bool {
+	for _, code := range e.ErrorCodes {
+		if code == strconv.Itoa(status) {
+			return true
+		}
+	}
+	return false
+}
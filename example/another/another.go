@@ -8,3 +8,10 @@ type Another struct {
 	A int
 	S string
 }
+
+// Address is a postal address
+type Address struct {
+	Street  string `json:"street"`  // street and number
+	City    string `json:"city"`    // city (or town)
+	Country string `json:"country"` // ISO 3166-1 alpha-2 country code
+}
@@ -5,26 +5,41 @@
 package example
 
 import (
+	"encoding/xml"
+	"mime/multipart"
+	"time"
+
 	"github.com/lukpank/jsondoc/example/another"
 	another1 "github.com/lukpank/jsondoc/example/another"
 )
 
 type helloInput struct {
-	Name string `json:"test"` // Name to be used in greetings
+	Name string `json:"test" validate:"required,max=64"` // Name to be used in greetings
 	A, B int    // Some numeric parameter
 	Size size   `json:"size"`
 }
 
+// envelope wraps outputs of endpoints
+type envelope struct {
+	Data  interface{} `json:"data"`            // the requested data
+	Error string      `json:"error,omitempty"` // only present if there was an error
+}
+
 type helloOutput struct {
 	Msg string `json:"msg"` // Greetings message for the provided name
 }
 
 // indexInput specifies input for /item/get request
+//
+// mutually-exclusive: id, sku
 type itemGetInput struct {
-	ID int64    `json:"id"` // ID of the requested item
-	A  string   `json:"a"`  // A represents something
-	B  []string `json:"b"`
-	C  struct {
+	ID  int64  `json:"id,omitempty" validate:"min=1"` // ID of the requested item
+	SKU string `json:"sku,omitempty"`                 // stock keeping unit of the requested item
+	// required-with: sku
+	Warehouse string   `json:"warehouse,omitempty"` // warehouse the SKU is looked up in
+	A         string   `json:"a"`                   // A represents something
+	B         []string `json:"b"`
+	C         struct {
 		D, E int
 		F    []struct {
 			A, B int
@@ -38,12 +53,16 @@ type itemGetInput struct {
 
 // itemGetOutput specifies output of /item/get request
 type itemGetOutput struct {
-	RequestID string `json:"request_id"`      // request ID assigned by the server
-	Error     string `json:"error,omitempty"` // only present if there was an error
-	Name      string `json:"name"`
-	Size      size   `json:"size"`
-	Info      info   `json:"info"` // type with anonymous field
-	C         struct {
+	// format: uuid
+	RequestID string `json:"request_id"` // request ID assigned by the server
+	// present-when: status == "error"
+	Error    string     `json:"error,omitempty"` // reason the item could not be retrieved
+	Name     string     `json:"name"`
+	Status   itemStatus `json:"status"`
+	Priority priority   `json:"priority"`
+	Size     size       `json:"size"`
+	Info     info       `json:"info"` // type with anonymous field
+	C        struct {
 		A, B string
 	}
 	F []struct {
@@ -51,6 +70,91 @@ type itemGetOutput struct {
 	} `json:"f"`
 }
 
+// apiError is reported by the endpoints failing to process a request
+type apiError struct {
+	Code    string `json:"code"`    // machine readable code of the error
+	Message string `json:"message"` // description of the error
+}
+
+// pageParams are the parameters of the requests for pages of lists
+type pageParams struct {
+	Cursor string `json:"cursor,omitempty"`                             // cursor of the requested page (the first page if empty)
+	Limit  int    `json:"limit,omitempty" validate:"omitempty,max=100"` // maximum number of elements of the page (20 if zero)
+}
+
+type itemListInput struct {
+	pageParams
+	Status itemStatus `json:"status,omitempty"` // only items with the given status are listed
+}
+
+// page wraps a page of a list
+type page struct {
+	Items      interface{} `json:"items"`                 // elements of the page
+	NextCursor string      `json:"next_cursor,omitempty"` // cursor of the next page (empty on the last page)
+}
+
+// itemExportRow is a row of the exported list of items
+type itemExportRow struct {
+	ID       int64  `json:"id"`       // ID of the item
+	Name     string `json:"name"`     // name of the item
+	Quantity int    `json:"quantity"` // number of items in stock
+}
+
+// stockChange is a change of the stock level of an item
+type stockChange struct {
+	ItemID   int64 `json:"item_id"`  // ID of the item
+	Quantity int   `json:"quantity"` // number of items in stock after the change
+}
+
+// warehouse stores the items
+type warehouse struct {
+	Code   string    `json:"code"`            // code of the warehouse
+	City   string    `json:"city"`            // city of the warehouse
+	Opened time.Time `json:"opened,omitzero"` // time the warehouse was opened (not present if unknown)
+}
+
+type photoUploadInput struct {
+	ItemID  int                   `form:"item_id"`                        // ID of the product
+	Caption string                `form:"caption,omitempty"`              // caption shown below the photo
+	Photo   *multipart.FileHeader `form:"photo" contentType:"image/jpeg"` // the photo
+	Info    info                  `form:"info,omitempty"`                 // additional information
+}
+
+type loginInput struct {
+	Username string `schema:"username"`           // name of the user
+	Password string `schema:"password"`           // password of the user
+	Remember bool   `schema:"remember,omitempty"` // keep the user logged in
+}
+
+type stockQuery struct {
+	XMLName   xml.Name `xml:"query"`
+	ID        int      `xml:"id,attr"`                  // ID of the product
+	Warehouse string   `xml:"warehouse,attr,omitempty"` // code of the warehouse
+}
+
+type stockReport struct {
+	XMLName xml.Name     `xml:"report"`
+	ID      int          `xml:"id,attr"`      // ID of the product
+	Updated time.Time    `xml:"updated"`      // time of the last stock update
+	Levels  []stockLevel `xml:"levels>level"` // stock levels in warehouses
+	Note    string       `xml:",comment"`     // a comment added by the warehouse system
+}
+
+type stockLevel struct {
+	Warehouse string `xml:"warehouse,attr"` // code of the warehouse
+	Quantity  int    `xml:",chardata"`      // number of items in stock
+}
+
+type eventBatch struct {
+	Source string  `cbor:"1,keyasint"`           // name of the reporting service
+	Events []event `cbor:"2,keyasint,omitempty"` // reported events
+}
+
+type event struct {
+	Name string `json:"name"`                      // name of the event
+	At   int64  `json:"at" msgpack:"ts" cbor:"ts"` // time of the event (Unix seconds)
+}
+
 type size struct {
 	Length float64 `json:"length"` // length of the object
 	Width  float64 `json:"width"`  // width of the object
@@ -59,9 +163,40 @@ type size struct {
 
 type info struct {
 	size
-	Weight float64 `json:"weight"` // weight of the object
+	Weight   float64       `json:"weight"`   // weight of the object
+	Handling handlingFlags `json:"handling"` // handling requirements
 }
 
+type handlingFlags uint8
+
+const (
+	handlingFragile  handlingFlags = 1 << iota // handle with care
+	handlingUpright                            // keep upright
+	handlingKeepCold                           // keep below 8°C
+)
+
+type itemStatus string
+
+const (
+	statusAvailable itemStatus = "available" // the item may be ordered
+	statusReserved  itemStatus = "reserved"  // all the items in stock are reserved
+
+	// statusDiscontinued means that the item is no longer produced.
+	statusDiscontinued itemStatus = "discontinued"
+
+	statusError itemStatus = "error" // the item could not be retrieved
+)
+
+type priority int
+
+const (
+	_              priority = iota * 10 // zero means that the priority is not set
+	priorityLow                         // processed when there is nothing else to do
+	priorityNormal                      // the default priority
+	_
+	priorityUrgent = priorityNormal + 100 // processed immediately
+)
+
 type empty struct{}
 type emptyA []struct{}
 type emptyAA [][]struct{}
@@ -0,0 +1,140 @@
+package jsondoc
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"html"
+	"sort"
+)
+
+// externalType is a type of another package referenced by the
+// documented types but not expanded (such as time.Time).
+type externalType struct {
+	Name string // qualified with the package name (such as "time.Time")
+	Path string // import path of the package
+	ID   string // id of the row of the type in the "External types" chapter
+	JSON string // JSON representation (HTML)
+}
+
+const externalTypesPlaceholder = "<!--jsondoc-external-types-->"
+
+// externalJSON are the JSON representations of the common external
+// types (map: package path and type name -> HTML description).
+var externalJSON = map[string]string{
+	"time.Time":                             "string with the date and time in RFC 3339 format (such as <code>\"2006-01-02T15:04:05Z\"</code>)",
+	"time.Duration":                         "integer number of nanoseconds",
+	"encoding/json.RawMessage":              "any JSON value",
+	"encoding/json.Number":                  "number",
+	"math/big.Int":                          "integer number (of arbitrary precision)",
+	"math/big.Float":                        "string with the decimal number (of arbitrary precision)",
+	"math/big.Rat":                          "string with the fraction (such as <code>\"1/3\"</code>)",
+	"net.IP":                                "string with the IPv4 or IPv6 address (such as <code>\"192.0.2.1\"</code>)",
+	"net/netip.Addr":                        "string with the IPv4 or IPv6 address (such as <code>\"192.0.2.1\"</code>)",
+	"github.com/google/uuid.UUID":           "string with the UUID (such as <code>\"f47ac10b-58cc-4372-a567-0e02b2c3d479\"</code>)",
+	"github.com/shopspring/decimal.Decimal": "string with the decimal number (such as <code>\"3.14\"</code>)",
+}
+
+// externalTypeLink records the type of another package which is not
+// expanded in the documentation and returns the link to its row in the
+// "External types" chapter.
+func (d *JSONDoc) externalTypeLink(t *ast.SelectorExpr, path string) string {
+	key := path + "." + t.Sel.Name
+	text := fmt.Sprintf("%s.%s", t.X, t.Sel.Name)
+	if d.externalIDs == nil {
+		d.externalIDs = make(map[string]string)
+	}
+	id, ok := d.externalIDs[key]
+	if !ok {
+		name := text
+		if o, ok := d.typeUse(t.Sel).(*types.TypeName); ok && o.Pkg() != nil {
+			name = o.Pkg().Name() + "." + t.Sel.Name
+		}
+		id = d.uniqueID("external-" + idFromString(name))
+		d.externalIDs[key] = id
+		d.externalTypes = append(d.externalTypes, externalType{name, path, id, d.externalJSON(key, t)})
+	}
+	return fmt.Sprintf(`<a href="#%s">%s</a>`, html.EscapeString(id), html.EscapeString(text))
+}
+
+// externalJSON returns the JSON representation of the external type
+// (known or derived from the methods and the underlying type as
+// resolved by the type checker).
+func (d *JSONDoc) externalJSON(key string, t *ast.SelectorExpr) string {
+	if s, ok := externalJSON[key]; ok {
+		return s
+	}
+	if path := key[:len(key)-len(t.Sel.Name)-1]; path == "C" {
+		return cTypeJSON(t.Sel.Name)
+	}
+	unknown := "unknown (see the documentation of the package)"
+	o, ok := d.typeUse(t.Sel).(*types.TypeName)
+	if !ok {
+		return unknown
+	}
+	ms := types.NewMethodSet(types.NewPointer(o.Type()))
+	switch {
+	case ms.Lookup(nil, "MarshalJSON") != nil:
+		return "custom (defined by its MarshalJSON method)"
+	case ms.Lookup(nil, "MarshalText") != nil:
+		return "string (defined by its MarshalText method)"
+	}
+	switch u := o.Type().Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "boolean"
+		case u.Info()&types.IsString != 0:
+			return "string"
+		case u.Info()&types.IsNumeric != 0:
+			return "number"
+		}
+	case *types.Struct:
+		return "object with the exported fields of the type"
+	case *types.Slice:
+		if b, ok := u.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte {
+			return "string (base64 encoded)"
+		}
+		return "array"
+	case *types.Array:
+		return "array"
+	case *types.Map:
+		return "object"
+	}
+	return unknown
+}
+
+// externalTypesChapter marks the place of the "External types" chapter.
+func (d *JSONDoc) externalTypesChapter() string {
+	d.externalTypesUsed = true
+	return heading(d.headingLevel()) + " External types {#external-types}\n\n<div>\n" + externalTypesPlaceholder + "\n</div>\n"
+}
+
+// resolveExternalTypes returns the markdown with the table of the
+// external types referenced in the whole document inserted in the
+// "External types" chapter (appended at the end if the externalTypes
+// action is not used).
+func (d *JSONDoc) resolveExternalTypes(md []byte) []byte {
+	if len(d.externalTypes) == 0 && !d.externalTypesUsed {
+		return md
+	}
+	ts := append([]externalType(nil), d.externalTypes...)
+	sort.SliceStable(ts, func(i, j int) bool { return ts[i].Name < ts[j].Name })
+	var b bytes.Buffer
+	if len(ts) == 0 {
+		b.WriteString("<p>No types of other packages are referenced.</p>")
+	} else {
+		b.WriteString("<p>Types of other packages referenced in the documentation and their JSON representation.</p>\n<table>\n<caption>External types</caption>\n<tr>\n<th scope=\"col\">Type</th>\n<th scope=\"col\">Package</th>\n<th scope=\"col\">JSON representation</th>\n</tr>\n")
+		for _, t := range ts {
+			fmt.Fprintf(&b, "<tr id=\"%s\">\n<td>%s</td>\n<td>%s</td>\n<td>%s</td>\n</tr>\n", html.EscapeString(t.ID), html.EscapeString(t.Name), html.EscapeString(t.Path), t.JSON)
+		}
+		b.WriteString("</table>")
+	}
+	if d.externalTypesUsed {
+		return bytes.Replace(md, []byte(externalTypesPlaceholder), b.Bytes(), 1)
+	}
+	md = append(md, "\n"+heading(d.headingLevel())+" External types {#external-types}\n\n<div>\n"...)
+	md = append(md, b.Bytes()...)
+	return append(md, "\n</div>\n"...)
+}
@@ -0,0 +1,220 @@
+package jsondoc
+
+import (
+	"fmt"
+	"go/ast"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Fake values are used in samples instead of zero values. They are
+// chosen based on the JSON key of the field (such as "email" or
+// "created_at") and are reproducible for the given seed (see -seed).
+// Names, addresses and phone numbers follow the configured locale.
+// The format and the limits given in the comment or in the validate tag
+// of the field (see fieldComment and fieldLimits) take precedence.
+
+var (
+	fakeWords     = []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel", "india", "juliet"}
+	fakeSentences = []string{"Lorem ipsum dolor sit amet.", "The quick brown fox jumps over the lazy dog.",
+		"Everything went fine.", "Please try again later."}
+)
+
+// fakeEpoch is the base of fake timestamps (so that they do not depend
+// on the current time).
+var fakeEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fakeField is the field a fake value is chosen for.
+type fakeField struct {
+	Key    string // JSON key of the field (if any)
+	Format string // format of the values (such as "uuid" or "date-time")
+	Limits limits // limits of the values or of the lengths of the strings
+}
+
+// fieldFake returns the fake field of the struct field with the given
+// key.
+func fieldFake(key string, f *ast.Field) fakeField {
+	fc := parseFieldComment(f)
+	l, _ := fieldLimits(f, fc) // invalid limits are reported with the field
+	return fakeField{key, fc.Format, l}
+}
+
+// elem returns the fake field of the elements of an array field or of
+// the values of a map field (its limits limit the number of elements
+// or members, not the elements).
+func (f fakeField) elem() fakeField {
+	return fakeField{Key: f.Key, Format: f.Format}
+}
+
+// normalizeKey returns the key in lower case without underscores and
+// dashes (so that "created_at", "createdAt" and "created-at" are alike).
+func normalizeKey(key string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+}
+
+func (d *JSONDoc) pick(a []string) string {
+	return a[d.rand.Intn(len(a))]
+}
+
+func (d *JSONDoc) fakeTime() time.Time {
+	return fakeEpoch.Add(time.Duration(d.rand.Intn(365*24*3600)) * time.Second)
+}
+
+// fakeString returns a string value of the format of the field or, if
+// it has none, a realistic value for its JSON key of a length within
+// its limits.
+func (d *JSONDoc) fakeString(f fakeField) string {
+	switch f.Format {
+	case "uuid":
+		return d.fakeUUID()
+	case "date-time":
+		return d.fakeTime().Format(time.RFC3339)
+	case "date":
+		return d.fakeTime().Format("2006-01-02")
+	case "time":
+		return d.fakeTime().Format("15:04:05")
+	case "email":
+		return d.fakeString(fakeField{Key: "email"})
+	case "uri", "url", "uri-reference", "iri":
+		return "https://example.com/" + d.pick(fakeWords)
+	case "hostname":
+		return d.pick(fakeWords) + ".example.com"
+	case "ipv4":
+		return d.fakeString(fakeField{Key: "ip"})
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x", 1+d.rand.Intn(0xfffe))
+	case "byte":
+		return "ZXhhbXBsZQ=="
+	}
+	s := d.fakeKeyString(f.Key)
+	if n := utf8.RuneCountInString(s); f.Limits.Max != nil && float64(n) > *f.Limits.Max {
+		s = string([]rune(s)[:int(math.Max(*f.Limits.Max, 0))])
+	} else if f.Limits.Min != nil && float64(n) < *f.Limits.Min {
+		s += strings.Repeat("x", int(math.Ceil(*f.Limits.Min))-n)
+	}
+	return s
+}
+
+// fakeKeyString returns a realistic string value for the given JSON
+// key.
+func (d *JSONDoc) fakeKeyString(key string) string {
+	k := normalizeKey(key)
+	l := d.locale()
+	switch {
+	case strings.Contains(k, "mail"):
+		return asciiReplacer.Replace(strings.ToLower(d.pick(l.FirstNames)+"."+d.pick(l.LastNames))) + "@example.com"
+	case strings.Contains(k, "uuid") || strings.Contains(k, "guid"):
+		return d.fakeUUID()
+	case strings.HasSuffix(k, "url") || strings.HasSuffix(k, "uri") || strings.Contains(k, "link") || strings.Contains(k, "website") || k == "href":
+		return "https://example.com/" + d.pick(fakeWords)
+	case strings.HasSuffix(key, "_at") || strings.HasSuffix(key, "At") || strings.Contains(k, "time"):
+		return d.fakeTime().Format(time.RFC3339)
+	case strings.Contains(k, "date") || strings.Contains(k, "birthday"):
+		return d.fakeTime().Format("2006-01-02")
+	case strings.Contains(k, "firstname") || strings.Contains(k, "givenname"):
+		return d.pick(l.FirstNames)
+	case strings.Contains(k, "lastname") || strings.Contains(k, "surname") || strings.Contains(k, "familyname"):
+		return d.pick(l.LastNames)
+	case strings.Contains(k, "username") || strings.Contains(k, "login"):
+		return asciiReplacer.Replace(strings.ToLower(d.pick(l.FirstNames))) + fmt.Sprint(d.rand.Intn(100))
+	case strings.Contains(k, "name"):
+		return d.pick(l.FirstNames) + " " + d.pick(l.LastNames)
+	case strings.Contains(k, "phone") || strings.Contains(k, "mobile"):
+		return d.fakeDigits(l.Phone)
+	case strings.Contains(k, "city"):
+		return d.pick(l.Cities)
+	case strings.Contains(k, "country"):
+		return d.pick(l.Countries)
+	case strings.Contains(k, "address") || strings.Contains(k, "street"):
+		n := 1 + d.rand.Intn(200)
+		return fmt.Sprintf(l.Address, n, d.pick(l.Streets))
+	case strings.Contains(k, "zip") || strings.Contains(k, "postal"):
+		return d.fakeDigits(l.Postal)
+	case k == "ip" || strings.HasSuffix(strings.ToLower(key), "_ip") || strings.Contains(k, "ipaddr"):
+		return fmt.Sprintf("192.0.2.%d", 1+d.rand.Intn(254))
+	case k == "id" || strings.HasSuffix(k, "id"):
+		return fmt.Sprintf("%x", d.rand.Int63())
+	case strings.Contains(k, "error"):
+		return ""
+	case strings.Contains(k, "msg") || strings.Contains(k, "message") || strings.Contains(k, "description") ||
+		strings.Contains(k, "text") || strings.Contains(k, "comment"):
+		return d.pick(fakeSentences)
+	}
+	return d.pick(fakeWords)
+}
+
+func (d *JSONDoc) fakeUUID() string {
+	var b [16]byte
+	d.rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // variant 10
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// fakeInt returns a realistic integer value for the JSON key of the
+// field within its limits.
+func (d *JSONDoc) fakeInt(f fakeField) int {
+	k := normalizeKey(f.Key)
+	var v int
+	switch {
+	case k == "age":
+		v = 18 + d.rand.Intn(60)
+	case strings.Contains(k, "year"):
+		v = 2000 + d.rand.Intn(25)
+	case strings.HasSuffix(f.Key, "_at") || strings.HasSuffix(f.Key, "At") || strings.Contains(k, "timestamp"):
+		v = int(d.fakeTime().Unix())
+	case k == "id" || strings.HasSuffix(k, "id"):
+		v = 1 + d.rand.Intn(10000)
+	default:
+		v = 1 + d.rand.Intn(100)
+	}
+	return int(d.withinLimits(float64(v), f.Limits, true))
+}
+
+// fakeFloat returns a realistic floating point value for the JSON key
+// of the field within its limits.
+func (d *JSONDoc) fakeFloat(f fakeField) float64 {
+	k := normalizeKey(f.Key)
+	var v float64
+	switch {
+	case k == "lat" || k == "latitude":
+		v = float64(d.rand.Intn(18000000)-9000000) / 100000
+	case k == "lon" || k == "lng" || k == "longitude":
+		v = float64(d.rand.Intn(36000000)-18000000) / 100000
+	default:
+		v = float64(d.rand.Intn(100000)) / 100
+	}
+	return d.withinLimits(v, f.Limits, false)
+}
+
+// withinLimits returns v if it is within the limits or otherwise a
+// value (an integer if integer is true) within them.
+func (d *JSONDoc) withinLimits(v float64, l limits, integer bool) float64 {
+	if (l.Min == nil || v >= *l.Min) && (l.Max == nil || v <= *l.Max) {
+		return v
+	}
+	lo, hi := math.Inf(-1), math.Inf(1)
+	if l.Min != nil {
+		lo = *l.Min
+	}
+	if l.Max != nil {
+		hi = *l.Max
+	}
+	if integer {
+		lo, hi = math.Ceil(lo), math.Floor(hi)
+	}
+	switch {
+	case hi <= lo:
+		return lo
+	case l.Min == nil:
+		lo = hi - 100
+	case l.Max == nil:
+		hi = lo + 100
+	}
+	if integer {
+		return lo + float64(d.rand.Int63n(int64(math.Min(hi-lo, 1<<30))+1))
+	}
+	return math.Min(hi, lo+math.Round(d.rand.Float64()*(hi-lo)*100)/100)
+}
@@ -0,0 +1,101 @@
+package jsondoc
+
+import (
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+const fakeTestSource = `package api
+
+import "time"
+
+type user struct {
+	// format: uuid
+	ID string ` + "`json:\"id\"`" + `
+	// format: email
+	Contact string ` + "`json:\"contact\"`" + `
+	// format: date-time
+	Seen string ` + "`json:\"seen\"`" + `
+	// format: date
+	Born string ` + "`json:\"born\"`" + `
+	// min: 20
+	Nick string ` + "`json:\"nick\"`" + `
+	Code string ` + "`json:\"code\" validate:\"max=3\"`" + `
+	// min: 1000
+	// max: 1005
+	Score int ` + "`json:\"score\"`" + `
+	Ratio float64 ` + "`json:\"ratio\" validate:\"min=0,max=1\"`" + `
+}
+
+type event struct {
+	At time.Time ` + "`xml:\"at\"`" + `
+	// format: uuid
+	Ref string ` + "`xml:\"ref,attr\"`" + `
+}
+`
+
+func TestFakeFormatsAndLimits(t *testing.T) {
+	d := newTestDoc(t, fakeTestSource, "# API\n", Options{})
+	if err := d.execute(); err != nil {
+		t.Fatal(err)
+	}
+	for seed := int64(1); seed <= 20; seed++ {
+		d.rand = rand.New(rand.NewSource(seed))
+		v, err := d.sampleByName("user")
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := make(map[string]interface{})
+		for _, f := range v.(object) {
+			m[f.Key] = f.Value
+		}
+		if s := m["id"].(string); !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(s) {
+			t.Errorf("id = %q, want a UUID", s)
+		}
+		if s := m["contact"].(string); !strings.Contains(s, "@") {
+			t.Errorf("contact = %q, want an email address", s)
+		}
+		if _, err := time.Parse(time.RFC3339, m["seen"].(string)); err != nil {
+			t.Errorf("seen: %v", err)
+		}
+		if _, err := time.Parse("2006-01-02", m["born"].(string)); err != nil {
+			t.Errorf("born: %v", err)
+		}
+		if s := m["nick"].(string); len(s) < 20 {
+			t.Errorf("nick = %q, want at least 20 characters", s)
+		}
+		if s := m["code"].(string); len(s) > 3 {
+			t.Errorf("code = %q, want at most 3 characters", s)
+		}
+		if n := m["score"].(int); n < 1000 || n > 1005 {
+			t.Errorf("score = %d, want 1000–1005", n)
+		}
+		if x := m["ratio"].(float64); x < 0 || x > 1 {
+			t.Errorf("ratio = %v, want 0–1", x)
+		}
+	}
+}
+
+func TestFakeXMLTime(t *testing.T) {
+	d := newTestDoc(t, fakeTestSource, "# API\n", Options{})
+	if err := d.execute(); err != nil {
+		t.Fatal(err)
+	}
+	s, err := d.sampleXML("event")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := regexp.MustCompile(`<at>([^<]*)</at>`).FindStringSubmatch(s)
+	if m == nil {
+		t.Fatalf("no at element in\n%s", s)
+	}
+	if _, err := time.Parse(time.RFC3339, m[1]); err != nil {
+		t.Errorf("at: %v\n%s", err, s)
+	}
+	if !regexp.MustCompile(`ref="[0-9a-f]{8}-`).MatchString(s) {
+		t.Errorf("ref is not a UUID in\n%s", s)
+	}
+}
@@ -0,0 +1,317 @@
+package jsondoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"html"
+	"reflect"
+	"strconv"
+)
+
+// Content types of form inputs.
+const (
+	multipartContentType  = "multipart/form-data"
+	urlEncodedContentType = "application/x-www-form-urlencoded"
+)
+
+// formPart is a part of a multipart form (or a field of an URL encoded
+// form) described by a struct field.
+type formPart struct {
+	Name        string
+	File        bool // a file upload (*multipart.FileHeader)
+	Multiple    bool // many files with the same name ([]*multipart.FileHeader)
+	Optional    bool
+	ContentType string
+	Description string
+
+	typ  ast.Expr // type of the field (of a text part)
+	c    *context
+	fake fakeField
+}
+
+// inputMultipart documents the input of type name (a struct) sent as
+// a multipart form: its fields are the parts of the form named with
+// the form (or schema) tag. Fields of type *multipart.FileHeader (or a
+// slice of them) are file uploads, other fields are text parts. The
+// content type of a part may be given with the contentType tag.
+func (d *JSONDoc) inputMultipart(name string, level ...int) (string, error) {
+	return d.renderForm(name, multipartContentType, level)
+}
+
+// inputForm documents the input of type name (a struct) sent as an
+// URL encoded form: its fields are the fields of the form named with
+// the form (or schema) tag.
+func (d *JSONDoc) inputForm(name string, level ...int) (string, error) {
+	return d.renderForm(name, urlEncodedContentType, level)
+}
+
+// renderForm renders the input section of the form of type name sent
+// with the given content type (and the heading of the given level, if
+// any).
+func (d *JSONDoc) renderForm(name, contentType string, level []int) (string, error) {
+	d.b.Reset()
+	e := d.currentEndpoint()
+	l, err := d.sectionLevel(e, level)
+	if err != nil {
+		return "", fmt.Errorf("form %s: %v", name, err)
+	}
+	id := d.sectionID(e, "input", name)
+	if e != nil {
+		e.InputContentType = contentType
+	}
+	fmt.Fprintf(&d.b, "%s Input (%s, %s) {#%s}\n<div>\n", heading(l), markdownEscapeString(name), contentType, id)
+	t, c, err := d.lookupTypeName(name)
+	if err != nil {
+		return "", err
+	}
+	parts, err := d.formParts(t, c)
+	if err != nil {
+		return "", err
+	}
+	type row struct {
+		Name, Type, ContentType, Description string
+	}
+	v := struct {
+		Multipart bool
+		Rows      []row
+	}{Multipart: contentType == multipartContentType}
+	for _, p := range parts {
+		if p.File && !v.Multipart {
+			return "", fmt.Errorf("form %s: file field %s requires a multipart form", name, p.Name)
+		}
+		r := row{Name: html.EscapeString(strconv.Quote(p.Name)), ContentType: html.EscapeString(p.ContentType),
+			Description: d.linkTerms(html.EscapeString(p.Description))}
+		if p.Optional {
+			r.Name += " (optional)"
+		}
+		switch {
+		case p.Multiple:
+			r.Type = "files"
+		case p.File:
+			r.Type = "file"
+		default:
+			r.Type = d.typeLink(p.typ, p.c, p.Name, "")
+		}
+		v.Rows = append(v.Rows, r)
+	}
+	if len(v.Rows) > 0 {
+		if err := d.table.ExecuteTemplate(&d.b, "form", v); err != nil {
+			return "", err
+		}
+	} else if v.Multipart {
+		d.b.WriteString("<p>Multipart form with no parts.</p>\n")
+	} else {
+		d.b.WriteString("<p>URL encoded form with no fields.</p>\n")
+	}
+	if err := d.renderQueued(); err != nil {
+		return "", err
+	}
+	d.b.WriteString("</div>\n")
+	return d.b.String(), nil
+}
+
+// formParts returns the parts of the form described by the struct type
+// t (including the fields of embedded structs).
+func (d *JSONDoc) formParts(t *ast.TypeSpec, c *context) ([]formPart, error) {
+	st, ok := t.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("form type %s is not a struct", t.Name.Name)
+	}
+	return d.appendFormParts(nil, st, c)
+}
+
+func (d *JSONDoc) appendFormParts(parts []formPart, t *ast.StructType, c *context) ([]formPart, error) {
+	for _, f := range t.Fields.List {
+		if len(f.Names) == 0 {
+			typ := f.Type
+			if s, ok := typ.(*ast.StarExpr); ok {
+				typ = s.X
+			}
+			ts, c, err := d.lookupType(typ, c)
+			if err != nil {
+				return nil, err
+			}
+			if ts == nil {
+				continue
+			}
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				if parts, err = d.appendFormParts(parts, st, c); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		for _, ident := range f.Names {
+			name, omitempty, err := formKey(ident.Name, f.Tag)
+			if err == NotExported {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			p := formPart{Name: name, Optional: omitempty, Description: parseFieldComment(f).Description, typ: f.Type, c: c, fake: fieldFake(name, f)}
+			typ := f.Type
+			if a, ok := typ.(*ast.ArrayType); ok && d.isFileHeader(a.Elt, c) {
+				p.File, p.Multiple = true, true
+			} else if d.isFileHeader(typ, c) {
+				p.File = true
+			}
+			p.ContentType, err = partContentType(f.Tag)
+			if err != nil {
+				return nil, err
+			}
+			if p.ContentType == "" {
+				p.ContentType = d.defaultPartContentType(p, c)
+			}
+			parts = append(parts, p)
+		}
+	}
+	return parts, nil
+}
+
+// formKey returns the name of the form field of the struct field with
+// the given name and tag (taken from the form tag or, if not present,
+// from the schema tag used by github.com/gorilla/schema).
+func formKey(name string, tag *ast.BasicLit) (key string, omitempty bool, err error) {
+	if tag != nil {
+		s, err := strconv.Unquote(tag.Value)
+		if err != nil {
+			return "", false, err
+		}
+		if _, ok := reflect.StructTag(s).Lookup("form"); !ok {
+			return tagKey(name, tag, "schema")
+		}
+	}
+	return tagKey(name, tag, "form")
+}
+
+// isFileHeader reports whether t is (a pointer to) multipart.FileHeader
+// or multipart.File.
+func (d *JSONDoc) isFileHeader(t ast.Expr, c *context) bool {
+	if s, ok := t.(*ast.StarExpr); ok {
+		t = s.X
+	}
+	sel, ok := t.(*ast.SelectorExpr)
+	if !ok || (sel.Sel.Name != "FileHeader" && sel.Sel.Name != "File") {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	path, err := d.findImportIdent(c.File, ident.Name)
+	return err == nil && path == "mime/multipart"
+}
+
+// partContentType returns the content type of the part given with the
+// contentType tag (if any).
+func partContentType(tag *ast.BasicLit) (string, error) {
+	if tag == nil {
+		return "", nil
+	}
+	s, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return "", err
+	}
+	return reflect.StructTag(s).Get("contentType"), nil
+}
+
+// defaultPartContentType returns the content type of the part p not
+// given explicitly: application/octet-stream for files, text/plain for
+// strings, numbers and booleans and application/json for other types.
+func (d *JSONDoc) defaultPartContentType(p formPart, c *context) string {
+	if p.File {
+		return "application/octet-stream"
+	}
+	if d.isTextValue(p.typ, c) {
+		return "text/plain"
+	}
+	return "application/json"
+}
+
+// isTextValue reports whether values of type t are sent as plain text
+// (strings, numbers and booleans).
+func (d *JSONDoc) isTextValue(t ast.Expr, c *context) bool {
+	if s, ok := t.(*ast.StarExpr); ok {
+		t = s.X
+	}
+	switch t := t.(type) {
+	case *ast.Ident:
+		if builtin[t.Name] {
+			return t.Name != "error"
+		}
+	case *ast.SelectorExpr:
+	default:
+		return false
+	}
+	ts, c, err := d.lookupType(t, c)
+	if err != nil || ts == nil {
+		return false
+	}
+	return d.isTextValue(ts.Type, c)
+}
+
+// formSample returns the parts of a sample form input of the endpoint.
+// Values of text parts are the sample values (as text or JSON) and
+// values of file parts are the names of sample files.
+func (d *JSONDoc) formSample(e *endpoint) ([]member, error) {
+	t, c, err := d.lookupTypeName(e.Input)
+	if err != nil {
+		return nil, err
+	}
+	parts, err := d.formParts(t, c)
+	if err != nil {
+		return nil, err
+	}
+	var form []member
+	for _, p := range parts {
+		if p.File {
+			form = append(form, member{p.Name, sampleFile{p.Name + fileExtension(p.ContentType), p.ContentType}})
+			continue
+		}
+		form = append(form, member{p.Name, textValue(d.sample(p.typ, p.c, p.fake, make(map[*ast.TypeSpec]bool)))})
+	}
+	return form, nil
+}
+
+// sampleFile is a file uploaded in a sample form.
+type sampleFile struct {
+	Name, ContentType string
+}
+
+// fileExtension returns the file name extension typical for the
+// content type (if known).
+func fileExtension(contentType string) string {
+	switch contentType {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/gif":
+		return ".gif"
+	case "application/pdf":
+		return ".pdf"
+	case "text/plain":
+		return ".txt"
+	case "text/csv":
+		return ".csv"
+	case "application/json":
+		return ".json"
+	case "application/zip":
+		return ".zip"
+	}
+	return ".bin"
+}
+
+// textValue returns the sample value v as sent in a text part (strings
+// as they are, other values as JSON).
+func textValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
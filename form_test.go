@@ -0,0 +1,133 @@
+package jsondoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const formSrc = `package api
+
+import "mime/multipart"
+
+type Base struct {
+	Tag string ` + "`form:\"tag\"`" + `
+}
+
+type Meta struct {
+	Width int ` + "`json:\"width\"`" + `
+}
+
+type Upload struct {
+	Base
+	Avatar  *multipart.FileHeader   ` + "`form:\"avatar\" contentType:\"image/png\"`" + ` // the picture
+	Docs    []*multipart.FileHeader ` + "`form:\"docs\"`" + `
+	Title   string                  ` + "`form:\"title\"`" + `
+	Count   int                     ` + "`schema:\"count,omitempty\"`" + `
+	Meta    Meta                    ` + "`form:\"meta\"`" + `
+	Skipped string                  ` + "`form:\"-\"`" + `
+}
+`
+
+func TestFormParts(t *testing.T) {
+	d := newTestDoc(t, formSrc, "# API\n", Options{})
+	if err := d.execute(); err != nil {
+		t.Fatal(err)
+	}
+	ts, c, err := d.lookupTypeName("Upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts, err := d.formParts(ts, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []formPart
+	for _, p := range parts {
+		got = append(got, formPart{Name: p.Name, File: p.File, Multiple: p.Multiple, Optional: p.Optional, ContentType: p.ContentType, Description: p.Description})
+	}
+	want := []formPart{
+		{Name: "tag", ContentType: "text/plain"},
+		{Name: "avatar", File: true, ContentType: "image/png", Description: "the picture"},
+		{Name: "docs", File: true, Multiple: true, ContentType: "application/octet-stream"},
+		{Name: "title", ContentType: "text/plain"},
+		{Name: "count", Optional: true, ContentType: "text/plain"},
+		{Name: "meta", ContentType: "application/json"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got parts\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestInputMultipart(t *testing.T) {
+	md := renderMarkdown(t, formSrc, "{{endpoint \"POST\" \"/upload\"}}\n\n{{inputMultipart \"Upload\"}}\n\n{{snippets}}\n")
+	for _, s := range []string{
+		"### Input (Upload, multipart/form-data) {#endpoint-post-upload-input}",
+		"<td>&#34;avatar&#34;</td>\n<td>file</td>\n<td>image/png</td>\n<td>the picture</td>",
+		"<td>&#34;docs&#34;</td>\n<td>files</td>",
+		"<td>&#34;count&#34; (optional)</td>",
+		"-F &#39;avatar=@avatar.png;type=image/png&#39;",
+		"-F &#39;docs=@docs.bin;type=application/octet-stream&#39;",
+	} {
+		if !strings.Contains(md, s) {
+			t.Errorf("%q not found in\n%s", s, md)
+		}
+	}
+	if strings.Contains(md, "Skipped") || strings.Contains(md, "skipped") {
+		t.Errorf("the skipped field is documented in\n%s", md)
+	}
+}
+
+const loginSrc = `package api
+
+import "mime/multipart"
+
+type Login struct {
+	User     string ` + "`form:\"user\"`" + ` // the user name
+	Password string ` + "`schema:\"password\"`" + `
+	Remember bool   ` + "`form:\"remember,omitempty\"`" + `
+}
+
+type Avatar struct {
+	File *multipart.FileHeader ` + "`form:\"file\"`" + `
+}
+`
+
+func TestInputForm(t *testing.T) {
+	tmpl := "{{endpoint \"POST\" \"/login\"}}\n\n{{inputForm \"Login\"}}\n\n{{snippets}}\n"
+	md := renderMarkdown(t, loginSrc, tmpl)
+	for _, s := range []string{
+		"### Input (Login, application/x-www-form-urlencoded) {#endpoint-post-login-input}",
+		"<p>URL encoded form with the following fields:</p>",
+		"<td>&#34;user&#34;</td>\n<td>string</td>\n<td>the user name</td>",
+		"<td>&#34;password&#34;</td>",
+		"<td>&#34;remember&#34; (optional)</td>\n<td>bool</td>",
+		"--data-urlencode &#39;remember=true&#39;",
+	} {
+		if !strings.Contains(md, s) {
+			t.Errorf("%q not found in\n%s", s, md)
+		}
+	}
+	d := newTestDoc(t, loginSrc, tmpl, Options{})
+	var b bytes.Buffer
+	if err := d.WriteOpenAPI(&b); err != nil {
+		t.Fatal(err)
+	}
+	var spec map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &spec); err != nil {
+		t.Fatal(err)
+	}
+	schema := mapAt(spec, "paths", "/login", "post", "requestBody", "content", urlEncodedContentType, "schema")
+	if len(mapAt(schema, "properties")) != 3 || !reflect.DeepEqual(schema["required"], []interface{}{"user", "password"}) {
+		t.Errorf("got schema %v of the form", schema)
+	}
+}
+
+func TestInputFormFile(t *testing.T) {
+	d := newTestDoc(t, loginSrc, "{{endpoint \"POST\" \"/avatar\"}}\n\n{{inputForm \"Avatar\"}}\n", Options{})
+	if err := d.execute(); err == nil || !strings.Contains(err.Error(), "file field file requires a multipart form") {
+		t.Errorf("got error %v, want one of the file field", err)
+	}
+}
@@ -0,0 +1,100 @@
+package jsondoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// term defines a glossary term (it may be used before the glossary
+// action). Occurrences of the term in descriptions of fields rendered
+// after it link to its definition.
+func (d *JSONDoc) term(name, definition string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("empty glossary term")
+	}
+	if _, ok := d.glossary[name]; !ok {
+		d.terms = append(d.terms, name)
+	}
+	d.glossary[name] = definition
+	d.termsRe = nil
+	return "", nil
+}
+
+// glossaryFile defines glossary terms read from the JSON file (an
+// object mapping terms to their definitions) with the path relative to
+// the template.
+func (d *JSONDoc) glossaryFile(filename string) (string, error) {
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(d.dir, filename)
+	}
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	var m map[string]string
+	if err := json.Unmarshal(b, &m); err != nil {
+		return "", fmt.Errorf("glossary %s: %v", filename, err)
+	}
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := d.term(name, m[name]); err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+func termID(name string) string {
+	return "term-" + idFromString(name)
+}
+
+// glossaryTable returns the "Glossary" chapter with a table of all
+// the defined terms (sorted alphabetically).
+func (d *JSONDoc) glossaryTable() string {
+	terms := append([]string(nil), d.terms...)
+	sort.Strings(terms)
+	var b bytes.Buffer
+	b.WriteString(heading(d.headingLevel()) + " Glossary {#glossary}\n\n<div>\n<table>\n<caption>Glossary terms</caption>\n<tr>\n<th scope=\"col\">Term</th>\n<th scope=\"col\">Definition</th>\n</tr>\n")
+	for _, t := range terms {
+		fmt.Fprintf(&b, "<tr id=\"%s\">\n<td>%s</td>\n<td>%s</td>\n</tr>\n", termID(t), html.EscapeString(t), html.EscapeString(d.glossary[t]))
+	}
+	b.WriteString("</table>\n</div>\n")
+	return b.String()
+}
+
+// linkTerms returns the (HTML escaped) description s with the
+// occurrences of the glossary terms linked to their definitions.
+func (d *JSONDoc) linkTerms(s string) string {
+	if len(d.terms) == 0 {
+		return s
+	}
+	if d.termsRe == nil {
+		terms := append([]string(nil), d.terms...)
+		// prefer longer terms if one is a prefix of another
+		sort.Slice(terms, func(i, j int) bool { return len(terms[i]) > len(terms[j]) })
+		var b bytes.Buffer
+		b.WriteString(`\b(`)
+		for i, t := range terms {
+			if i > 0 {
+				b.WriteByte('|')
+			}
+			b.WriteString(regexp.QuoteMeta(html.EscapeString(t)))
+		}
+		b.WriteString(`)\b`)
+		d.termsRe = regexp.MustCompile(b.String())
+	}
+	return d.termsRe.ReplaceAllStringFunc(s, func(t string) string {
+		name := html.UnescapeString(t)
+		return fmt.Sprintf(`<a class="term" href="#%s" title="%s">%s</a>`, termID(name), html.EscapeString(d.glossary[name]), t)
+	})
+}
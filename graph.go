@@ -0,0 +1,125 @@
+package jsondoc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// addEdge records that the section (or the type) with id from refers
+// to the section (or the type) with id to.
+func (d *JSONDoc) addEdge(from, to string) {
+	if from == "" || to == "" {
+		return
+	}
+	if d.graph == nil {
+		d.graph = make(map[string][]string)
+	}
+	for _, s := range d.graph[from] {
+		if s == to {
+			return
+		}
+	}
+	d.graph[from] = append(d.graph[from], to)
+}
+
+// WriteGraph executes the template (without rendering the
+// documentation) and writes the resolved dependency graph as a tree:
+// the endpoints with their sections and the types they refer to
+// followed by the sections outside of endpoints. Types already shown
+// are marked "(see above)" instead of being expanded again.
+func (d *JSONDoc) WriteGraph(w io.Writer) error {
+	c, err := d.begin()
+	if err != nil {
+		return err
+	}
+	defer d.end(c)
+	return c.writeGraph(w)
+}
+
+func (d *JSONDoc) writeGraph(w io.Writer) error {
+	anchors := make(map[string]anchor)
+	referenced := make(map[string]bool)
+	for _, a := range d.anchors {
+		anchors[a.ID] = a
+		for _, to := range d.graph[a.ID] {
+			referenced[to] = true
+		}
+	}
+	bw := bufio.NewWriter(w)
+	shown := make(map[string]bool)
+	var write func(id string, depth int)
+	write = func(id string, depth int) {
+		fmt.Fprintf(bw, "%s%s (#%s)", strings.Repeat("    ", depth), graphLabel(anchors[id]), id)
+		if shown[id] && len(d.graph[id]) > 0 {
+			bw.WriteString(" (see above)\n")
+			return
+		}
+		bw.WriteString("\n")
+		shown[id] = true
+		for _, to := range d.graph[id] {
+			write(to, depth+1)
+		}
+	}
+	for _, a := range d.anchors {
+		if a.Kind == "endpoint" || !referenced[a.ID] && !shown[a.ID] {
+			write(a.ID, 0)
+		}
+	}
+	return bw.Flush()
+}
+
+// graphLabel returns the label of the node of the dependency graph.
+func graphLabel(a anchor) string {
+	switch a.Kind {
+	case "endpoint", "type", "":
+		return a.Title
+	}
+	title := map[string]string{"input": "Input", "output": "Output", "errors": "Errors"}[a.Kind]
+	if a.Type == "" {
+		return title
+	}
+	return title + " " + typeIdent(a.Type)
+}
+
+// WriteDOT writes the dependency graph of the documented types (with
+// the endpoints and their sections referring to them) in the DOT
+// language of Graphviz.
+func (d *JSONDoc) WriteDOT(w io.Writer) error {
+	c, err := d.begin()
+	if err != nil {
+		return err
+	}
+	defer d.end(c)
+	return c.writeDOT(w)
+}
+
+func (d *JSONDoc) writeDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph jsondoc {\n\trankdir=LR;\n\tnode [fontname=\"sans-serif\", fontsize=10];\n")
+	for _, a := range d.anchors {
+		shape := "box, style=rounded"
+		switch a.Kind {
+		case "endpoint":
+			shape = "box, style=bold"
+		case "type":
+			shape = "ellipse"
+		}
+		fmt.Fprintf(bw, "\t%s [label=%s, shape=%s];\n", dotQuote(a.ID), dotQuote(graphLabel(a)), shape)
+	}
+	for _, a := range d.anchors {
+		for _, to := range d.graph[a.ID] {
+			fmt.Fprintf(bw, "\t%s -> %s;\n", dotQuote(a.ID), dotQuote(to))
+		}
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+var dotReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// dotQuote returns s as a quoted DOT identifier.
+func dotQuote(s string) string {
+	return `"` + dotReplacer.Replace(s) + `"`
+}
@@ -0,0 +1,75 @@
+package jsondoc
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+)
+
+// HandlerConfig configures the documentation served by Handler.
+type HandlerConfig struct {
+	Template string // file name of the markdown template
+	Options
+
+	// Dev regenerates the documentation on every request so that
+	// changes of the template and of the documented types are shown
+	// after reloading the page (errors are then shown in the
+	// response instead of being returned by Handler).
+	Dev bool
+}
+
+// Handler returns an HTTP handler serving the documentation generated
+// from the template (for example to be mounted at /docs by the
+// documented API service itself). Unless in the Dev mode the
+// documentation is generated once by Handler.
+func Handler(c HandlerConfig) (http.Handler, error) {
+	h := &docHandler{config: c}
+	if !c.Dev {
+		var err error
+		if h.page, err = c.generate(); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+type docHandler struct {
+	config HandlerConfig
+	mu     sync.Mutex // serializes generation in the Dev mode
+	page   []byte
+}
+
+func (h *docHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	page := h.page
+	if h.config.Dev {
+		h.mu.Lock()
+		var err error
+		page, err = h.config.generate()
+		h.mu.Unlock()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(page)
+}
+
+// generate returns the documentation (parsing the template and the
+// documented packages anew).
+func (c HandlerConfig) generate() ([]byte, error) {
+	d, err := New(c.Template, c.Options)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if _, err := d.WriteTo(&b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
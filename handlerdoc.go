@@ -0,0 +1,380 @@
+package jsondoc
+
+import (
+	"fmt"
+	"go/ast"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// handlerTypes are the input and output types of a handler function
+// (as given to the input and output actions), the container of the
+// output values ("array" or "object" for slices and maps, see
+// outputList and outputMap) and the envelope type of the output (if
+// any, given with -swag).
+type handlerTypes struct {
+	Input, Output string
+	Container     string
+	Envelope      string
+}
+
+// handler renders the input and output sections of the handler
+// function (or method) given by name (such as "api.GetItem" or
+// "api.Server.GetItem") with the types found, in this order, in the
+// "input: T" and "output: T" lines of its doc comment, in its
+// annotations of swag (with -swag, see parseSwag), in its signature
+// (such as func(ctx context.Context, in *T) (*U, error)) or decoded
+// and encoded in its body (with the Decode, Unmarshal, Encode and
+// Marshal calls). Unless it follows the endpoint action the sections
+// are preceded by the header of the endpoint given in the doc comment
+// (see handlerRoute).
+func (d *JSONDoc) handler(name string, level ...int) (string, error) {
+	fn, c, err := d.lookupFunc(name)
+	if err != nil {
+		return "", fmt.Errorf("handler %s: %v", name, err)
+	}
+	var h handlerTypes
+	var sw swagAnnotations
+	d.handlerDirectives(fn, &h)
+	if d.swag {
+		sw = d.parseSwag(fn, c, &h)
+	}
+	d.handlerSignature(fn, c, &h)
+	d.handlerBody(fn, c, &h)
+	if h.Input == "" && h.Output == "" && sw.empty() {
+		return "", fmt.Errorf("handler %s: no input or output types found (the types of the packages imported in the template may be given with the \"input: T\" and \"output: T\" lines of its doc comment)", name)
+	}
+	var parts []string
+	if e := d.currentEndpoint(); e == nil || e.handler {
+		method, path, ok := sw.Method, sw.Path, sw.Method != ""
+		if !ok {
+			method, path, ok = d.handlerRoute(fn)
+		}
+		if ok {
+			s, err := d.endpoint(method, path)
+			if err != nil {
+				return "", fmt.Errorf("handler %s: %v", name, err)
+			}
+			d.endpoints[len(d.endpoints)-1].handler = true
+			parts = append(parts, s)
+		}
+	}
+	if s := sw.render(); s != "" {
+		parts = append(parts, s)
+	}
+	if h.Input != "" {
+		s, err := d.input(h.Input, level...)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, s)
+	}
+	if h.Output != "" {
+		var s string
+		switch {
+		case h.Envelope != "":
+			s, err = d.renderOutput(h.Output, h.Envelope, h.Container, level)
+		case h.Container == "array":
+			s, err = d.outputList(h.Output, level...)
+		case h.Container == "object":
+			s, err = d.outputMap(h.Output, level...)
+		default:
+			s, err = d.output(h.Output, level...)
+		}
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, s)
+	}
+	if d.currentEndpoint() != nil {
+		for _, e := range sw.Errors {
+			s, err := d.stdError(e.Type, e.Codes...)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
+// defaultHandlerRe matches the doc comments of handlers such as
+// "GetItem handles GET /items/{id}" (see Config.HandlerPattern).
+var defaultHandlerRe = regexp.MustCompile(`(?m)^\S+ handles (?P<method>[A-Z]+) (?P<path>/\S*)`)
+
+// handlerRoute returns the method and the path of the endpoint given
+// in the doc comment of the handler (matched with Config.HandlerPattern).
+func (d *JSONDoc) handlerRoute(fn *ast.FuncDecl) (method, path string, ok bool) {
+	re := d.config.handlerRe
+	if re == nil {
+		re = defaultHandlerRe
+	}
+	m := re.FindStringSubmatch(fn.Doc.Text())
+	if m == nil {
+		return "", "", false
+	}
+	method, path = m[1], m[2]
+	if i, j := re.SubexpIndex("method"), re.SubexpIndex("path"); i != -1 && j != -1 {
+		method, path = m[i], m[j]
+	}
+	path = strings.TrimRight(path, ".,;")
+	return method, path, method != "" && path != ""
+}
+
+// lookupFunc returns the declaration of the function (or the method
+// given as the receiver type and the method names) with the given name
+// (qualified as the names of the types in the input and output actions).
+func (d *JSONDoc) lookupFunc(name string) (*ast.FuncDecl, *context, error) {
+	pkgName, recv := ".", ""
+	parts := strings.Split(name, ".")
+	switch {
+	case len(parts) == 3:
+		pkgName, recv = parts[0], parts[1]
+	case len(parts) == 2 && d.imports[parts[0]] != "":
+		pkgName = parts[0]
+	case len(parts) == 2:
+		recv = parts[0]
+	case len(parts) != 1:
+		return nil, nil, fmt.Errorf("expected function or method name")
+	}
+	fname := parts[len(parts)-1]
+	path, err := d.importedPath(pkgName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if path == "" {
+		return nil, nil, fmt.Errorf("name %s must be imported to access %s", pkgName, name)
+	}
+	pkg := d.loadedPackage(path)
+	for _, f := range pkg.Files {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if ok && fn.Name.Name == fname && receiverName(fn) == recv {
+				return fn, &context{path, pkg, f}, nil
+			}
+		}
+	}
+	if recv != "" {
+		return nil, nil, fmt.Errorf("method %s of type %s not found in package %s", fname, recv, path)
+	}
+	return nil, nil, fmt.Errorf("function %s not found in package %s", fname, path)
+}
+
+// receiverName returns the name of the receiver type of the method (""
+// for functions).
+func receiverName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	t := fn.Recv.List[0].Type
+	if s, ok := t.(*ast.StarExpr); ok {
+		t = s.X
+	}
+	switch x := t.(type) {
+	case *ast.IndexExpr:
+		t = x.X
+	case *ast.IndexListExpr:
+		t = x.X
+	}
+	if ident, ok := t.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// handlerDirectives sets the types given with the "input: T" and
+// "output: T" lines of the doc comment of the handler.
+func (d *JSONDoc) handlerDirectives(fn *ast.FuncDecl, h *handlerTypes) {
+	for _, line := range strings.Split(fn.Doc.Text(), "\n") {
+		m := directiveRe.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		switch m[1] {
+		case "input":
+			h.Input = strings.TrimSpace(m[2])
+		case "output":
+			h.Output = strings.TrimSpace(m[2])
+		}
+	}
+}
+
+// handlerSignature sets the types not yet found to the type of the
+// last parameter and of the first result (other than error) of the
+// handler which are types of the documented packages (so that the
+// parameters such as context.Context and *http.Request are skipped).
+func (d *JSONDoc) handlerSignature(fn *ast.FuncDecl, c *context, h *handlerTypes) {
+	if h.Input == "" && fn.Type.Params != nil {
+		params := fn.Type.Params.List
+		for i := len(params) - 1; i >= 0 && h.Input == ""; i-- {
+			if name, container := d.handlerType(params[i].Type, c); container == "" {
+				h.Input = name
+			}
+		}
+	}
+	if h.Output == "" && fn.Type.Results != nil {
+		for _, r := range fn.Type.Results.List {
+			if name, container := d.handlerType(r.Type, c); name != "" {
+				h.Output, h.Container = name, container
+				break
+			}
+		}
+	}
+}
+
+// handlerBody sets the types not yet found to the types of the values
+// decoded (with Decode or Unmarshal) and encoded (with Encode, Marshal
+// or MarshalIndent) first in the body of the handler. The types of the
+// variables are known from their declarations (such as var in T, in :=
+// T{} or in := new(T)) ignoring scopes.
+func (d *JSONDoc) handlerBody(fn *ast.FuncDecl, c *context, h *handlerTypes) {
+	if fn.Body == nil {
+		return
+	}
+	vars := make(map[string]ast.Expr)
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ValueSpec:
+			for i, ident := range n.Names {
+				if n.Type != nil {
+					vars[ident.Name] = n.Type
+				} else if len(n.Values) == len(n.Names) {
+					vars[ident.Name] = exprType(n.Values[i], vars)
+				}
+			}
+		case *ast.AssignStmt:
+			if len(n.Lhs) != len(n.Rhs) {
+				break
+			}
+			for i, lhs := range n.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && vars[ident.Name] == nil {
+					vars[ident.Name] = exprType(n.Rhs[i], vars)
+				}
+			}
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok {
+				break
+			}
+			var arg ast.Expr
+			input := false
+			switch {
+			case sel.Sel.Name == "Decode" && len(n.Args) == 1:
+				arg, input = n.Args[0], true
+			case sel.Sel.Name == "Unmarshal" && len(n.Args) == 2:
+				arg, input = n.Args[1], true
+			case sel.Sel.Name == "Encode" && len(n.Args) == 1,
+				sel.Sel.Name == "Marshal" && len(n.Args) == 1,
+				sel.Sel.Name == "MarshalIndent" && len(n.Args) == 3:
+				arg = n.Args[0]
+			default:
+				return true
+			}
+			t := exprType(arg, vars)
+			if t == nil {
+				break
+			}
+			name, container := d.handlerType(t, c)
+			switch {
+			case name == "":
+			case input && h.Input == "" && container == "":
+				h.Input = name
+			case !input && h.Output == "":
+				h.Output, h.Container = name, container
+			}
+		}
+		return true
+	})
+}
+
+// exprType returns the type of the value of the expression (the value
+// pointed to for pointers) if it is apparent from the expression or
+// from the declarations of the variables (nil otherwise).
+func exprType(e ast.Expr, vars map[string]ast.Expr) ast.Expr {
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return exprType(e.X, vars)
+	case *ast.UnaryExpr:
+		return exprType(e.X, vars)
+	case *ast.StarExpr:
+		return exprType(e.X, vars)
+	case *ast.CompositeLit:
+		return e.Type
+	case *ast.CallExpr:
+		if ident, ok := e.Fun.(*ast.Ident); ok && ident.Name == "new" && len(e.Args) == 1 {
+			return e.Args[0]
+		}
+	case *ast.Ident:
+		return vars[e.Name]
+	}
+	return nil
+}
+
+// handlerType returns the name (as given to the input and output
+// actions) of the type (or of the element type of a slice or a map
+// given with the container) if it is a type declared in a package
+// imported in the template (and "" for other types).
+func (d *JSONDoc) handlerType(t ast.Expr, c *context) (name, container string) {
+	switch x := t.(type) {
+	case *ast.StarExpr:
+		return d.handlerType(x.X, c)
+	case *ast.ArrayType:
+		if name, container = d.handlerType(x.Elt, c); name == "" || container != "" || x.Len != nil {
+			return "", ""
+		}
+		return name, "array"
+	case *ast.MapType:
+		if name, container = d.handlerType(x.Value, c); name == "" || container != "" {
+			return "", ""
+		}
+		return name, "object"
+	case *ast.Ident:
+		return d.templateTypeName(x.Name, c.Path), ""
+	case *ast.SelectorExpr:
+		ident, ok := x.X.(*ast.Ident)
+		if !ok || d.stdType(x, c) {
+			return "", ""
+		}
+		path, err := d.findImportIdent(c.File, ident.Name)
+		if err != nil || path == "C" {
+			return "", ""
+		}
+		return d.templateTypeName(x.Sel.Name, path), ""
+	}
+	return "", ""
+}
+
+// templateTypeName returns the name of the type declared in the
+// package with the given import path qualified with a name the package
+// is imported with in the template (not qualified for the package
+// imported only as "."). It returns "" if the package is not imported
+// or does not declare the type.
+func (d *JSONDoc) templateTypeName(name, path string) string {
+	pkg := d.loadedPackage(path)
+	if pkg == nil {
+		return ""
+	}
+	declared := false
+	for _, f := range pkg.Files {
+		if o := f.Scope.Objects[name]; o != nil && o.Kind == ast.Typ {
+			declared = true
+		}
+	}
+	var names []string
+	for n, p := range d.imports {
+		if p == path && n != "." {
+			names = append(names, n)
+		}
+	}
+	switch {
+	case !declared:
+		return ""
+	case len(names) > 0:
+		sort.Strings(names)
+		return names[0] + "." + name
+	case d.imports["."] == path:
+		return name
+	}
+	return ""
+}
@@ -0,0 +1,172 @@
+package jsondoc
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const handlerSrc = `package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+type Item struct {
+	ID int ` + "`json:\"id\"`" + `
+}
+
+type Filter struct {
+	Name string ` + "`json:\"name\"`" + `
+}
+
+type Server struct{}
+
+// CreateItem creates an item.
+func CreateItem(ctx context.Context, in *Item) (*Item, error) { return in, nil }
+
+// ListItems lists the items matching the filter.
+func ListItems(w http.ResponseWriter, r *http.Request) {
+	var f Filter
+	if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+		return
+	}
+	items := []Item{}
+	json.NewEncoder(w).Encode(items)
+}
+
+// ItemsByName returns the items by name.
+func (s *Server) ItemsByName(ctx context.Context) (map[string]*Item, error) { return nil, nil }
+
+// Raw decodes the input itself.
+//
+// input: Filter
+// output: Item
+func Raw(w http.ResponseWriter, r *http.Request) {
+	b, _ := json.Marshal(map[string]int{})
+	w.Write(b)
+}
+
+// Ping has no documented types.
+func Ping(w http.ResponseWriter, r *http.Request) {}
+`
+
+func TestHandlerTypes(t *testing.T) {
+	d := newTestDoc(t, handlerSrc, "# API\n", Options{})
+	if err := d.execute(); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		name string
+		want handlerTypes
+	}{
+		{"CreateItem", handlerTypes{Input: "Item", Output: "Item"}},
+		{"ListItems", handlerTypes{Input: "Filter", Output: "Item", Container: "array"}},
+		{"Server.ItemsByName", handlerTypes{Output: "Item", Container: "object"}},
+		{"Raw", handlerTypes{Input: "Filter", Output: "Item"}},
+		{"Ping", handlerTypes{}},
+	} {
+		fn, ctx, err := d.lookupFunc(c.name)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		var h handlerTypes
+		d.handlerDirectives(fn, &h)
+		d.handlerSignature(fn, ctx, &h)
+		d.handlerBody(fn, ctx, &h)
+		if h != c.want {
+			t.Errorf("%s: got %+v, want %+v", c.name, h, c.want)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	md := renderMarkdown(t, handlerSrc, "{{endpoint \"GET\" \"/items\"}}\n\n{{handler \"ListItems\"}}\n")
+	for _, s := range []string{"### Input (Filter)", "### Output (array of Item)"} {
+		if !strings.Contains(md, s) {
+			t.Errorf("%q not found in\n%s", s, md)
+		}
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	for _, c := range []struct {
+		name, err string
+	}{
+		{"Ping", "no input or output types found"},
+		{"Missing", "function Missing not found"},
+		{"Server.Missing", "method Missing of type Server not found"},
+		{"a.b.c.d", "expected function or method name"},
+	} {
+		d := newTestDoc(t, handlerSrc, "{{endpoint \"GET\" \"/items\"}}\n\n{{handler \""+c.name+"\"}}\n", Options{})
+		if err := d.execute(); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: got error %v, want one containing %q", c.name, err, c.err)
+		}
+	}
+}
+
+func TestHandlerRoute(t *testing.T) {
+	for _, c := range []struct {
+		pattern, doc string
+		method, path string
+	}{
+		{"", "GetItem handles GET /items/{id}.", "GET", "/items/{id}"},
+		{"", "GetItem returns the item.\n\nGetItem handles DELETE /items/{id}, if it exists.", "DELETE", "/items/{id}"},
+		{"", "GetItem returns the item.", "", ""},
+		{"", "GetItem handles get /items.", "", ""},
+		{`@route (?P<path>\S+) \[(?P<method>[a-z]+)\]`, "GetItem returns the item.\n@route /items/{id} [get]", "get", "/items/{id}"},
+		{`(\S+) (\S+) endpoint`, "GetItem is the PUT /items endpoint.", "PUT", "/items"},
+	} {
+		src := "package api\n\n// " + strings.Replace(c.doc, "\n", "\n// ", -1) + "\nfunc GetItem() {}\n"
+		src = strings.Replace(src, "// \n", "//\n", -1)
+		d := newTestDoc(t, src, "# API\n", Options{})
+		if c.pattern != "" {
+			d.config.handlerRe = regexp.MustCompile(c.pattern)
+		}
+		if err := d.execute(); err != nil {
+			t.Fatal(err)
+		}
+		fn, _, err := d.lookupFunc("GetItem")
+		if err != nil {
+			t.Fatal(err)
+		}
+		method, path, ok := d.handlerRoute(fn)
+		if method != c.method || path != c.path || ok != (c.method != "") {
+			t.Errorf("%q: got %q %q %t, want %q %q", c.doc, method, path, ok, c.method, c.path)
+		}
+	}
+}
+
+func TestHandlerEndpoint(t *testing.T) {
+	src := handlerSrc + "\n// GetItem handles GET /items/{id}.\nfunc GetItem(ctx context.Context, id string) (*Item, error) { return nil, nil }\n"
+	md := renderMarkdown(t, src, "{{handler \"GetItem\"}}\n\n{{handler \"CreateItem\"}}\n")
+	if !strings.Contains(md, "## GET `/items/{id}` {#endpoint-get-items-id}") || !strings.Contains(md, "### Output (Item)") {
+		t.Errorf("no endpoint of the handler in\n%s", md)
+	}
+	if n := len(regexp.MustCompile(`(?m)^## `).FindAllString(md, -1)); n != 1 || !strings.Contains(md, "### Input (Item)") {
+		t.Errorf("the sections of the handler without a route are not in the last endpoint in\n%s", md)
+	}
+}
+
+func TestConfigHandlerPattern(t *testing.T) {
+	for _, c := range []struct {
+		pattern, err string
+	}{
+		{`handles (\S+)`, "must have submatches of the method and the path"},
+		{`handles ([A-Z]+`, "handler pattern"},
+	} {
+		config := filepath.Join(t.TempDir(), "config.json")
+		b, _ := json.Marshal(map[string]string{"handlerPattern": c.pattern})
+		if err := os.WriteFile(config, b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readConfig(config); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: got error %v, want one containing %q", c.pattern, err, c.err)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package jsondoc
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// The HAR (HTTP Archive 1.2) export lists the sample request and
+// response of each endpoint (as in the snippets and as served by
+// MockHandler) so that tools replaying HAR files (such as testing
+// tools of API gateways) may exercise the API.
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            int         `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string     `json:"mimeType"`
+	Text     string     `json:"text,omitempty"`
+	Params   []harParam `json:"params,omitempty"`
+}
+
+type harParam struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	FileName    string `json:"fileName,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type harTimings struct {
+	Send    int `json:"send"`
+	Wait    int `json:"wait"`
+	Receive int `json:"receive"`
+}
+
+// WriteHAR writes an HTTP Archive (HAR 1.2) with the sample request and
+// response of each documented endpoint (the endpoints with inputs and
+// outputs encoded with other codecs than JSON, such as CBOR, are
+// omitted). The requests are sent to the base URL of the snippets.
+func (d *JSONDoc) WriteHAR(w io.Writer) error {
+	c, err := d.begin()
+	if err != nil {
+		return err
+	}
+	defer d.end(c)
+	return c.writeHAR(w)
+}
+
+func (d *JSONDoc) writeHAR(w io.Writer) error {
+	t, err := generationTime()
+	if err != nil {
+		return err
+	}
+	log := harLog{Version: "1.2", Creator: harCreator{"jsondoc", jsondocVersion()}, Entries: []harEntry{}}
+	for _, e := range d.endpoints {
+		if e.codec != nil {
+			continue
+		}
+		req, err := d.harRequest(e)
+		if err != nil {
+			return err
+		}
+		r, err := d.sampleResponse(e)
+		if err != nil {
+			return err
+		}
+		resp := harResponse{Status: r.Status, StatusText: http.StatusText(r.Status), HTTPVersion: "HTTP/1.1",
+			Cookies: []harNameValue{}, Headers: []harNameValue{}, HeadersSize: -1, BodySize: len(r.Body)}
+		resp.Content = harContent{Size: len(r.Body), MimeType: r.ContentType, Text: string(r.Body)}
+		if r.ContentType != "" {
+			resp.Headers = append(resp.Headers, harNameValue{"Content-Type", r.ContentType})
+		}
+		if r.Disposition != "" {
+			resp.Headers = append(resp.Headers, harNameValue{"Content-Disposition", r.Disposition})
+		}
+		log.Entries = append(log.Entries, harEntry{
+			StartedDateTime: t.Format(time.RFC3339),
+			Request:         req,
+			Response:        resp,
+			Comment:         e.Title(),
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Log harLog `json:"log"`
+	}{log})
+}
+
+// harRequest returns the sample request of the endpoint.
+func (d *JSONDoc) harRequest(e *endpoint) (harRequest, error) {
+	r, err := d.sampleRequest(e)
+	if err != nil {
+		return harRequest{}, err
+	}
+	req := harRequest{Method: r.Method, URL: r.URL, HTTPVersion: "HTTP/1.1",
+		Cookies: []harNameValue{}, Headers: []harNameValue{}, QueryString: []harNameValue{}, HeadersSize: -1}
+	var p *harPostData
+	switch {
+	case r.Form != nil && r.Multipart:
+		p = &harPostData{MimeType: multipartContentType}
+		for _, m := range r.Form {
+			if f, ok := m.Value.(sampleFile); ok {
+				p.Params = append(p.Params, harParam{Name: m.Key, FileName: f.Name, ContentType: f.ContentType})
+			} else {
+				s, _ := m.Value.(string)
+				p.Params = append(p.Params, harParam{Name: m.Key, Value: s})
+			}
+		}
+	case r.Form != nil:
+		p = &harPostData{MimeType: urlEncodedContentType}
+		v := url.Values{}
+		for _, m := range r.Form {
+			s, _ := m.Value.(string)
+			v.Add(m.Key, s)
+			p.Params = append(p.Params, harParam{Name: m.Key, Value: s})
+		}
+		p.Text = v.Encode()
+	case r.Raw != "":
+		p = &harPostData{MimeType: r.ContentType, Text: r.Raw}
+	case r.Body != nil:
+		b, err := json.MarshalIndent(r.Body, "", "  ")
+		if err != nil {
+			return req, err
+		}
+		p = &harPostData{MimeType: "application/json", Text: string(b)}
+	}
+	if p != nil {
+		req.PostData = p
+		req.BodySize = len(p.Text)
+		req.Headers = append(req.Headers, harNameValue{"Content-Type", p.MimeType})
+		if strings.HasPrefix(p.MimeType, "multipart/") {
+			// the size of the body depends on the boundary chosen by
+			// the replaying tool
+			req.BodySize = -1
+		}
+	}
+	return req, nil
+}
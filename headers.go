@@ -0,0 +1,119 @@
+package jsondoc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"html"
+	"reflect"
+	"strconv"
+)
+
+// responseHeader is a header of the responses of an endpoint.
+type responseHeader struct {
+	Name, Type, Description string
+}
+
+// outputHeaders documents the headers of the responses of the current
+// endpoint (such as ETag, Location or the cursor of the next page) in
+// a table. They are given by the name of a struct type whose fields
+// are the headers (named with the header struct tag, such as
+// `header:"ETag"`, or by the names of the fields) or inline as the
+// name, the type and the description of each header.
+func (d *JSONDoc) outputHeaders(args ...string) (string, error) {
+	e := d.currentEndpoint()
+	if e == nil {
+		return "", errors.New("outputHeaders must follow an endpoint")
+	}
+	var headers []responseHeader
+	switch {
+	case len(args) == 1:
+		var err error
+		if headers, err = d.structHeaders(args[0]); err != nil {
+			return "", fmt.Errorf("outputHeaders %s: %v", args[0], err)
+		}
+	case len(args) > 0 && len(args)%3 == 0:
+		for i := 0; i < len(args); i += 3 {
+			headers = append(headers, responseHeader{args[i], args[i+1], args[i+2]})
+		}
+	default:
+		return "", errors.New("outputHeaders: expected a struct type name or the name, type and description of each header")
+	}
+	e.ResponseHeaders = append(e.ResponseHeaders, headers...)
+	l, err := d.sectionLevel(e, nil)
+	if err != nil {
+		return "", fmt.Errorf("outputHeaders: %v", err)
+	}
+	id := d.endpointSectionID(e, "headers")
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s Response headers {#%s}\n<div>\n<table>\n<caption>Headers of the response</caption>\n<tr>\n<th scope=\"col\">Name</th>\n<th scope=\"col\">Type</th>\n<th scope=\"col\">Description</th>\n</tr>\n", heading(l), id)
+	for _, h := range headers {
+		fmt.Fprintf(&b, "<tr>\n<td><code>%s</code></td>\n<td>%s</td>\n<td>%s</td>\n</tr>\n", html.EscapeString(h.Name), html.EscapeString(h.Type), d.linkTerms(html.EscapeString(h.Description)))
+	}
+	b.WriteString("</table>\n</div>\n")
+	return b.String(), nil
+}
+
+// structHeaders returns the headers given by the exported fields of the
+// struct type with the given name.
+func (d *JSONDoc) structHeaders(name string) ([]responseHeader, error) {
+	t, c, err := d.lookupTypeName(name)
+	if err != nil {
+		return nil, err
+	}
+	st, ok := t.Type.(*ast.StructType)
+	if !ok {
+		return nil, errors.New("expected a struct type")
+	}
+	var headers []responseHeader
+	for _, f := range st.Fields.List {
+		tag := ""
+		if f.Tag != nil {
+			s, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(s).Get("header")
+		}
+		if tag == "-" {
+			continue
+		}
+		typ := d.valueKind(f.Type, c)
+		if typ == "" {
+			typ = typeString(f.Type)
+		}
+		desc := parseFieldComment(f).Description
+		for _, ident := range f.Names {
+			if !ast.IsExported(ident.Name) {
+				continue
+			}
+			h := tag
+			if h == "" {
+				h = ident.Name
+			}
+			headers = append(headers, responseHeader{h, typ, desc})
+		}
+	}
+	return headers, nil
+}
+
+// openAPIHeaders returns the OpenAPI headers object of the response
+// headers.
+func openAPIHeaders(headers []responseHeader) object {
+	o := object{}
+	for _, h := range headers {
+		typ := h.Type
+		switch typ {
+		case "string", "integer", "number", "boolean":
+		default:
+			typ = "string"
+		}
+		v := object{{"schema", object{{"type", typ}}}}
+		if h.Description != "" {
+			v = append(object{{"description", h.Description}}, v...)
+		}
+		o = append(o, member{h.Name, v})
+	}
+	return o
+}
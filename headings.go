@@ -0,0 +1,62 @@
+package jsondoc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Levels of the generated headings: the heading of an endpoint (or of
+// a chapter such as the glossary) is followed by its input and output
+// sections one level deeper, which contain the headings of the types
+// another level deeper (h6 at most).
+const (
+	defaultHeadingLevel = 2
+	maxEndpointLevel    = 4
+	maxSectionLevel     = 5
+)
+
+// headingLevel returns the level of the headings of endpoints and
+// chapters.
+func (d *JSONDoc) headingLevel() int {
+	if d.config.HeadingLevel != 0 {
+		return d.config.HeadingLevel
+	}
+	return defaultHeadingLevel
+}
+
+// heading returns the markdown heading prefix of the given level.
+func heading(level int) string {
+	return strings.Repeat("#", level)
+}
+
+// optLevel returns the heading level given as the optional last
+// argument of a template action (or def if not given).
+func optLevel(level []int, def, max int) (int, error) {
+	switch {
+	case len(level) == 0:
+		return def, nil
+	case len(level) > 1:
+		return 0, errors.New("too many heading levels given")
+	case level[0] < 1 || level[0] > max:
+		return 0, fmt.Errorf("heading level %d out of range 1 to %d", level[0], max)
+	}
+	return level[0], nil
+}
+
+// sectionLevel returns the level of the heading of an input or output
+// section of the endpoint e (or of a section not in an endpoint if
+// nil) unless given explicitly, and sets the level of the headings of
+// the types rendered in it.
+func (d *JSONDoc) sectionLevel(e *endpoint, level []int) (int, error) {
+	def := d.headingLevel() + 1
+	if e != nil {
+		def = e.level + 1
+	}
+	l, err := optLevel(level, def, maxSectionLevel)
+	if err != nil {
+		return 0, err
+	}
+	d.typeLevel = l + 1
+	return l, nil
+}
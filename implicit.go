@@ -0,0 +1,118 @@
+package jsondoc
+
+import (
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// importedPath returns the path of the package imported in the template
+// with the given name ("" if it is not imported). If the template does
+// not import a package as "." it is bound to the package given with
+// Options.Package or, if not given, to the package in the current
+// directory, so that single package projects do not need the import
+// action.
+func (d *JSONDoc) importedPath(name string) (string, error) {
+	if path := d.imports[name]; path != "" || name != "." {
+		return path, nil
+	}
+	pkg := d.implicitPackage
+	if pkg == "" {
+		pkg = "."
+	}
+	path := pkg
+	if isLocalPath(pkg) {
+		// relative to the current directory (not to the template)
+		var err error
+		if path, err = filepath.Abs(pkg); err != nil {
+			return "", err
+		}
+	}
+	if _, err := d.importPkg(".", path); err != nil {
+		return "", fmt.Errorf("implicit import of %s as \".\": %v", pkg, err)
+	}
+	return d.imports["."], nil
+}
+
+// localImport returns the import path of the package in the directory
+// given relative to the directory of the template (or, if not found
+// there, to the root of the module given with Options.Module) and
+// records the directory the package is loaded from (so that packages
+// not addressable by their import paths, such as internal packages of
+// other modules, may be documented). A package outside of modules and
+// GOPATH is identified by its directory.
+func (d *JSONDoc) localImport(rel string) (string, error) {
+	dirs := []string{rel}
+	if !filepath.IsAbs(rel) {
+		dirs = []string{filepath.Join(d.dir, rel)}
+		if d.module != nil {
+			dirs = append(dirs, filepath.Join(d.module.dir, rel))
+		}
+	}
+	for _, dir := range dirs {
+		if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+			continue
+		}
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			return "", err
+		}
+		path, err := dirImportPath(dir)
+		if err != nil {
+			path = filepath.ToSlash(dir)
+		}
+		if d.localDirs == nil {
+			d.localDirs = make(map[string]string)
+		}
+		d.localDirs[path] = dir
+		return path, nil
+	}
+	return "", fmt.Errorf("directory %s not found", strings.Join(dirs, " or "))
+}
+
+// isLocalPath reports whether the package is given as a directory
+// (such as "." or "./api") rather than an import path.
+func isLocalPath(pkg string) bool {
+	return pkg == "." || pkg == ".." || strings.HasPrefix(pkg, "./") || strings.HasPrefix(pkg, "../") || filepath.IsAbs(pkg)
+}
+
+// dirImportPath returns the import path of the package in the directory
+// in its module (the nearest go.mod file) or, outside of modules, in
+// GOPATH.
+func dirImportPath(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for root := dir; ; {
+		path, err := modulePath(filepath.Join(root, "go.mod"))
+		if err == nil {
+			rel, err := filepath.Rel(root, dir)
+			if err != nil {
+				return "", err
+			}
+			if rel == "." {
+				return path, nil
+			}
+			return path + "/" + filepath.ToSlash(rel), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			break
+		}
+		root = parent
+	}
+	p, err := build.ImportDir(dir, build.FindOnly)
+	if err != nil {
+		return "", err
+	}
+	if p.ImportPath == "" || p.ImportPath == "." {
+		return "", fmt.Errorf("directory %s is not in a module or in GOPATH", dir)
+	}
+	return p.ImportPath, nil
+}
@@ -0,0 +1,77 @@
+package jsondoc
+
+import (
+	"fmt"
+	"go/build"
+	"path/filepath"
+	"strings"
+)
+
+// importFilter selects the files of a package imported with the import
+// action (for example to skip large generated files).
+type importFilter struct {
+	tags             []string // additional build tags
+	include, exclude []string // patterns of the file names (as of filepath.Match)
+}
+
+// parseImportFilter returns the filter given with the arguments of the
+// import action following the path: "tags=a,b" (build tags satisfied in
+// addition to the default ones), "include=PATTERN" (only the files
+// matching one of the include patterns are parsed) and
+// "exclude=PATTERN" (such as "exclude=*_gen.go").
+func parseImportFilter(args []string) (importFilter, error) {
+	var f importFilter
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "tags="):
+			for _, tag := range strings.Split(strings.TrimPrefix(arg, "tags="), ",") {
+				if tag != "" {
+					f.tags = append(f.tags, tag)
+				}
+			}
+		case strings.HasPrefix(arg, "include="), strings.HasPrefix(arg, "exclude="):
+			pattern := arg[len("include="):]
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return f, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+			}
+			if strings.HasPrefix(arg, "include=") {
+				f.include = append(f.include, pattern)
+			} else {
+				f.exclude = append(f.exclude, pattern)
+			}
+		default:
+			return f, fmt.Errorf("unknown filter %q", arg)
+		}
+	}
+	return f, nil
+}
+
+// match reports whether the named file of the package is parsed.
+func (f importFilter) match(name string) bool {
+	if len(f.include) > 0 && !matchAny(f.include, name) {
+		return false
+	}
+	return !matchAny(f.exclude, name)
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// buildContext returns the build context the package with the given
+// import path is found with (with the build tags of its import filter).
+// The files using cgo are included even if cgo is disabled (such as
+// with CGO_ENABLED=0 or without a C compiler) as they are only parsed.
+func (d *JSONDoc) buildContext(path string) *build.Context {
+	ctx := build.Default
+	ctx.CgoEnabled = true
+	if f, ok := d.importFilters[path]; ok {
+		ctx.BuildTags = append(append([]string(nil), ctx.BuildTags...), f.tags...)
+	}
+	return &ctx
+}
@@ -0,0 +1,1493 @@
+// Copyright 2016-2017 Łukasz Pankowski <lukpank at o2 dot pl>. All rights
+// reserved.  This source code is licensed under the terms of the MIT
+// license. See LICENSE file for details.
+
+// Package jsondoc generates documentation of HTTP (REST) JSON APIs
+// for projects written in Go (as done by the jsondoc command). The
+// input and/or output JSON structure for particular endpoints is
+// obtained from named types from selected Go packages. The
+// documentation is an HTML file with embedded CSS which may also be
+// served by the API service itself (see Handler).
+//
+// See https://github.com/lukpank/jsondoc for further documentation.
+package jsondoc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"html"
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// JSONDoc is the documentation of an API described in a markdown
+// template. It is safe for concurrent use: the template is executed
+// once (by the first call of a method using it) and the calls of the
+// methods then render their outputs in parallel.
+type JSONDoc struct {
+	imports      map[string]string       // map: local in template name -> package path
+	packages     map[string]*ast.Package // map: package path -> package AST
+	packageNames map[string]string       // map: package path -> package name (may be obtained without parsing the package)
+	t            *template.Template
+	funcs        template.FuncMap // actions of the template
+	tmplName     string
+	table        *template.Template
+	b            bytes.Buffer
+	rendered     map[renderedElem]string
+	renderQueue  []queueElem
+	links        map[string]map[ast.Expr]int
+	title        string
+	md           bytes.Buffer // markdown output of the template
+	endpoints    []*endpoint
+	anchors      []anchor
+	ids          map[string]bool // ids of endpoints and sections
+	console      bool            // embed "Try it" consoles
+	baseURL      string          // base URL used by the consoles
+	rand         *rand.Rand      // source of fake values in samples
+	seed         int64           // seed of rand (of each call, see begin)
+	snippetsUsed bool            // the snippets action was used
+	config       *Config
+	dataField    *ast.Field // data field of the envelope being rendered
+	dataLink     string     // link to the type of data in the envelope
+
+	componentsMode bool                       // render shared types in "Common objects"
+	componentsUsed bool                       // the components action was used
+	section        string                     // id of the endpoint (or section) being rendered
+	sections       int                        // number of sections not in endpoints
+	owner          string                     // id of the named type being rendered
+	typeRefs       map[string]map[string]bool // map: type id -> referencing sections
+	chunks         map[string][]byte          // map: type id -> rendered type section
+	chunkOrder     []string
+	namedTypes     []namedType // rendered named types
+
+	dir      string            // directory of the template
+	stamp    *stamp            // generation metadata (if embedded)
+	glossary map[string]string // map: term -> definition
+	terms    []string          // glossary terms in order of definition
+	termsRe  *regexp.Regexp    // matches glossary terms
+
+	serverList  []server                 // declared base URLs of the API
+	xmlRendered map[*ast.TypeSpec]string // map: type -> id of its XML element section
+	codec       *codec                   // encoding of the values being rendered (nil for JSON)
+	engine      string                   // markdown engine
+	mermaidJS   string                   // source of mermaid (URL or file name)
+	sequences   [][]string               // endpoints of the sequence actions
+	typeLevel   int                      // level of the headings of the types being rendered
+	minify      bool                     // minify the output
+	fragment    bool                     // write only the content of the body element
+
+	captures          []CapturedExchange     // exchanges recorded by Capture
+	capturedValues    map[string]interface{} // map: endpoint id and "-input" or "-output" -> captured example
+	marshalersChecked map[*ast.TypeSpec]bool // types checked for asymmetric MarshalJSON and UnmarshalJSON
+	tests             bool                   // parse _test.go files of the packages
+	module            *module                // documenting module (nil if not configured)
+	workspace         workspace              // modules of the go.work workspace (if any)
+
+	localDirs     map[string]string       // map: import path -> directory of the packages imported with relative paths
+	importFilters map[string]importFilter // map: import path -> filter of the files given with the import action
+	stdPackages   map[string]bool         // map: import path -> whether it is a package of the standard library
+
+	externalTypes     []externalType       // types of other packages referenced but not expanded
+	externalIDs       map[string]string    // map: package path and type name -> id of the external type
+	externalTypesUsed bool                 // the externalTypes action was used
+	referenced        map[*ast.Object]bool // objects of the imported packages looked up while documenting
+	graph             map[string][]string  // map: section or type id -> ids of the types it refers to
+	graphParent       string               // id of the section or the type being rendered
+	renderedTypes     int                  // types rendered (for Stats)
+	undescribed       int                  // fields rendered with no description (for Stats)
+	warnings          int                  // warnings reported (for Stats)
+	embedStats        bool                 // embed the statistics in the footer
+	rtl               bool                 // right-to-left layout
+	highContrast      bool                 // high-contrast theme
+	coverPage         bool                 // generate the cover page of the printed documentation
+	branding          branding             // logo, header bar and footer
+	changesSince      string               // old OpenAPI file of the apiChanges action
+	timer             timer                // times of the phases of the generation
+	defaultLayout     string               // presentation of the fields (see fieldLayouts)
+	fieldLayout       string               // presentation of the fields set with the layout action
+	keepOpen          int                  // keepTogether actions not ended yet
+	typeName          string               // name of the type being rendered (for table captions)
+	jsonv2            bool                 // interpret the json tags as encoding/json/v2
+	implicitPackage   string               // package imported as "." unless imported by the template (the current directory if empty)
+	qualifyTypes      bool                 // qualify the headings and anchors of types with the package
+	swag              bool                 // read the annotations of swaggo/swag of handlers
+	searchUsed        bool                 // the search action was used
+	searchFields      []searchEntry        // fields rendered (for the search index)
+
+	fset          *token.FileSet            // positions of the parsed packages
+	typesPackages map[string]*types.Package // map: package path -> type checked package (nil while being checked)
+	typesInfo     *types.Info               // identifiers of the type checked packages
+	typeDecls     map[types.Object]typeDecl // map: named type -> its declaration
+	stdImporter   types.Importer            // imports the packages of the standard library from their export data
+
+	calls *calls // state shared by the calls of the methods
+}
+
+type queueElem struct {
+	t     *ast.TypeSpec
+	c     *context
+	id    string
+	named bool   // a named type (not an anonymous struct)
+	owner string // id of the named type it was referenced from (if any)
+}
+
+type renderedElem struct {
+	Name string
+	Obj  *ast.Object
+}
+
+func NewJSONDoc(filename string) (*JSONDoc, error) {
+	d := &JSONDoc{rendered: make(map[renderedElem]string), links: make(map[string]map[ast.Expr]int),
+		packages: make(map[string]*ast.Package), packageNames: make(map[string]string), imports: make(map[string]string), ids: make(map[string]bool),
+		rand: rand.New(rand.NewSource(1)), seed: 1, config: &Config{}, mermaidJS: DefaultMermaidJS, typeRefs: make(map[string]map[string]bool), importFilters: make(map[string]importFilter), stdPackages: make(map[string]bool), chunks: make(map[string][]byte),
+		dir: filepath.Dir(filename), glossary: make(map[string]string), xmlRendered: make(map[*ast.TypeSpec]string),
+		capturedValues: make(map[string]interface{}), marshalersChecked: make(map[*ast.TypeSpec]bool), referenced: make(map[*ast.Object]bool),
+		fset: token.NewFileSet(), typesPackages: make(map[string]*types.Package), defaultLayout: "table", fieldLayout: "table", calls: &calls{},
+		typesInfo: &types.Info{Defs: make(map[*ast.Ident]types.Object), Uses: make(map[*ast.Ident]types.Object)}, typeDecls: make(map[types.Object]typeDecl)}
+	d.stdImporter = importer.ForCompiler(d.fset, "gc", nil)
+	d.funcs = template.FuncMap{"input": d.input, "output": d.output, "title": d.setTitle, "import": d.importPkg,
+		"endpoint": d.endpoint, "snippets": d.snippets, "envelope": d.envelope, "components": d.components,
+		"typeIndex": d.typeIndex, "term": d.term, "glossaryFile": d.glossaryFile, "glossary": d.glossaryTable,
+		"servers": d.servers, "inputMultipart": d.inputMultipart, "inputForm": d.inputForm, "inputXML": d.inputXML,
+		"outputXML": d.outputXML, "contentType": d.contentType, "sequence": d.sequence, "consts": d.consts,
+		"paginated": d.paginated, "stdError": d.stdError,
+		"outputFile": d.outputFile, "outputHeaders": d.outputHeaders, "noInput": d.noInput, "noOutput": d.noOutput,
+		"outputStream": d.outputStream, "outputList": d.outputList, "outputMap": d.outputMap,
+		"types": d.packageTypes, "externalTypes": d.externalTypesChapter,
+		"apiChanges": d.apiChanges, "schemaInline": d.schemaInline, "layout": d.layout, "tree": d.tree, "search": d.search, "handler": d.handler, "pagebreak": d.pagebreak, "keepTogether": d.keepTogether, "endKeepTogether": d.endKeepTogether}
+	d.t = template.New("").Funcs(d.funcs)
+	if _, err := d.t.ParseFiles(filename); err != nil {
+		return nil, err
+	}
+	d.table = template.New("table")
+	if _, err := d.table.Parse(table); err != nil {
+		return nil, err
+	}
+	if _, err := d.table.New("list").Parse(fieldList); err != nil {
+		return nil, err
+	}
+	if _, err := d.table.New("form").Parse(formTable); err != nil {
+		return nil, err
+	}
+	if _, err := d.table.New("xml").Parse(xmlTable); err != nil {
+		return nil, err
+	}
+	d.tmplName = filepath.Base(filename)
+	return d, nil
+}
+
+// Options are the settings of the generated documentation (as given
+// with the flags of the jsondoc command).
+type Options struct {
+	Config       string // JSON file with the project configuration (if any)
+	Partials     string // directory of templates parsed together with the template (if any)
+	Try          bool   // embed "Try it" consoles
+	BaseURL      string // base URL of the API used by the consoles
+	Seed         int64  // seed for fake values in samples (1 if zero)
+	Components   bool   // render types referenced from many endpoints in "Common objects"
+	Engine       string // markdown engine: "goldmark" (if empty) or "blackfriday"
+	MermaidJS    string // URL of the mermaid ES module or a local file (a CDN if empty)
+	Minify       bool   // minify the output (requiring all assets to be embedded)
+	Fragment     bool   // write only the content of the body
+	Stamp        bool   // embed generation metadata
+	Stats        bool   // embed generation statistics in the footer
+	RTL          bool   // right-to-left layout (for languages such as Arabic and Hebrew)
+	HighContrast bool   // high-contrast theme
+	Cover        bool   // generate the cover page of the printed documentation
+	Logo         string // URL or local file of the logo image (overrides the configuration)
+	Header       string // HTML of the header bar (overrides the configuration)
+	Footer       string // HTML of the footer (overrides the configuration)
+	Layout       string // presentation of the fields of objects: "table" (if empty), "annotated" or "list"
+	JSONv2       bool   // interpret the json struct tags as encoding/json/v2 (quoted keys, inline, format and case options)
+	Package      string // import path or directory of the package imported as "." unless the template imports it (the current directory if empty)
+	QualifyTypes bool   // qualify the headings and anchors of types with the template import name of their package (such as "Type items.size")
+	Swag         bool   // read the annotations of swaggo/swag (such as @Summary, @Param and @Success) of the handlers of the handler action
+	Commit       string // git commit for Stamp (obtained with git if empty)
+	Captures     string // file with exchanges recorded by Capture used as examples (if any)
+	Tests        bool   // parse _test.go files of the imported packages
+	Module       string // root directory of the documenting module whose internal packages may be imported (if any)
+	Download     bool   // download dependencies of Module missing from the module cache (with go mod download)
+	At           string // git revision (such as a tag) of Module the documented packages are loaded at (the working tree if empty)
+}
+
+// New returns the documentation of the API described in the named
+// markdown template with the given options.
+func New(filename string, opts Options) (*JSONDoc, error) {
+	d, err := NewJSONDoc(filename)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Partials != "" {
+		if err := d.parsePartials(opts.Partials); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Config != "" {
+		if d.config, err = readConfig(opts.Config); err != nil {
+			return nil, err
+		}
+	}
+	switch opts.Engine {
+	case "":
+	case engineGoldmark, engineBlackfriday:
+		d.engine = opts.Engine
+	default:
+		return nil, fmt.Errorf("unknown markdown engine %q", opts.Engine)
+	}
+	if opts.Seed != 0 {
+		d.rand, d.seed = rand.New(rand.NewSource(opts.Seed)), opts.Seed
+	}
+	if opts.MermaidJS != "" {
+		d.mermaidJS = opts.MermaidJS
+	}
+	if opts.Stamp {
+		if d.stamp, err = newStamp(d.dir, opts.Commit); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Module != "" {
+		if d.module, err = newModule(opts.Module); err != nil {
+			return nil, err
+		}
+		d.module.download = opts.Download
+	} else if opts.Download {
+		return nil, errors.New("downloading dependencies requires the documenting module (given with -module)")
+	}
+	dir := d.dir
+	if d.module != nil {
+		dir = d.module.dir
+	}
+	if d.workspace, err = findWorkspace(dir); err != nil {
+		return nil, err
+	}
+	if opts.At != "" {
+		if d.module == nil {
+			return nil, errors.New("documenting a revision requires the documenting module (given with -module)")
+		}
+		commit, err := d.module.checkout(opts.At)
+		if err != nil {
+			return nil, err
+		}
+		if d.workspace == nil {
+			d.workspace = make(workspace)
+		}
+		// packages of the module are read from the checked out tree
+		d.workspace[d.module.path] = d.module.dir
+		if d.stamp != nil && opts.Commit == "" {
+			d.stamp.Commit = commit
+		}
+	}
+	if opts.Captures != "" {
+		if d.captures, err = readCaptures(opts.Captures); err != nil {
+			return nil, err
+		}
+	}
+	d.console = opts.Try
+	d.tests = opts.Tests
+	d.baseURL = opts.BaseURL
+	d.componentsMode = opts.Components
+	d.minify = opts.Minify
+	d.fragment = opts.Fragment
+	d.embedStats = opts.Stats
+	d.rtl = opts.RTL
+	d.highContrast = opts.HighContrast
+	d.jsonv2 = opts.JSONv2
+	d.qualifyTypes = opts.QualifyTypes
+	d.swag = opts.Swag
+	d.implicitPackage = opts.Package
+	d.coverPage = opts.Cover
+	if opts.Layout != "" {
+		if !fieldLayouts[opts.Layout] {
+			return nil, fmt.Errorf("unknown layout %q (supported: %s)", opts.Layout, strings.Join(layoutNames(), ", "))
+		}
+		d.defaultLayout, d.fieldLayout = opts.Layout, opts.Layout
+	}
+	d.branding = branding{d.config.Logo, d.config.Header, d.config.Footer}
+	if opts.Logo != "" {
+		d.branding.Logo = opts.Logo
+	}
+	if opts.Header != "" {
+		d.branding.Header = opts.Header
+	}
+	if opts.Footer != "" {
+		d.branding.Footer = opts.Footer
+	}
+	return d, nil
+}
+
+// Endpoints returns the documented endpoints (such as "POST /hello")
+// once the template was executed (by any of the Write methods or
+// MockHandler).
+func (d *JSONDoc) Endpoints() []string {
+	if !d.calls.done.Load() {
+		return nil
+	}
+	var a []string
+	for _, e := range d.endpoints {
+		a = append(a, e.Title())
+	}
+	return a
+}
+
+// parsePartials parses all the files in the directory dir into the
+// template set of the documentation so that the templates defined in
+// them (named by the file names or with the define action) may be
+// used with the template action.
+func (d *JSONDoc) parsePartials(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("partials: %v", err)
+	}
+	var filenames []string
+	for _, e := range entries {
+		if !e.Type().IsRegular() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		if e.Name() == d.tmplName {
+			return fmt.Errorf("partials: %s has the same name as the documentation template", filepath.Join(dir, e.Name()))
+		}
+		filenames = append(filenames, filepath.Join(dir, e.Name()))
+	}
+	if len(filenames) == 0 {
+		return fmt.Errorf("partials: no templates in %s", dir)
+	}
+	_, err = d.t.ParseFiles(filenames...)
+	return err
+}
+
+// execute executes the template (only once, concurrent callers wait
+// for it) collecting its markdown output and the documented endpoints.
+// The state it collects is not modified afterwards.
+func (d *JSONDoc) execute() error {
+	d.executeOnce(nil)
+	return d.calls.err
+}
+
+// executeOnce executes the template (calling setup before) unless it
+// was already executed and reports whether it executed it.
+func (d *JSONDoc) executeOnce(setup func()) bool {
+	executed := false
+	d.calls.once.Do(func() {
+		executed = true
+		defer d.calls.done.Store(true)
+		if setup != nil {
+			setup()
+		}
+		defer d.timer.start("resolve")()
+		if err := d.t.ExecuteTemplate(&d.md, d.tmplName, nil); err != nil {
+			d.calls.err = err
+		} else if d.keepOpen > 0 {
+			d.calls.err = errors.New("keepTogether without endKeepTogether")
+		}
+	})
+	return executed
+}
+
+// resolvedMarkdown returns the markdown output of the template with
+// the placeholders (of the sequence diagrams and of the generated
+// chapters) replaced.
+func (d *JSONDoc) resolvedMarkdown() ([]byte, error) {
+	md, err := d.resolveSequences(d.md.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	md = d.resolveExternalTypes(md)
+	if md, err = d.resolveAPIChanges(md); err != nil {
+		return nil, err
+	}
+	if md, err = d.resolveSearch(md); err != nil {
+		return nil, err
+	}
+	if d.componentsMode {
+		md = d.resolveComponents(md)
+	}
+	return md, nil
+}
+
+// WriteMarkdown writes the markdown document rendered by WriteTo (with
+// the documented types as HTML blocks), for example to be published
+// with another static site generator.
+func (d *JSONDoc) WriteMarkdown(w io.Writer) error {
+	c, err := d.begin()
+	if err != nil {
+		return err
+	}
+	defer d.end(c)
+	return c.writeMarkdown(w)
+}
+
+func (d *JSONDoc) writeMarkdown(w io.Writer) error {
+	md, err := d.resolvedMarkdown()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(md)
+	return err
+}
+
+func (d *JSONDoc) WriteTo(w io.Writer) (int64, error) {
+	c, err := d.begin()
+	if err != nil {
+		return 0, err
+	}
+	defer d.end(c)
+	return c.writeTo(w)
+}
+
+func (d *JSONDoc) writeTo(w io.Writer) (int64, error) {
+	defer d.timer.start("render")()
+	md, err := d.resolvedMarkdown()
+	if err != nil {
+		return 0, err
+	}
+	body, err := d.parseMarkdown(md)
+	if err != nil {
+		return 0, err
+	}
+	h := pageHeader{Title: html.EscapeString(d.title), Lang: "en", RTL: d.rtl, HighContrast: d.highContrast, Cover: d.coverPage}
+	if d.config.Locale != "" {
+		h.Lang = d.config.Locale
+	}
+	if d.title != "" {
+		h.PrintTitle = cssString(d.title)
+	}
+	meta, err := d.metaTags()
+	if err != nil {
+		return 0, err
+	}
+	h.Head += meta
+	var footer string
+	if d.stamp != nil {
+		h.Head += d.stamp.head()
+		footer += d.stamp.footer(d.locale().DateTime)
+	}
+	if d.embedStats {
+		footer += d.stats().footer()
+	}
+	if !d.fragment {
+		footer += d.branding.footer()
+	}
+	var b bytes.Buffer
+	if err := htmlHeaderTmpl.Execute(&b, h); err != nil {
+		return 0, err
+	}
+	header, err := d.branding.header()
+	if err != nil {
+		return 0, err
+	}
+	b.WriteString(header)
+	if d.coverPage {
+		cover, err := d.cover()
+		if err != nil {
+			return 0, err
+		}
+		b.WriteString(cover)
+	}
+	head := b.String()
+	if d.fragment {
+		head = ""
+	}
+	if d.minify {
+		head = minifyHTML(head)
+	}
+	var script string
+	if d.console {
+		if script, err = consoleScript(d.apiBaseURL("")); err != nil {
+			return 0, err
+		}
+	}
+	if d.snippetsUsed {
+		script += snippetsJS
+	}
+	if d.searchUsed {
+		script += searchJS
+	}
+	var mermaid string
+	if body.mermaid {
+		if d.minify && strings.Contains(d.mermaidJS, "://") {
+			return 0, errors.New("mermaid: a minified document requires a local mermaid.min.js (given with -mermaid-js) to be embedded")
+		}
+		if mermaid, err = mermaidScript(d.mermaidJS); err != nil {
+			return 0, err
+		}
+	}
+	tail, end := footer+script, htmlFooter
+	if d.fragment {
+		end = "\n" + anchorsJS + navJS
+	}
+	if d.minify {
+		// the embedded mermaid bundle is already minified
+		tail, end = minifyHTML(tail), minifyHTML(end)
+	}
+	n, err := io.WriteString(w, head)
+	var m int64
+	var o int
+	if err == nil {
+		m, err = body.writeTo(w, d.minify)
+	}
+	if err == nil {
+		o, err = io.WriteString(w, tail+mermaid+end)
+	}
+	return int64(n) + m + int64(o), err
+}
+
+func (d *JSONDoc) setTitle(title string) string {
+	d.title = title
+	return ""
+}
+
+// importPkg imports the package with the given path (or directory
+// relative to the template) under the name, optionally with the filters
+// of its files (see parseImportFilter).
+func (d *JSONDoc) importPkg(name, path string, filters ...string) (string, error) {
+	if d.imports[name] != "" {
+		return "", fmt.Errorf("name %s already imported", name)
+	}
+	f, err := parseImportFilter(filters)
+	if err != nil {
+		return "", fmt.Errorf("import %s: %v", name, err)
+	}
+	if isLocalPath(path) {
+		if path, err = d.localImport(path); err != nil {
+			return "", fmt.Errorf("import %s: %v", name, err)
+		}
+	}
+	if len(filters) > 0 {
+		if d.loadedPackage(path) != nil {
+			return "", fmt.Errorf("import %s: package %s is already loaded (its filters must be given where it is imported first)", name, path)
+		}
+		d.importFilters[path] = f
+	}
+	if _, err := d.parsedPackage(path); err != nil {
+		return "", err
+	}
+	d.imports[name] = path
+	return "", nil
+}
+
+func (d *JSONDoc) input(name string, level ...int) (string, error) {
+	d.b.Reset()
+	e := d.currentEndpoint()
+	l, err := d.sectionLevel(e, level)
+	if err != nil {
+		return "", fmt.Errorf("input %s: %v", name, err)
+	}
+	id := d.sectionID(e, "input", name)
+	title := markdownEscapeString(name)
+	contentType := ""
+	if e != nil && e.codec != nil {
+		d.codec = e.codec
+		defer func() { d.codec = nil }()
+		e.InputContentType = e.codec.ContentType
+	}
+	if e != nil && e.InputContentType != "" {
+		contentType = e.InputContentType
+		title += ", " + contentType
+	}
+	fmt.Fprintf(&d.b, "%s Input (%s) {#%s}\n<div>\n", heading(l), title, id)
+	if d.writeContentType(contentType) {
+		if err := d.renderTypes(name, id); err != nil {
+			return "", err
+		}
+	}
+	if err := d.renderCaptured(e, false); err != nil {
+		return "", err
+	}
+	if d.console && e != nil && contentType == "" {
+		if err := d.renderConsole(e); err != nil {
+			return "", err
+		}
+	}
+	d.b.WriteString("</div>\n")
+	return d.b.String(), nil
+}
+
+func (d *JSONDoc) output(name string, level ...int) (string, error) {
+	env := ""
+	if d.currentEndpoint() != nil {
+		env = d.config.Envelope
+	}
+	return d.renderOutput(name, env, "", level)
+}
+
+// renderOutput renders the output section for the type name (in the
+// container, if any, see endpoint.Container) wrapped in the envelope
+// type env (if not empty) with the heading of the given level (if any).
+func (d *JSONDoc) renderOutput(name, env, container string, level []int) (string, error) {
+	d.b.Reset()
+	title := containerTitle(container, markdownEscapeString(typeIdent(name)))
+	if env != "" {
+		title += " in " + markdownEscapeString(typeIdent(env))
+	}
+	e := d.currentEndpoint()
+	l, err := d.sectionLevel(e, level)
+	if err != nil {
+		return "", fmt.Errorf("output %s: %v", name, err)
+	}
+	id := d.sectionID(e, "output", name)
+	contentType := ""
+	if e != nil && e.codec != nil {
+		d.codec = e.codec
+		defer func() { d.codec = nil }()
+		e.OutputContentType = e.codec.ContentType
+	}
+	if e != nil && e.OutputContentType != "" {
+		contentType = e.OutputContentType
+		title += ", " + contentType
+	}
+	fmt.Fprintf(&d.b, "%s Output (%s) {#%s}\n<div>\n", heading(l), title, id)
+	if e != nil {
+		e.Envelope, e.Container = env, container
+	}
+	switch {
+	case !d.writeContentType(contentType):
+	case env != "":
+		err = d.renderEnvelope(env, name, container, id)
+	case container == "array":
+		fmt.Fprintf(&d.b, "<p>%s array of values of type %s.</p>\n", d.format(), html.EscapeString(typeIdent(name)))
+		err = d.renderTypes(name, id)
+	case container == "object":
+		fmt.Fprintf(&d.b, "<p>%s object mapping keys to values of type %s.</p>\n", d.format(), html.EscapeString(typeIdent(name)))
+		err = d.renderTypes(name, id)
+	default:
+		err = d.renderTypes(name, id)
+	}
+	if err == nil {
+		err = d.renderCaptured(e, true)
+	}
+	if err == nil && e != nil && contentType == ndjsonContentType {
+		err = d.renderStream(e)
+	}
+	if err != nil {
+		return "", err
+	}
+	if d.console && e != nil && e.Input == "" && d.codec == nil {
+		if err := d.renderConsole(e); err != nil {
+			return "", err
+		}
+	}
+	d.b.WriteString("</div>\n")
+	return d.b.String(), nil
+}
+
+// sectionID records the type name of the input, output or errors
+// section of the endpoint e (if not nil) and returns the markdown
+// header id for the section.
+func (d *JSONDoc) sectionID(e *endpoint, kind, name string) string {
+	title := map[string]string{"input": "Input", "output": "Output", "errors": "Errors"}[kind]
+	if e == nil {
+		d.sections++
+		d.section = fmt.Sprintf("section-%d", d.sections)
+		id := d.uniqueID(kind + "-" + idFromString(typeIdent(name)))
+		d.addAnchor(anchor{ID: id, Kind: kind, Title: title + " " + typeIdent(name), Type: name})
+		return id
+	}
+	d.section = e.ID
+	switch kind {
+	case "input":
+		e.Input = name
+	case "output":
+		e.Output = name
+	case "errors":
+		e.Error = name
+	}
+	id := d.uniqueID(e.ID + "-" + kind)
+	d.addAnchor(anchor{ID: id, Kind: kind, Title: e.Title() + " " + title, Method: e.Method, Path: e.Path, Type: name})
+	d.addEdge(e.ID, id)
+	return id
+}
+
+// renderTypes renders the type name (unless it was already rendered,
+// then it links to it) in the section with the given id followed by
+// the types it refers to.
+func (d *JSONDoc) renderTypes(name, id string) error {
+	d.graphParent = id
+	if err := d.renderTypeByName(name, id); err != nil {
+		return err
+	}
+	return d.renderQueued()
+}
+
+// writeHeading writes the heading (of a type) with the given id and
+// title and its permalink.
+func (d *JSONDoc) writeHeading(id, title string) {
+	id = html.EscapeString(id)
+	fmt.Fprintf(&d.b, "<h%d id=\"%s\">%s%s</h%[1]d>\n", d.typeLevel, id, html.EscapeString(title), anchorLink(id))
+}
+
+// renderQueued renders the types queued with renderLater.
+func (d *JSONDoc) renderQueued() error {
+	for i := 0; i < len(d.renderQueue); i++ {
+		q := d.renderQueue[i]
+		start := d.b.Len()
+		if d.componentsMode && q.named {
+			// leave a placeholder in the section (or type) referencing the type
+			d.writeChunk(q.owner, start, typePlaceholder(q.id))
+			d.chunkOrder = append(d.chunkOrder, q.id)
+			start = d.b.Len()
+		}
+		d.owner = q.owner
+		if q.named {
+			d.owner = q.id
+		}
+		name := q.t.Name.Name
+		if q.named {
+			name = d.qualifiedName(name, q.c)
+		}
+		d.writeHeading(q.id, "Type "+name)
+		d.addAnchor(anchor{ID: q.id, Kind: "type", Title: "Type " + name, Type: q.t.Name.Name})
+		d.renderedTypes++
+		d.graphParent = q.id
+		err := d.renderType(q.t, q.c)
+		if d.componentsMode && d.owner != "" {
+			d.writeChunk(d.owner, start, "")
+		}
+		d.owner = ""
+		if err != nil {
+			return err
+		}
+	}
+	d.renderQueue = d.renderQueue[:0]
+	return nil
+}
+
+// writeChunk moves the output rendered after start (followed by s) to
+// the chunk of the type with the given id (or leaves it in the section
+// if id is empty).
+func (d *JSONDoc) writeChunk(id string, start int, s string) {
+	d.b.WriteString(s)
+	if id == "" {
+		return
+	}
+	d.chunks[id] = append(d.chunks[id], d.b.Bytes()[start:]...)
+	d.b.Truncate(start)
+}
+
+func (d *JSONDoc) renderTypeByName(name, id string) error {
+	t, c, err := d.lookupTypeName(name)
+	if err != nil {
+		return err
+	}
+	if d.renderedAt(t, c, id) {
+		return nil
+	}
+	return d.renderType(t, c)
+}
+
+// renderedAt records that the named type t is rendered in the section
+// with the given id (so that further references link there). If it was
+// already rendered elsewhere it writes a link to it and returns true.
+func (d *JSONDoc) renderedAt(t *ast.TypeSpec, c *context, id string) bool {
+	if t.Name.Obj == nil {
+		return false
+	}
+	key := renderedElem{t.Name.Name, t.Name.Obj}
+	if s := d.rendered[key]; s != "" {
+		d.addTypeRef(s)
+		d.addEdge(id, s)
+		fmt.Fprintf(&d.b, "<p>%s value of <a href=\"#%s\">type %s</a> described above.</p>\n", d.format(), html.EscapeString(s), html.EscapeString(d.qualifiedName(t.Name.Name, c)))
+		return true
+	}
+	d.rendered[key] = id
+	d.namedTypes = append(d.namedTypes, namedType{t.Name.Name, c.Package.Name, id})
+	d.renderedTypes++
+	d.addTypeRef(id)
+	return false
+}
+
+// lookupTypeName returns the type declaration of the type with the
+// given name (as given to the input and output actions).
+func (d *JSONDoc) lookupTypeName(name string) (*ast.TypeSpec, *context, error) {
+	pkgName := "."
+	i := strings.LastIndexByte(name, '.')
+	if i != -1 {
+		pkgName = name[:i]
+		name = name[i+1:]
+	}
+	path, err := d.importedPath(pkgName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if path == "" {
+		return nil, nil, fmt.Errorf("name %s mast be imported to access %s", pkgName, name)
+	}
+	if i == -1 {
+		if c := d.typeCandidates(name); len(c) > 1 {
+			return nil, nil, fmt.Errorf("type %s is ambiguous: it is declared in the packages imported as %s (qualify the name with one of them, the default package may also be imported under a name)", name, strings.Join(c, ", "))
+		}
+	}
+	o, c, err := d.findObject(name, d.loadedPackage(path), path)
+	if o == nil {
+		return nil, nil, fmt.Errorf("Type %s error: %v", name, err)
+	}
+	t, ok := o.Decl.(*ast.TypeSpec)
+	if !ok {
+		return nil, nil, fmt.Errorf("Object named %s is not a type", name)
+	}
+	t, c = d.unalias(t, c)
+	return t, c, nil
+}
+
+// typeCandidates returns the imported packages (the names they are
+// imported with in the template followed by their paths) which declare
+// a type of the given name.
+func (d *JSONDoc) typeCandidates(name string) []string {
+	names := make(map[string][]string) // map: package path -> import names
+	for n, path := range d.imports {
+		names[path] = append(names[path], strconv.Quote(n))
+	}
+	var c []string
+	for path, n := range names {
+		for _, f := range d.loadedPackage(path).Files {
+			if o := f.Scope.Objects[name]; o != nil && o.Kind == ast.Typ {
+				sort.Strings(n)
+				c = append(c, strings.Join(n, " or ")+" ("+path+")")
+				break
+			}
+		}
+	}
+	sort.Strings(c)
+	return c
+}
+
+type field struct {
+	Name, Type, Description string
+	kind                    string // kind of the JSON values (see valueKind)
+}
+
+func (d *JSONDoc) renderType(typ *ast.TypeSpec, c *context) error {
+	d.checkMarshalers(typ, c)
+	d.typeName = typ.Name.Name
+	if typ.Name.Obj != nil {
+		d.typeName = d.qualifiedName(typ.Name.Name, c)
+	}
+	if err := d.renderType1(typ.Type, c, ""); err != nil {
+		return err
+	}
+	d.renderEnum(typ, c)
+	return d.renderNotes(typ, c)
+}
+
+func (d *JSONDoc) renderType1(typ ast.Expr, c *context, prefix string) error {
+	switch t := typ.(type) {
+	case *ast.StructType:
+		fields, err := d.appendFields(nil, t, c)
+		if err != nil {
+			return err
+		}
+		d.addSearchFields(fields)
+		s := ""
+		if prefix != "" {
+			s = "s"
+		}
+		if len(fields) == 0 {
+			fmt.Fprintf(&d.b, "<p>%s %sobject%s with no fields.</p>\n", d.format(), prefix, s)
+			return nil
+		}
+		return d.renderFields(fields, prefix, s)
+	case *ast.MapType:
+		ident, ok := t.Key.(*ast.Ident)
+		if !ok || ident.Name != "string" {
+			return errors.New("only maps with string keys are supported")
+		}
+		if prefix == "" {
+			prefix = "object of "
+		} else {
+			prefix = prefix + " objects of "
+		}
+		return d.renderType1(t.Value, c, prefix)
+	case *ast.ArrayType:
+		if prefix == "" {
+			prefix = "array of "
+		} else {
+			prefix = prefix + " arrays of "
+		}
+		return d.renderType1(t.Elt, c, prefix)
+	case *ast.StarExpr:
+		return d.renderType1(t.X, c, prefix)
+	case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.IndexListExpr:
+		if prefix == "" && typeArgs(t) == nil && !d.stdType(t, c) {
+			// a definition of another type has its representation (an
+			// instantiation of a generic type links to the generic
+			// type instead)
+			ts, c, err := d.lookupType(t, c)
+			if err != nil {
+				return err
+			}
+			if ts != nil {
+				return d.renderType1(ts.Type, c, "")
+			}
+		}
+		s := ""
+		if prefix != "" {
+			s = "s"
+		}
+		fmt.Fprintf(&d.b, "<p>%s %svalue%s of type %s.</p>\n", d.format(), prefix, s, d.typeLink(t, c, "", ""))
+	}
+	return nil
+}
+
+// stdType reports whether the selector expression refers to a type of
+// the standard library (such as time.Time).
+func (d *JSONDoc) stdType(t ast.Expr, c *context) bool {
+	sel, ok := t.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	path, err := d.findImportIdent(c.File, ident.Name)
+	if err != nil {
+		return false
+	}
+	d.calls.cache.Lock()
+	std, ok := d.stdPackages[path]
+	d.calls.cache.Unlock()
+	if !ok {
+		p, err := d.importPackage(path, build.FindOnly)
+		std = err == nil && p.Goroot
+		d.calls.cache.Lock()
+		d.stdPackages[path] = std
+		d.calls.cache.Unlock()
+	}
+	return std
+}
+
+func (d *JSONDoc) appendFields(fields []field, t *ast.StructType, c *context) ([]field, error) {
+	for _, f := range t.Fields.List {
+		if typ := d.inlined(f); typ != nil {
+			if s, ok := typ.(*ast.StarExpr); ok {
+				typ = s.X
+			}
+			t, c, err := d.lookupType(typ, c)
+			if err != nil {
+				return nil, err
+			}
+			if t == nil {
+				continue
+			}
+			if t, ok := t.Type.(*ast.StructType); ok {
+				var err error
+				fields, err = d.appendFields(fields, t, c)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		if m := d.inlinedMap(f); m != nil {
+			desc := d.linkTerms(html.EscapeString(parseFieldComment(f).Description))
+			fields = append(fields, field{"any other key", d.typeLink(m.Value, c, "other keys", ""), desc, d.valueKind(m.Value, c)})
+			continue
+		}
+		for _, indent := range f.Names {
+			name, err := d.fieldName(indent.Name, f.Tag)
+			if err != nil {
+				if err == NotExported {
+					continue
+				}
+				return nil, err
+			}
+			fc := parseFieldComment(f)
+			typ := d.dataLink
+			if f != d.dataField {
+				typ = d.typeLink(f.Type, c, name, "") + d.allowedValues(f.Type, c)
+			}
+			var opts jsonOptions
+			if d.codec == nil {
+				opts, _ = d.jsonTag(indent.Name, f.Tag)
+			}
+			if fc.Format == "" {
+				fc.Format = opts.Format
+			}
+			if fc.Format != "" {
+				typ += "<br>format: " + html.EscapeString(fc.Format)
+			}
+			l, err := fieldLimits(f, fc)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %v", indent.Name, err)
+			}
+			kind := d.valueKind(f.Type, c)
+			if s := l.describe(kind, d.locale().DecimalSeparator); s != "" {
+				typ += "<br>" + html.EscapeString(s)
+			}
+			if fc.Description == "" {
+				d.undescribed++
+			}
+			desc := d.linkTerms(html.EscapeString(fc.Description))
+			if fc.PresentWhen != "" {
+				if desc != "" {
+					desc += "<br>"
+				}
+				desc += "Present when: <code>" + html.EscapeString(fc.PresentWhen) + "</code>"
+			}
+			both := opts.OmitEmpty && opts.OmitZero
+			if d.codec != nil {
+				both = omitsEmptyAndZero(f.Tag, d.codec.tagName(f.Tag))
+			}
+			if both {
+				if desc != "" {
+					desc += "<br>"
+				}
+				desc += "Omitted when empty or zero."
+			}
+			if opts.CaseIgnore {
+				if desc != "" {
+					desc += "<br>"
+				}
+				desc += "The key is matched ignoring case."
+			}
+			fields = append(fields, field{html.EscapeString(name), typ, desc, kind})
+		}
+	}
+	return fields, nil
+}
+
+type context struct {
+	Path    string
+	Package *ast.Package
+	File    *ast.File
+}
+
+func (d *JSONDoc) findObject(name string, pkg *ast.Package, path string) (*ast.Object, *context, error) {
+	for _, f := range pkg.Files {
+		if o := f.Scope.Objects[name]; o != nil {
+			d.reference(o)
+			return o, &context{path, pkg, f}, nil
+		}
+	}
+	if builtin[name] {
+		return nil, nil, nil
+	}
+	return nil, nil, fmt.Errorf("identifier %s not found in package %s", name, path)
+}
+
+// reference records that the object of an imported package was looked
+// up while documenting (see Unused).
+func (d *JSONDoc) reference(o *ast.Object) {
+	d.calls.cache.Lock()
+	defer d.calls.cache.Unlock()
+	d.referenced[o] = true
+}
+
+// isReferenced reports whether the object was looked up while
+// documenting.
+func (d *JSONDoc) isReferenced(o *ast.Object) bool {
+	d.calls.cache.Lock()
+	defer d.calls.cache.Unlock()
+	return d.referenced[o]
+}
+
+// loadedPackage returns the package with the given path if it was
+// already parsed (and nil otherwise).
+func (d *JSONDoc) loadedPackage(path string) *ast.Package {
+	d.calls.cache.Lock()
+	defer d.calls.cache.Unlock()
+	return d.packages[path]
+}
+
+// packageName returns the name of the package with the given path if
+// it is already known (and "" otherwise).
+func (d *JSONDoc) packageName(path string) string {
+	d.calls.cache.Lock()
+	defer d.calls.cache.Unlock()
+	return d.packageNames[path]
+}
+
+func (d *JSONDoc) parsedPackage(path string) (*ast.Package, error) {
+	if pkg := d.loadedPackage(path); pkg != nil {
+		return pkg, nil
+	}
+	if d.tests && strings.HasSuffix(path, "_test") {
+		// the external test package is parsed with the package
+		if _, err := d.parsedPackage(strings.TrimSuffix(path, "_test")); err != nil {
+			return nil, err
+		}
+		if pkg := d.loadedPackage(path); pkg != nil {
+			return pkg, nil
+		}
+		return nil, fmt.Errorf("package %s has no external test files", strings.TrimSuffix(path, "_test"))
+	}
+	defer d.timer.start("parse")()
+	p, err := d.importPackage(path, 0)
+	if err != nil {
+		return nil, err
+	}
+	// only the files matching the build constraints are parsed (as
+	// they are type checked together)
+	files := make(map[string]bool)
+	names := append(p.GoFiles, p.CgoFiles...)
+	if d.tests {
+		names = append(append(names, p.TestGoFiles...), p.XTestGoFiles...)
+	}
+	f := d.importFilters[path]
+	for _, name := range names {
+		files[name] = f.match(name)
+	}
+	filter := func(info fs.FileInfo) bool { return files[info.Name()] }
+	pkgs, err := parser.ParseDir(d.fset, p.Dir, filter, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var pkg, xtest *ast.Package
+	for name, p := range pkgs {
+		switch {
+		case d.tests && strings.HasSuffix(name, "_test") && len(pkgs) > 1:
+			xtest = p
+		case pkg != nil:
+			return nil, fmt.Errorf("more than one package in directory %s", path)
+		default:
+			pkg = p
+		}
+	}
+	if pkg == nil {
+		return nil, fmt.Errorf("package %s is empty", path)
+	}
+	d.calls.cache.Lock()
+	defer d.calls.cache.Unlock()
+	if p := d.packages[path]; p != nil {
+		// parsed concurrently by another call (whose package is used
+		// as it may be type checked already)
+		return p, nil
+	}
+	d.packages[path] = pkg
+	d.packageNames[path] = pkg.Name
+	if xtest != nil {
+		d.packages[path+"_test"] = xtest
+		d.packageNames[path+"_test"] = xtest.Name
+	}
+	return pkg, nil
+}
+
+var builtin = map[string]bool{
+	"bool":       true,
+	"byte":       true,
+	"complex128": true,
+	"complex64":  true,
+	"error":      true,
+	"float32":    true,
+	"float64":    true,
+	"int":        true,
+	"int16":      true,
+	"int32":      true,
+	"int64":      true,
+	"int8":       true,
+	"rune":       true,
+	"string":     true,
+	"uint":       true,
+	"uint16":     true,
+	"uint32":     true,
+	"uint64":     true,
+	"uint8":      true,
+	"uintptr":    true,
+}
+
+func (d *JSONDoc) typeLink(t ast.Expr, c *context, name string, suffix string) string {
+	switch t := t.(type) {
+	case *ast.ArrayType:
+		if !strings.HasSuffix(name, "-element") {
+			name = name + "-element"
+		}
+		return fmt.Sprintf("array%s of %s", suffix, d.typeLink(t.Elt, c, name, "s"))
+	case *ast.MapType:
+		ident, ok := t.Key.(*ast.Ident)
+		if !ok || ident.Name != "string" {
+			return "(error: only maps with string keys are supported)"
+		}
+		if !strings.HasSuffix(name, "-element") {
+			name = name + "-element"
+		}
+		return fmt.Sprintf("object%s of %s", suffix, d.typeLink(t.Value, c, name, "s"))
+	case *ast.StarExpr:
+		return d.typeLink(t.X, c, name, suffix)
+	case *ast.Ident:
+		if ts, c, ok := d.resolveType(t, c); ok && ts != nil {
+			if ID := d.renderLater(ts.Name.Name, nil, c); ID != "" {
+				return fmt.Sprintf(`<a href="#%s">%s</a>`, html.EscapeString(ID), html.EscapeString(t.Name))
+			}
+			return html.EscapeString(t.Name)
+		} else if ok {
+			// a predeclared type or a type parameter
+			return html.EscapeString(t.Name)
+		}
+		if ID := d.renderLater(t.Name, nil, c); ID != "" {
+			return fmt.Sprintf(`<a href="#%s">%s</a>`, html.EscapeString(ID), html.EscapeString(t.Name))
+		}
+		return html.EscapeString(t.Name)
+	case *ast.StructType:
+		if strings.HasSuffix(name, "-element") {
+			ID := d.renderLater(name, t, c)
+			return fmt.Sprintf(`<a href="#%s">%s</a>`, html.EscapeString(ID), html.EscapeString(name))
+		}
+		ID := d.renderLater("of "+name, t, c)
+		return fmt.Sprintf(`<a href="#%s">type of %s</a>`, html.EscapeString(ID), html.EscapeString(name))
+	case *ast.SelectorExpr:
+		ident, ok := t.X.(*ast.Ident)
+		if !ok {
+			d.warnf("type %v: expected identifier before '.'\n", t)
+			return html.EscapeString(fmt.Sprint(t))
+		}
+		if ts, c, ok := d.resolveType(t, c); ok && ts != nil {
+			if ID := d.renderLater(ts.Name.Name, nil, c); ID != "" {
+				return fmt.Sprintf(`<a href="#%s">%s</a>`, html.EscapeString(ID), html.EscapeString(t.Sel.Name))
+			}
+			return html.EscapeString(fmt.Sprintf("%s.%s", ident.Name, t.Sel.Name))
+		}
+		path, err := d.findImportIdent(c.File, ident.Name)
+		if err != nil {
+			d.warnf("type %s.%s: %v\n", ident.Name, t.Sel.Name, err)
+			return html.EscapeString(fmt.Sprintf("%s.%s", ident.Name, t.Sel.Name))
+		}
+		if d.stdType(t, c) || path == "C" {
+			// types of the standard library (and of cgo) are described in the "External types" chapter
+			return d.externalTypeLink(t, path)
+		}
+		pkg, err := d.parsedPackage(path)
+		if err != nil {
+			d.warnf("type %s.%s: %v\n", ident.Name, t.Sel.Name, err)
+			return d.externalTypeLink(t, path)
+		}
+		_, c, err := d.findObject(t.Sel.Name, pkg, path)
+		if err != nil {
+			d.warnf("type %s.%s: %v\n", ident.Name, t.Sel.Name, err)
+			return html.EscapeString(fmt.Sprintf("%s.%s", ident.Name, t.Sel.Name))
+		}
+		if ID := d.renderLater(t.Sel.Name, nil, c); ID != "" {
+			return fmt.Sprintf(`<a href="#%s">%s</a>`, html.EscapeString(ID), html.EscapeString(t.Sel.Name))
+		}
+		return html.EscapeString(fmt.Sprintf("%s.%s", ident.Name, t.Sel.Name))
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		// an instantiation of a generic type (such as Box[int]) links
+		// to the generic type
+		var args []string
+		for _, a := range typeArgs(t) {
+			args = append(args, d.typeLink(a, c, name, ""))
+		}
+		return fmt.Sprintf("%s[%s]", d.typeLink(genericType(t), c, name, suffix), strings.Join(args, ", "))
+	default:
+		return html.EscapeString(fmt.Sprint(t))
+	}
+}
+
+// findImportIdent returns the path of the package imported in the file
+// under the given name. Names of packages imported without an explicit
+// name are obtained from their package clauses. Packages which cannot
+// be found are assumed to be named after the last element of their
+// import path (so that the error is reported when the type is looked
+// up in the package).
+func (d *JSONDoc) findImportIdent(file *ast.File, name string) (string, error) {
+	var firstErr error
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return "", err
+		}
+		if imp.Name != nil {
+			if imp.Name.Name == name {
+				return path, nil
+			}
+			continue
+		}
+		if path == "C" {
+			// the pseudo-package of cgo
+			if name == "C" {
+				return path, nil
+			}
+			continue
+		}
+		s := d.packageName(path)
+		if s == "" {
+			p, err := d.importPackage(path, 0)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				if assumedPackageName(path) == name {
+					return path, nil
+				}
+				continue
+			}
+			s = p.Name
+			d.calls.cache.Lock()
+			d.packageNames[path] = s
+			d.calls.cache.Unlock()
+		}
+		if s == name {
+			return path, nil
+		}
+	}
+	if firstErr != nil {
+		return "", fmt.Errorf("package named %s not found (%v)", name, firstErr)
+	}
+	return "", fmt.Errorf("package named %s not imported", name)
+}
+
+// assumedPackageName returns the name of the package conventionally
+// used for the import path: its last element without a major version
+// suffix (such as /v2), "go-" prefix and ".go" suffix, up to the first
+// hyphen or dot.
+func assumedPackageName(path string) string {
+	elems := strings.Split(path, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = elems[len(elems)-2]
+	}
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "go-"), ".go")
+	if i := strings.IndexAny(name, "-."); i != -1 {
+		name = name[:i]
+	}
+	return name
+}
+
+func (d *JSONDoc) renderLater(name string, t ast.Expr, c *context) string {
+	if t != nil {
+		s := "type-" + strings.Replace(name, " ", "-", -1)
+		if d.links[s] == nil {
+			d.links[s] = make(map[ast.Expr]int)
+		}
+		if i, ok := d.links[s][t]; ok {
+			// the same expression reached by another path is
+			// already queued (or rendered)
+			s = fmt.Sprintf("%s-%d", s, i)
+			d.addEdge(d.graphParent, s)
+			return s
+		}
+		i := len(d.links[s]) + 1
+		d.links[s][t] = i
+		s = fmt.Sprintf("%s-%d", s, i)
+		d.renderQueue = append(d.renderQueue, queueElem{&ast.TypeSpec{Name: &ast.Ident{Name: name}, Type: t}, c, s, false, d.owner})
+		d.addEdge(d.graphParent, s)
+		return s
+	}
+	o, c, err := d.findObject(name, c.Package, c.Path)
+	if err != nil {
+		d.warnf("%v\n", err)
+		return ""
+	}
+	if o == nil {
+		return ""
+	}
+	if s := d.rendered[renderedElem{name, o}]; s != "" {
+		d.addTypeRef(s)
+		d.addEdge(d.graphParent, s)
+		return s
+	}
+	if t, ok := o.Decl.(*ast.TypeSpec); ok {
+		s := "type-" + strings.Replace(d.qualifiedName(name, c), ".", "-", -1)
+		if d.links[s] == nil {
+			d.links[s] = make(map[ast.Expr]int)
+		}
+		i := len(d.links[s]) + 1
+		d.links[s][t.Type] = i
+		s = fmt.Sprintf("%s-%d", s, i)
+		d.renderQueue = append(d.renderQueue, queueElem{t, c, s, true, d.owner})
+		d.rendered[renderedElem{name, o}] = s
+		d.namedTypes = append(d.namedTypes, namedType{name, c.Package.Name, s})
+		d.addTypeRef(s)
+		d.addEdge(d.graphParent, s)
+		return s
+	}
+	return ""
+}
+
+var NotExported = errors.New("Not exported")
+
+func (d *JSONDoc) tagToName(name string, tag *ast.BasicLit) (string, error) {
+	key, omitempty, err := d.jsonKey(name, tag)
+	if err != nil {
+		return "", err
+	}
+	if omitempty {
+		return strconv.Quote(key) + " (optional)", nil
+	}
+	return strconv.Quote(key), nil
+}
+
+// tagKey returns the object key of the struct field with the given
+// name in the struct tag with the given key (such as "form" or
+// "msgpack") and whether it is marked with omitempty (or omitzero). It
+// returns NotExported for fields not present in the encoding.
+func tagKey(name string, tag *ast.BasicLit, tagName string) (key string, omitempty bool, err error) {
+	if !ast.IsExported(name) {
+		return "", false, NotExported
+	}
+	if tag == nil {
+		return name, false, nil
+	}
+	s, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return "", false, err
+	}
+	s = reflect.StructTag(s).Get(tagName)
+	if s == "" {
+		return name, false, nil
+	}
+	fields := strings.Split(s, ",")
+	if fields[0] == "-" {
+		return "", false, NotExported
+	}
+	for _, f := range fields[1:] {
+		if f == "omitempty" || f == "omitzero" {
+			omitempty = true
+		}
+	}
+	if fields[0] != "" {
+		name = fields[0]
+	}
+	return name, omitempty, nil
+}
+
+// omitsEmptyAndZero reports whether the struct tag with the given key
+// has both the omitempty and the omitzero options (the field is then
+// omitted if it is empty or if it is zero).
+func omitsEmptyAndZero(tag *ast.BasicLit, tagName string) bool {
+	if tag == nil {
+		return false
+	}
+	s, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return false
+	}
+	var empty, zero bool
+	for _, f := range strings.Split(reflect.StructTag(s).Get(tagName), ",")[1:] {
+		empty = empty || f == "omitempty"
+		zero = zero || f == "omitzero"
+	}
+	return empty && zero
+}
+
+var isASCIIPunctuation [128]bool
+
+func init() {
+	for _, c := range "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~." {
+		isASCIIPunctuation[c] = true
+	}
+}
+
+func markdownEscapeString(s string) string {
+	var b bytes.Buffer
+	for _, c := range s {
+		if c < 128 && isASCIIPunctuation[c] {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package jsondoc
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// newTestDoc returns the documentation of the template documenting the
+// package with the given source (imported as ".").
+func newTestDoc(t *testing.T, src, tmpl string, opts Options) *JSONDoc {
+	t.Helper()
+	d, _ := newTestPackageDoc(t, src, tmpl, opts)
+	return d
+}
+
+// newTestPackageDoc is like newTestDoc but also returns the directory
+// of the package (api.go) and of the template (api.md).
+func newTestPackageDoc(t *testing.T, src, tmpl string, opts Options) (*JSONDoc, string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "api.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "api.md")
+	if err := os.WriteFile(filename, []byte(tmpl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opts.Package = dir
+	d, err := New(filename, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d, dir
+}
+
+// renderMarkdown returns the markdown document of the template
+// documenting the package with the given source.
+func renderMarkdown(t *testing.T, src, tmpl string) string {
+	t.Helper()
+	var b bytes.Buffer
+	if err := newTestDoc(t, src, tmpl, Options{}).WriteMarkdown(&b); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+// runGo runs the go command with the arguments in the directory of a
+// test package (adding a go.mod file to it). The test is skipped in the
+// short mode or if there is no go command.
+func runGo(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/api\n\ngo 1.21\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(goCmd, args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go %v: %v\n%s", args, err, out)
+	}
+}
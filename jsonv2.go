@@ -0,0 +1,145 @@
+package jsondoc
+
+import (
+	"go/ast"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// With -jsonv2 the json struct tags are interpreted as by
+// encoding/json/v2: the key may be given as a single-quoted string (to
+// contain commas or quotes), keys are matched case-sensitively unless
+// the field has the case:ignore option, the fields of the fields with
+// the inline (or unknown) option are inlined in the enclosing object
+// and the format option gives the representation of the value.
+
+// jsonOptions are the key and the options of the json tag of a field.
+type jsonOptions struct {
+	Key        string
+	OmitEmpty  bool
+	OmitZero   bool
+	Inline     bool   // inline or unknown option (with -jsonv2)
+	CaseIgnore bool   // case:ignore or nocase option (with -jsonv2)
+	Format     string // format option (with -jsonv2)
+}
+
+// jsonTag returns the key and the options of the json tag of the
+// struct field with the given name (with the syntax and the options of
+// encoding/json/v2 with -jsonv2). It returns NotExported for fields not
+// present in JSON.
+func (d *JSONDoc) jsonTag(name string, tag *ast.BasicLit) (jsonOptions, error) {
+	o := jsonOptions{Key: name}
+	if !ast.IsExported(name) {
+		return o, NotExported
+	}
+	if tag == nil {
+		return o, nil
+	}
+	s, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return o, err
+	}
+	s = reflect.StructTag(s).Get("json")
+	if s == "" {
+		return o, nil
+	}
+	key, opts := s, ""
+	if i := strings.IndexByte(s, ','); i != -1 {
+		key, opts = s[:i], s[i+1:]
+	}
+	if d.jsonv2 && strings.HasPrefix(s, "'") {
+		var n int
+		if key, n, err = unquoteSingle(s); err != nil {
+			return o, err
+		}
+		opts = strings.TrimPrefix(s[n:], ",")
+	} else if s == "-" {
+		return o, NotExported // (but "-," is the key "-")
+	}
+	if key != "" {
+		o.Key = key
+	}
+	for _, opt := range strings.Split(opts, ",") {
+		switch {
+		case opt == "omitempty":
+			o.OmitEmpty = true
+		case opt == "omitzero":
+			o.OmitZero = true
+		case !d.jsonv2:
+		case opt == "inline" || opt == "unknown":
+			o.Inline = true
+		case opt == "case:ignore" || opt == "nocase":
+			o.CaseIgnore = true
+		case strings.HasPrefix(opt, "format:"):
+			o.Format = strings.TrimPrefix(opt, "format:")
+		}
+	}
+	return o, nil
+}
+
+// unquoteSingle returns the single-quoted string (with the escapes of
+// Go string literals) at the beginning of s and its length in s.
+func unquoteSingle(s string) (string, int, error) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '\'':
+			q := strings.Replace(strings.Replace(s[1:i], `\'`, "'", -1), `"`, `\"`, -1)
+			u, err := strconv.Unquote(`"` + q + `"`)
+			return u, i + 1, err
+		}
+	}
+	return "", 0, strconv.ErrSyntax
+}
+
+// jsonKey returns the JSON object key of the struct field with the
+// given name and tag and whether it is marked with omitempty (or
+// omitzero, supported by encoding/json since Go 1.24). It returns
+// NotExported for fields not present in JSON as members (also for the
+// fields inlined with -jsonv2, see inlined).
+func (d *JSONDoc) jsonKey(name string, tag *ast.BasicLit) (key string, omitempty bool, err error) {
+	o, err := d.jsonTag(name, tag)
+	if err != nil {
+		return "", false, err
+	}
+	if o.Inline {
+		return "", false, NotExported
+	}
+	return o.Key, o.OmitEmpty || o.OmitZero, nil
+}
+
+// inlined returns the type of the struct field the fields of which are
+// inlined in the enclosing object: an embedded field or, with -jsonv2,
+// a field (of a struct type) with the inline option. It returns nil for
+// other fields.
+func (d *JSONDoc) inlined(f *ast.Field) ast.Expr {
+	if len(f.Names) == 0 {
+		return f.Type
+	}
+	if d.inlinedMap(f) != nil {
+		return nil
+	}
+	if o, err := d.jsonTag(f.Names[0].Name, f.Tag); err == nil && o.Inline {
+		return f.Type
+	}
+	return nil
+}
+
+// inlinedMap returns the map type of the field with the inline (or
+// unknown) option holding the members of the enclosing object other
+// than its fields (with -jsonv2, otherwise nil).
+func (d *JSONDoc) inlinedMap(f *ast.Field) *ast.MapType {
+	if !d.jsonv2 || len(f.Names) == 0 {
+		return nil
+	}
+	m, ok := f.Type.(*ast.MapType)
+	if !ok {
+		return nil
+	}
+	if o, err := d.jsonTag(f.Names[0].Name, f.Tag); err == nil && o.Inline {
+		return m
+	}
+	return nil
+}
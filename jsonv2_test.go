@@ -0,0 +1,84 @@
+package jsondoc
+
+import (
+	"go/ast"
+	"strings"
+	"testing"
+)
+
+func TestJSONTag(t *testing.T) {
+	for _, c := range []struct {
+		jsonv2 bool
+		tag    string
+		want   jsonOptions
+		err    error
+	}{
+		{false, `json:"a,omitempty"`, jsonOptions{Key: "a", OmitEmpty: true}, nil},
+		{false, `json:",omitzero"`, jsonOptions{Key: "Name", OmitZero: true}, nil},
+		{false, `json:"-"`, jsonOptions{Key: "Name"}, NotExported},
+		{false, `json:"-,"`, jsonOptions{Key: "-"}, nil},
+		{false, `json:"a,inline,format:RFC3339"`, jsonOptions{Key: "a"}, nil},
+		{false, `json:"'a,b'"`, jsonOptions{Key: "'a"}, nil},
+		{false, `xml:"a"`, jsonOptions{Key: "Name"}, nil},
+		{true, `json:"'a,b',omitempty"`, jsonOptions{Key: "a,b", OmitEmpty: true}, nil},
+		{true, `json:"'it\\'s \"x\"'"`, jsonOptions{Key: `it's "x"`}, nil},
+		{true, `json:"'-'"`, jsonOptions{Key: "-"}, nil},
+		{true, `json:",inline"`, jsonOptions{Key: "Name", Inline: true}, nil},
+		{true, `json:",unknown"`, jsonOptions{Key: "Name", Inline: true}, nil},
+		{true, `json:"a,case:ignore"`, jsonOptions{Key: "a", CaseIgnore: true}, nil},
+		{true, `json:"a,nocase,format:RFC3339"`, jsonOptions{Key: "a", CaseIgnore: true, Format: "RFC3339"}, nil},
+	} {
+		d := newTestDoc(t, "package api\n", "# API\n", Options{JSONv2: c.jsonv2})
+		o, err := d.jsonTag("Name", &ast.BasicLit{Value: "`" + c.tag + "`"})
+		if o != c.want || err != c.err {
+			t.Errorf("jsonv2 %t, %s: got %+v, %v, want %+v, %v", c.jsonv2, c.tag, o, err, c.want, c.err)
+		}
+	}
+	d := newTestDoc(t, "package api\n", "# API\n", Options{JSONv2: true})
+	if _, err := d.jsonTag("Name", &ast.BasicLit{Value: "`json:\"'a\"`"}); err == nil {
+		t.Error("got no error for an unterminated quoted key")
+	}
+}
+
+const jsonv2Src = `package api
+
+import "time"
+
+type Item struct {
+	Key     string            ` + "`json:\"'a,b'\"`" + `
+	Name    string            ` + "`json:\"name,case:ignore\"`" + ` // name of the item
+	Created time.Time         ` + "`json:\"created,format:unix\"`" + `
+	Meta    Meta              ` + "`json:\",inline\"`" + `
+	Extra   map[string]string ` + "`json:\",unknown\"`" + ` // other members
+}
+
+type Meta struct {
+	Owner string ` + "`json:\"owner\"`" + `
+}
+`
+
+func TestRenderJSONv2(t *testing.T) {
+	tmpl := "{{endpoint \"GET\" \"/item\"}}\n\n{{output \"Item\"}}\n"
+	d := newTestDoc(t, jsonv2Src, tmpl, Options{JSONv2: true})
+	var b strings.Builder
+	if err := d.WriteMarkdown(&b); err != nil {
+		t.Fatal(err)
+	}
+	md := b.String()
+	for _, s := range []string{
+		"<td>&#34;a,b&#34;</td>",
+		"<td>name of the item<br>The key is matched ignoring case.</td>",
+		"<br>format: unix</td>",
+		"<td>&#34;owner&#34;</td>",
+		"<td>any other key</td>",
+	} {
+		if !strings.Contains(md, s) {
+			t.Errorf("%q not found in\n%s", s, md)
+		}
+	}
+	for _, s := range []string{"Meta", "Extra", "&#34;Meta&#34;"} {
+		if strings.Contains(md, "<td>&#34;"+s+"&#34;</td>") {
+			t.Errorf("inlined field %s rendered as a member in\n%s", s, md)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package jsondoc
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// fieldLayouts are the presentations of the fields of the documented
+// objects (selected with -layout or the layout action).
+var fieldLayouts = map[string]bool{
+	"table":     true, // a table of key names, value types and descriptions
+	"annotated": true, // a JSON skeleton with a comment per field
+	"list":      true, // a definition list (for narrow layouts)
+}
+
+// layout sets the presentation of the fields of the types rendered by
+// the following actions (the one given with Options.Layout if name is
+// not given).
+func (d *JSONDoc) layout(name ...string) (string, error) {
+	switch {
+	case len(name) == 0:
+		d.fieldLayout = d.defaultLayout
+	case len(name) == 1 && fieldLayouts[name[0]]:
+		d.fieldLayout = name[0]
+	default:
+		return "", fmt.Errorf("layout: expected one of %s", strings.Join(layoutNames(), ", "))
+	}
+	return "", nil
+}
+
+func layoutNames() []string {
+	var a []string
+	for name := range fieldLayouts {
+		a = append(a, name)
+	}
+	sort.Strings(a)
+	return a
+}
+
+// renderFields renders the fields of an object (prefixed with the
+// containers, such as "array of ", see renderType1) in the current
+// layout.
+func (d *JSONDoc) renderFields(fields []field, prefix, s string) error {
+	switch d.fieldLayout {
+	case "annotated":
+		fmt.Fprintf(&d.b, "<p>%s %sobject%s with the following fields:</p>\n", d.format(), prefix, s)
+		d.writeAnnotated(fields)
+		return nil
+	}
+	type data struct {
+		Format, Prefix, S, Caption string
+		Fields                     []field
+	}
+	name := "table"
+	if d.fieldLayout == "list" {
+		name = "list"
+	}
+	return d.table.ExecuteTemplate(&d.b, name, data{d.format(), prefix, s, "type " + html.EscapeString(d.typeName), fields})
+}
+
+// skeletonValues are the placeholders of the values of the given kinds
+// (see valueKind) in the JSON skeleton.
+var skeletonValues = map[string]string{
+	"string":  `"…"`,
+	"number":  "0",
+	"boolean": "false",
+	"array":   "[…]",
+	"object":  "{…}",
+	"":        "…",
+}
+
+// writeAnnotated writes the object as a JSON skeleton with the value
+// type and the description of each field in a comment aligned after
+// it.
+func (d *JSONDoc) writeAnnotated(fields []field) {
+	lines := make([]string, len(fields))
+	width := 0
+	for i, f := range fields {
+		name := strings.TrimSuffix(f.Name, " (optional)")
+		lines[i] = "  " + name + ": " + skeletonValues[f.kind]
+		if i < len(fields)-1 {
+			lines[i] += ","
+		}
+		if n := utf8.RuneCountInString(html.UnescapeString(lines[i])); n > width {
+			width = n
+		}
+	}
+	d.b.WriteString("<pre class=\"annotated\"><code>{\n")
+	for i, f := range fields {
+		comment := strings.Replace(f.Type, "<br>", ", ", -1)
+		if f.Name != strings.TrimSuffix(f.Name, " (optional)") {
+			comment += ", optional"
+		}
+		if f.Description != "" {
+			comment += " - " + strings.Replace(f.Description, "<br>", "; ", -1)
+		}
+		comment = strings.Replace(comment, "\n", " ", -1)
+		pad := width - utf8.RuneCountInString(html.UnescapeString(lines[i]))
+		fmt.Fprintf(&d.b, "%s%s  <span class=\"comment\">// %s</span>\n", lines[i], strings.Repeat(" ", pad), comment)
+	}
+	d.b.WriteString("}</code></pre>\n")
+}
@@ -0,0 +1,138 @@
+package jsondoc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// locale are the conventions of the audience of the documentation
+// used for fake values in samples and for numbers and dates in prose.
+// Values in samples which have a defined format (such as RFC 3339
+// timestamps and JSON numbers) do not depend on the locale.
+type locale struct {
+	FirstNames, LastNames []string
+	Cities, Countries     []string
+	Streets               []string
+	Address               string // format of the address with the house number and the street
+	Phone                 string // phone number with # standing for random digits
+	Postal                string // postal code with # standing for random digits
+	DecimalSeparator      string // decimal separator of numbers in prose
+	DateTime              string // layout of the date and time in prose
+	Date                  string // layout of the date in prose
+}
+
+// defaultLocale is used if no locale is configured.
+var defaultLocale = &locale{
+	FirstNames: []string{"Alice", "Bob", "Carol", "Dave", "Eve", "Frank", "Grace", "Heidi", "Ivan", "Judy"},
+	LastNames:  []string{"Smith", "Johnson", "Brown", "Taylor", "Miller", "Davis", "Wilson", "Moore", "Clark", "Lewis"},
+	Cities:     []string{"London", "Paris", "Berlin", "Warsaw", "Madrid", "Rome", "Vienna", "Prague", "Oslo", "Lisbon"},
+	Countries:  []string{"GB", "FR", "DE", "PL", "ES", "IT", "AT", "CZ", "NO", "PT"},
+	Streets:    []string{"Main Street", "High Street", "Park Avenue", "Oak Lane", "Mill Road", "Church Street"},
+	Address:    "%d %s", Phone: "+1-555-###-####", Postal: "#####",
+	DecimalSeparator: ".", DateTime: "2006-01-02 15:04 MST", Date: "2006-01-02",
+}
+
+// locales are the supported locales (map: BCP 47 language tag ->
+// locale).
+var locales = map[string]*locale{
+	"en-US": {
+		FirstNames: []string{"James", "Mary", "Robert", "Patricia", "John", "Jennifer", "Michael", "Linda", "David", "Susan"},
+		LastNames:  []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Wilson", "Moore"},
+		Cities:     []string{"New York", "Chicago", "Houston", "Phoenix", "Seattle", "Denver", "Boston", "Atlanta", "Austin", "Portland"},
+		Countries:  []string{"US"},
+		Streets:    []string{"Main Street", "Park Avenue", "Oak Street", "Maple Avenue", "Elm Street", "Washington Street"},
+		Address:    "%d %s", Phone: "+1-555-###-####", Postal: "#####",
+		DecimalSeparator: ".", DateTime: "01/02/2006 3:04 PM MST", Date: "01/02/2006",
+	},
+	"en-GB": {
+		FirstNames: []string{"Oliver", "Amelia", "George", "Isla", "Harry", "Ava", "Jack", "Emily", "Charlie", "Sophie"},
+		LastNames:  []string{"Smith", "Jones", "Taylor", "Brown", "Williams", "Wilson", "Davies", "Evans", "Thomas", "Roberts"},
+		Cities:     []string{"London", "Manchester", "Birmingham", "Leeds", "Glasgow", "Bristol", "Edinburgh", "Cardiff", "York", "Oxford"},
+		Countries:  []string{"GB"},
+		Streets:    []string{"High Street", "Station Road", "Church Lane", "Victoria Road", "Mill Lane", "King Street"},
+		Address:    "%d %s", Phone: "+44 20 #### ####", Postal: "SW# #AA",
+		DecimalSeparator: ".", DateTime: "02/01/2006 15:04 MST", Date: "02/01/2006",
+	},
+	"de-DE": {
+		FirstNames: []string{"Anna", "Lukas", "Lena", "Jonas", "Marie", "Felix", "Sophie", "Paul", "Emma", "Leon"},
+		LastNames:  []string{"Schmidt", "Schneider", "Fischer", "Weber", "Meyer", "Wagner", "Becker", "Schulz", "Hoffmann", "Koch"},
+		Cities:     []string{"Berlin", "Hamburg", "München", "Köln", "Frankfurt am Main", "Stuttgart", "Düsseldorf", "Leipzig", "Dresden", "Bremen"},
+		Countries:  []string{"DE"},
+		Streets:    []string{"Hauptstraße", "Schulstraße", "Gartenstraße", "Bahnhofstraße", "Dorfstraße", "Bergstraße"},
+		Address:    "%[2]s %[1]d", Phone: "+49 30 ########", Postal: "#####",
+		DecimalSeparator: ",", DateTime: "02.01.2006 15:04 MST", Date: "02.01.2006",
+	},
+	"fr-FR": {
+		FirstNames: []string{"Camille", "Louis", "Chloé", "Gabriel", "Léa", "Hugo", "Manon", "Jules", "Inès", "Lucas"},
+		LastNames:  []string{"Martin", "Bernard", "Dubois", "Thomas", "Robert", "Richard", "Petit", "Durand", "Leroy", "Moreau"},
+		Cities:     []string{"Paris", "Marseille", "Lyon", "Toulouse", "Nice", "Nantes", "Strasbourg", "Montpellier", "Bordeaux", "Lille"},
+		Countries:  []string{"FR"},
+		Streets:    []string{"rue de la Paix", "rue Victor Hugo", "avenue Jean Jaurès", "rue Pasteur", "boulevard Voltaire", "place de la République"},
+		Address:    "%d %s", Phone: "+33 1 ## ## ## ##", Postal: "75###",
+		DecimalSeparator: ",", DateTime: "02/01/2006 15:04 MST", Date: "02/01/2006",
+	},
+	"pl-PL": {
+		FirstNames: []string{"Anna", "Piotr", "Katarzyna", "Tomasz", "Agnieszka", "Marcin", "Magdalena", "Jakub", "Ewa", "Adam"},
+		LastNames:  []string{"Nowak", "Kowalski", "Mazur", "Krawczyk", "Kaczmarek", "Adamczyk", "Dudek", "Pawlak", "Michalski", "Sikora"},
+		Cities:     []string{"Warszawa", "Kraków", "Łódź", "Wrocław", "Poznań", "Gdańsk", "Szczecin", "Lublin", "Katowice", "Toruń"},
+		Countries:  []string{"PL"},
+		Streets:    []string{"ul. Polna", "ul. Leśna", "ul. Słoneczna", "ul. Krótka", "ul. Szkolna", "ul. Ogrodowa"},
+		Address:    "%[2]s %[1]d", Phone: "+48 ### ### ###", Postal: "##-###",
+		DecimalSeparator: ",", DateTime: "02.01.2006 15:04 MST", Date: "02.01.2006",
+	},
+	"es-ES": {
+		FirstNames: []string{"Lucía", "Hugo", "Sofía", "Martín", "María", "Pablo", "Paula", "Daniel", "Julia", "Alejandro"},
+		LastNames:  []string{"García", "Rodríguez", "González", "Fernández", "López", "Martínez", "Sánchez", "Pérez", "Gómez", "Martín"},
+		Cities:     []string{"Madrid", "Barcelona", "Valencia", "Sevilla", "Zaragoza", "Málaga", "Murcia", "Palma", "Bilbao", "Alicante"},
+		Countries:  []string{"ES"},
+		Streets:    []string{"Calle Mayor", "Calle Real", "Calle de Alcalá", "Gran Vía", "Calle del Sol", "Paseo del Prado"},
+		Address:    "%[2]s %[1]d", Phone: "+34 91 ### ## ##", Postal: "28###",
+		DecimalSeparator: ",", DateTime: "02/01/2006 15:04 MST", Date: "02/01/2006",
+	},
+}
+
+// locale returns the configured locale.
+func (d *JSONDoc) locale() *locale {
+	if l := locales[d.config.Locale]; l != nil {
+		return l
+	}
+	return defaultLocale
+}
+
+// localeNames returns the sorted names of the supported locales.
+func localeNames() []string {
+	var names []string
+	for name := range locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fakeDigits returns the pattern with each run of # replaced by random
+// digits.
+func (d *JSONDoc) fakeDigits(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); {
+		if pattern[i] != '#' {
+			b.WriteByte(pattern[i])
+			i++
+			continue
+		}
+		n, max := 0, 1
+		for ; i < len(pattern) && pattern[i] == '#'; i++ {
+			n++
+			max *= 10
+		}
+		fmt.Fprintf(&b, "%0*d", n, d.rand.Intn(max))
+	}
+	return b.String()
+}
+
+// asciiReplacer replaces the letters with diacritics of the supported
+// locales with ASCII letters (for the fake e-mail addresses and user
+// names).
+var asciiReplacer = strings.NewReplacer("á", "a", "à", "a", "â", "a", "ą", "a", "ć", "c", "ç", "c", "é", "e", "è", "e", "ê", "e",
+	"ë", "e", "ę", "e", "í", "i", "î", "i", "ï", "i", "ł", "l", "ń", "n", "ñ", "n", "ó", "o", "ô", "o", "ö", "o", "ś", "s",
+	"ß", "ss", "ú", "u", "û", "u", "ü", "u", "ź", "z", "ż", "z")
@@ -0,0 +1,289 @@
+package jsondoc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/russross/blackfriday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Markdown engines (selected with -engine).
+const (
+	engineGoldmark    = "goldmark"    // CommonMark (the default)
+	engineBlackfriday = "blackfriday" // blackfriday v1 (for compatibility)
+)
+
+// markdownExtensions maps the names of markdown extensions (as used in
+// the configuration) to the goldmark extensions.
+var markdownExtensions = map[string]goldmark.Extender{
+	"table":          extension.Table,
+	"strikethrough":  extension.Strikethrough,
+	"linkify":        extension.Linkify,
+	"definitionList": extension.DefinitionList,
+	"taskList":       extension.TaskList,
+	"footnote":       extension.Footnote,
+	"typographer":    extension.Typographer,
+}
+
+// defaultMarkdownExtensions lists the markdown extensions used if not
+// configured otherwise (the ones also used with blackfriday).
+var defaultMarkdownExtensions = []string{"table", "strikethrough", "linkify", "definitionList", "footnote"}
+
+const htmlFlags = blackfriday.HTML_TOC
+
+const commonExtensions = 0 |
+	blackfriday.EXTENSION_NO_INTRA_EMPHASIS |
+	blackfriday.EXTENSION_TABLES |
+	blackfriday.EXTENSION_FENCED_CODE |
+	blackfriday.EXTENSION_AUTOLINK |
+	blackfriday.EXTENSION_STRIKETHROUGH |
+	blackfriday.EXTENSION_SPACE_HEADERS |
+	blackfriday.EXTENSION_HEADER_IDS |
+	blackfriday.EXTENSION_BACKSLASH_LINE_BREAK |
+	blackfriday.EXTENSION_DEFINITION_LISTS |
+	blackfriday.EXTENSION_FOOTNOTES
+
+// htmlBody is the markdown document parsed so that its HTML is written
+// in chunks (the top-level blocks) and the HTML of the whole document
+// is never held in memory (except with blackfriday which renders the
+// whole document at once).
+type htmlBody struct {
+	source  []byte
+	gm      goldmark.Markdown
+	doc     ast.Node
+	html    []byte // the whole HTML (with blackfriday)
+	toc     []byte // table of contents
+	mermaid bool   // there are mermaid diagrams
+}
+
+// parseMarkdown parses the markdown document collecting its table of
+// contents and finding the mermaid diagrams (the goldmark document is
+// only rendered when written).
+func (d *JSONDoc) parseMarkdown(md []byte) (*htmlBody, error) {
+	if d.engine == engineBlackfriday {
+		out := blackfriday.Markdown(md, anchorRenderer{blackfriday.HtmlRenderer(htmlFlags, "", "")}, commonExtensions)
+		body := &htmlBody{html: out}
+		if bytes.HasPrefix(out, []byte("<nav>")) {
+			i := bytes.Index(out, []byte("</nav>")) + len("</nav>")
+			body.toc, body.html = out[:i], out[i:]
+		}
+		body.mermaid = mermaidRe.Match(out)
+		return body, nil
+	}
+	names := d.config.MarkdownExtensions
+	if names == nil {
+		names = defaultMarkdownExtensions
+	}
+	var exts []goldmark.Extender
+	for _, name := range names {
+		exts = append(exts, markdownExtensions[name])
+	}
+	gm := goldmark.New(goldmark.WithExtensions(exts...),
+		goldmark.WithParserOptions(parser.WithAttribute(), parser.WithAutoHeadingID()),
+		goldmark.WithRendererOptions(html.WithUnsafe(),
+			renderer.WithNodeRenderers(util.Prioritized(headingRenderer{}, 100))))
+	body := &htmlBody{source: md, gm: gm, doc: gm.Parser().Parse(text.NewReader(md))}
+	headings, err := body.scan(d.headingLevel() + 1)
+	if err != nil {
+		return nil, err
+	}
+	body.toc = tableOfContents(headings)
+	return body, nil
+}
+
+// scan returns the headings of the goldmark document with ids up to
+// the given level (the input and output sections, not the types) and
+// notes whether there are mermaid diagrams.
+func (b *htmlBody) scan(maxLevel int) ([]tocHeading, error) {
+	var headings []tocHeading
+	var title bytes.Buffer
+	r := b.gm.Renderer()
+	err := ast.Walk(b.doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Heading:
+			v, _ := n.AttributeString("id")
+			id, _ := v.([]byte)
+			if len(id) == 0 || n.Level > maxLevel {
+				return ast.WalkSkipChildren, nil
+			}
+			title.Reset()
+			for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+				if err := r.Render(&title, b.source, c); err != nil {
+					return ast.WalkStop, err
+				}
+			}
+			headings = append(headings, tocHeading{n.Level, string(util.EscapeHTML(id)), title.String()})
+			return ast.WalkSkipChildren, nil
+		case *ast.HTMLBlock:
+			// the headings of the types (such as of the types action)
+			for i := 0; i < n.Lines().Len(); i++ {
+				line := n.Lines().At(i)
+				headings = appendHeadings(headings, line.Value(b.source), maxLevel)
+			}
+		case *ast.FencedCodeBlock:
+			b.mermaid = b.mermaid || string(n.Language(b.source)) == "mermaid"
+		}
+		return ast.WalkContinue, nil
+	})
+	return headings, err
+}
+
+// render calls f with the HTML of the consecutive chunks of the
+// document (with the admonitions, see addAdmonitions). The chunk is
+// only valid until f returns.
+func (b *htmlBody) render(f func(chunk []byte) error) error {
+	if b.doc == nil {
+		return f(addAdmonitions(b.html))
+	}
+	var buf bytes.Buffer
+	r := b.gm.Renderer()
+	for n := b.doc.FirstChild(); n != nil; n = n.NextSibling() {
+		buf.Reset()
+		if err := r.Render(&buf, b.source, n); err != nil {
+			return err
+		}
+		if err := f(addAdmonitions(buf.Bytes())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTo writes the table of contents and the document (with the
+// mermaid diagrams) wrapped in the landmarks (see
+// addLandmarks), minified if requested.
+func (b *htmlBody) writeTo(w io.Writer, minify bool) (int64, error) {
+	var n int64
+	write := func(p []byte) error {
+		if minify {
+			p = []byte(minifyHTML(string(p)))
+		}
+		m, err := w.Write(p)
+		n += int64(m)
+		return err
+	}
+	if err := write(landmarksStart(b.toc)); err != nil {
+		return n, err
+	}
+	first := true
+	var pending []byte // the chunks ending in an unclosed raw element
+	err := b.render(func(chunk []byte) error {
+		if first {
+			chunk = bytes.TrimLeft(chunk, "\n")
+			first = false
+		}
+		chunk, _ = addMermaid(chunk)
+		if minify {
+			// the raw elements (such as pre) spanning chunks are
+			// minified as a whole (see minifyHTML)
+			if pending != nil {
+				chunk, pending = append(pending, chunk...), nil
+			}
+			if hasUnclosedRawElem(chunk) {
+				pending = append([]byte(nil), chunk...)
+				return nil
+			}
+		}
+		return write(chunk)
+	})
+	if err == nil && pending != nil {
+		err = write(pending)
+	}
+	if err == nil {
+		err = write([]byte(landmarksEnd))
+	}
+	return n, err
+}
+
+// admonitionRe matches block quotes starting with [!NOTE], [!WARNING]
+// or [!DANGER].
+var admonitionRe = regexp.MustCompile(`(?s)<blockquote>\s*<p>\[!(NOTE|WARNING|DANGER)\]\s*(.*?)</blockquote>`)
+
+// addAdmonitions replaces block quotes starting with [!NOTE],
+// [!WARNING] or [!DANGER] with admonition blocks (styled according to
+// their kind).
+func addAdmonitions(out []byte) []byte {
+	return admonitionRe.ReplaceAllFunc(out, func(b []byte) []byte {
+		m := admonitionRe.FindSubmatch(b)
+		kind := strings.ToLower(string(m[1]))
+		title := strings.ToUpper(kind[:1]) + kind[1:]
+		body := m[2]
+		if bytes.HasPrefix(body, []byte("</p>")) {
+			body = bytes.TrimLeft(body[len("</p>"):], "\n")
+		} else {
+			body = append([]byte("<p>"), body...)
+		}
+		return []byte(fmt.Sprintf("<div class=\"admonition %s\">\n<p class=\"admonition-title\">%s</p>\n%s</div>", kind, title, body))
+	})
+}
+
+// tocHeadingRe matches the headings of the types rendered as HTML
+// (without their permalinks, see writeHeading).
+var tocHeadingRe = regexp.MustCompile(`<h([1-6]) id="([^"]*)">(.*?)(?: <a class="anchor"[^>]*>¶</a>)?</h[1-6]>`)
+
+// tocHeading is a heading in the table of contents.
+type tocHeading struct {
+	level     int
+	id, title string // HTML
+}
+
+// appendHeadings appends the headings of the types in the HTML up to
+// the given level.
+func appendHeadings(headings []tocHeading, out []byte, maxLevel int) []tocHeading {
+	for _, m := range tocHeadingRe.FindAllSubmatch(out, -1) {
+		level, _ := strconv.Atoi(string(m[1]))
+		if level <= maxLevel {
+			headings = append(headings, tocHeading{level, string(m[2]), string(m[3])})
+		}
+	}
+	return headings
+}
+
+// tableOfContents returns the table of contents (nested lists of links
+// as generated by blackfriday) of the headings.
+func tableOfContents(headings []tocHeading) []byte {
+	var b bytes.Buffer
+	b.WriteString("<nav>\n")
+	var levels []int // levels of the open lists
+	for _, h := range headings {
+		switch {
+		case len(levels) == 0 || h.level > levels[len(levels)-1]:
+			if len(levels) > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString("<ul>\n")
+			levels = append(levels, h.level)
+		default:
+			b.WriteString("</li>\n")
+			for len(levels) > 1 && h.level < levels[len(levels)-1] {
+				levels = levels[:len(levels)-1]
+				b.WriteString("</ul></li>\n")
+			}
+		}
+		fmt.Fprintf(&b, `<li><a href="#%s">%s</a>`, h.id, h.title)
+	}
+	if len(levels) > 0 {
+		b.WriteString("</li>\n")
+		for i := len(levels) - 1; i > 0; i-- {
+			b.WriteString("</ul></li>\n")
+		}
+		b.WriteString("</ul>\n")
+	}
+	b.WriteString("</nav>\n\n")
+	return b.Bytes()
+}
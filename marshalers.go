@@ -0,0 +1,74 @@
+package jsondoc
+
+import "go/ast"
+
+// receiver describes a method of a named type: whether it is declared
+// and whether it has a pointer receiver.
+type receiver struct {
+	declared, pointer bool
+}
+
+// methodReceiver returns the receiver of the method of the named type
+// declared in the package.
+func methodReceiver(pkg *ast.Package, typeName, method string) receiver {
+	for _, f := range pkg.Files {
+		for _, decl := range f.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Recv == nil || len(fd.Recv.List) != 1 || fd.Name.Name != method {
+				continue
+			}
+			t, pointer := fd.Recv.List[0].Type, false
+			if s, ok := t.(*ast.StarExpr); ok {
+				t, pointer = s.X, true
+			}
+			switch x := t.(type) {
+			case *ast.IndexExpr:
+				t = x.X
+			case *ast.IndexListExpr:
+				t = x.X
+			}
+			if ident, ok := t.(*ast.Ident); ok && ident.Name == typeName {
+				return receiver{true, pointer}
+			}
+		}
+	}
+	return receiver{}
+}
+
+// marshalerProblem returns the description of the problem with the
+// MarshalJSON and UnmarshalJSON methods of the named type (or "" if
+// there is none). Such types usually have different JSON structure
+// than documented (from their fields) in one direction.
+func marshalerProblem(pkg *ast.Package, typeName string) string {
+	m := methodReceiver(pkg, typeName, "MarshalJSON")
+	u := methodReceiver(pkg, typeName, "UnmarshalJSON")
+	switch {
+	case m.declared && !u.declared:
+		return "MarshalJSON is defined without UnmarshalJSON"
+	case u.declared && !m.declared:
+		return "UnmarshalJSON is defined without MarshalJSON"
+	case u.declared && !u.pointer:
+		return "UnmarshalJSON has a value receiver (so it cannot modify the value)"
+	case m.declared && m.pointer:
+		return "MarshalJSON has a pointer receiver (so it is not used for values which are not addressable)"
+	}
+	return ""
+}
+
+// checkMarshalers warns (once per type) about the named type with
+// asymmetric MarshalJSON and UnmarshalJSON methods.
+func (d *JSONDoc) checkMarshalers(t *ast.TypeSpec, c *context) {
+	if c == nil || c.Package == nil {
+		return
+	}
+	d.calls.cache.Lock()
+	checked := d.marshalersChecked[t]
+	d.marshalersChecked[t] = true
+	d.calls.cache.Unlock()
+	if checked {
+		return
+	}
+	if p := marshalerProblem(c.Package, t.Name.Name); p != "" {
+		d.warnf("warning: type %s: %s; the documented structure may be wrong in one direction\n", t.Name.Name, p)
+	}
+}
@@ -0,0 +1,41 @@
+package jsondoc
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// DefaultMermaidJS is the mermaid module used to render diagrams if not
+// given with -mermaid-js.
+const DefaultMermaidJS = "https://cdn.jsdelivr.net/npm/mermaid@10/dist/mermaid.esm.min.mjs"
+
+// mermaidRe matches code blocks of the mermaid language.
+var mermaidRe = regexp.MustCompile(`(?s)<pre><code class="language-mermaid">(.*?)</code></pre>`)
+
+// addMermaid replaces mermaid code blocks with the elements rendered as
+// diagrams by mermaid. It reports whether there were any.
+func addMermaid(out []byte) ([]byte, bool) {
+	if !mermaidRe.Match(out) {
+		return out, false
+	}
+	return mermaidRe.ReplaceAll(out, []byte(`<pre class="mermaid">$1</pre>`)), true
+}
+
+// mermaidScript returns the script rendering the diagrams with mermaid
+// loaded from src: an URL of the ES module or a local file with the
+// mermaid.min.js bundle which is embedded in the output (so that the
+// documentation works offline).
+func mermaidScript(src string) (string, error) {
+	if strings.Contains(src, "://") {
+		return fmt.Sprintf("<script type=\"module\">\nimport mermaid from %s;\nmermaid.initialize({startOnLoad: true});\n</script>\n", jsString(src)), nil
+	}
+	b, err := os.ReadFile(src)
+	if err != nil {
+		return "", fmt.Errorf("mermaid: %v", err)
+	}
+	// </script> may not occur inside of the script element
+	js := strings.Replace(string(b), "</script", `<\/script`, -1)
+	return "<script>\n" + js + "\n</script>\n<script>\nmermaid.initialize({startOnLoad: true});\n</script>\n", nil
+}
@@ -0,0 +1,48 @@
+package jsondoc
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// metaTags returns the elements of the head with the favicon, the
+// description, the canonical URL and the Open Graph tags (so that the
+// published documentation is indexed correctly and previewed when
+// shared) given in the configuration.
+func (d *JSONDoc) metaTags() (string, error) {
+	c := d.config
+	var b strings.Builder
+	if c.Favicon != "" {
+		src, err := imageSource(c.Favicon)
+		if err != nil {
+			return "", fmt.Errorf("favicon: %v", err)
+		}
+		fmt.Fprintf(&b, "<link rel=\"icon\" href=\"%s\">\n", html.EscapeString(src))
+	}
+	if c.Description != "" {
+		fmt.Fprintf(&b, "<meta name=\"description\" content=\"%s\">\n", html.EscapeString(c.Description))
+	}
+	if c.URL != "" {
+		fmt.Fprintf(&b, "<link rel=\"canonical\" href=\"%s\">\n", html.EscapeString(c.URL))
+	}
+	if c.Description == "" && c.URL == "" && c.Image == "" {
+		return b.String(), nil
+	}
+	og := func(property, content string) {
+		if content != "" {
+			fmt.Fprintf(&b, "<meta property=\"og:%s\" content=\"%s\">\n", property, html.EscapeString(content))
+		}
+	}
+	og("type", "website")
+	og("title", d.title)
+	og("description", c.Description)
+	og("url", c.URL)
+	og("image", c.Image)
+	card := "summary"
+	if c.Image != "" {
+		card = "summary_large_image"
+	}
+	fmt.Fprintf(&b, "<meta name=\"twitter:card\" content=\"%s\">\n", card)
+	return b.String(), nil
+}
@@ -0,0 +1,141 @@
+package jsondoc
+
+import (
+	"regexp"
+	"strings"
+)
+
+// The minification (with -minify) is conservative: it only removes
+// white space (and comments) which does not change how the document is
+// rendered, without relying on the parsing of HTML, CSS or JavaScript.
+
+var (
+	// rawElemRe matches the elements (and comments) the content of
+	// which is not ordinary HTML text.
+	rawElemRe = regexp.MustCompile(`(?is)<pre\b.*?</pre>|<textarea\b.*?</textarea>|<script\b[^>]*>.*?</script>|<style\b[^>]*>.*?</style>|<!--.*?-->`)
+
+	// rawElemStartRe matches the start of such an element.
+	rawElemStartRe = regexp.MustCompile(`(?i)<(pre|textarea|script|style)\b|<!--`)
+
+	htmlTagRe  = regexp.MustCompile(`<[^>]*>`)
+	tagNameRe  = regexp.MustCompile(`^</?([a-zA-Z0-9]+)`)
+	spaceRe    = regexp.MustCompile(`\s+`)
+	cssCommRe  = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	cssPunctRe = regexp.MustCompile(`\s*([{};,>])\s*`)
+	cssColonRe = regexp.MustCompile(`:\s+`) // (a space before a colon is a descendant combinator)
+)
+
+// blockTags lists the elements white space around which is not
+// rendered.
+var blockTags = map[string]bool{
+	"html": true, "head": true, "body": true, "meta": true, "title": true, "link": true,
+	"div": true, "p": true, "ul": true, "ol": true, "li": true, "nav": true, "section": true, "hr": true,
+	"table": true, "thead": true, "tbody": true, "tr": true, "td": true, "th": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"dl": true, "dt": true, "dd": true, "blockquote": true, "form": true,
+	"pre": true, "textarea": true, "script": true, "style": true,
+}
+
+// minifyHTML returns the HTML document with comments and insignificant
+// white space removed. The content of pre and textarea elements is
+// kept, style elements are minified as CSS and script elements as
+// JavaScript.
+func minifyHTML(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range rawElemRe.FindAllStringIndex(s, -1) {
+		b.WriteString(minifyText(s[last:m[0]]))
+		elem := s[m[0]:m[1]]
+		switch {
+		case strings.HasPrefix(elem, "<!--"):
+		case hasTagPrefix(elem, "<script"):
+			b.WriteString(minifyElem(elem, minifyJS))
+		case hasTagPrefix(elem, "<style"):
+			b.WriteString(minifyElem(elem, minifyCSS))
+		default:
+			b.WriteString(elem)
+		}
+		last = m[1]
+	}
+	b.WriteString(minifyText(s[last:]))
+	return b.String()
+}
+
+// hasUnclosedRawElem reports whether the HTML ends within an element
+// (or a comment) matched by rawElemRe, which would not be kept by
+// minifyHTML if the HTML was minified without the rest of it.
+func hasUnclosedRawElem(html []byte) bool {
+	last := 0
+	for _, m := range rawElemRe.FindAllIndex(html, -1) {
+		last = m[1]
+	}
+	return rawElemStartRe.Match(html[last:])
+}
+
+func hasTagPrefix(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// minifyElem minifies the content of the element with the function f.
+func minifyElem(elem string, f func(string) string) string {
+	i := strings.IndexByte(elem, '>') + 1
+	j := strings.LastIndexByte(elem, '<')
+	return elem[:i] + f(elem[i:j]) + elem[j:]
+}
+
+// minifyText collapses white space in the text between the tags (the
+// tags themselves are kept) and removes it next to block elements.
+func minifyText(s string) string {
+	var b strings.Builder
+	block := true // the previous tag is of a block element
+	last := 0
+	for _, m := range htmlTagRe.FindAllStringIndex(s, -1) {
+		tag := s[m[0]:m[1]]
+		writeText(&b, s[last:m[0]], block, isBlockTag(tag))
+		b.WriteString(tag)
+		block = isBlockTag(tag)
+		last = m[1]
+	}
+	writeText(&b, s[last:], block, true)
+	return b.String()
+}
+
+// writeText writes the text with white space collapsed (and removed at
+// the beginning or end if it follows or precedes a block element).
+func writeText(b *strings.Builder, text string, afterBlock, beforeBlock bool) {
+	text = spaceRe.ReplaceAllString(text, " ")
+	if afterBlock {
+		text = strings.TrimPrefix(text, " ")
+	}
+	if beforeBlock {
+		text = strings.TrimSuffix(text, " ")
+	}
+	b.WriteString(text)
+}
+
+func isBlockTag(tag string) bool {
+	m := tagNameRe.FindStringSubmatch(tag)
+	return m != nil && blockTags[strings.ToLower(m[1])] || strings.HasPrefix(tag, "<!")
+}
+
+// minifyCSS removes comments and white space from the style sheet
+// (which must not contain strings).
+func minifyCSS(s string) string {
+	s = cssCommRe.ReplaceAllString(s, "")
+	s = spaceRe.ReplaceAllString(s, " ")
+	s = cssPunctRe.ReplaceAllString(s, "$1")
+	s = cssColonRe.ReplaceAllString(s, ":")
+	return strings.TrimSpace(strings.Replace(s, ";}", "}", -1))
+}
+
+// minifyJS removes indentation and empty lines from the script (line
+// breaks are kept so that automatic semicolon insertion still works).
+func minifyJS(s string) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,71 @@
+package jsondoc
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMinifyHTML(t *testing.T) {
+	for _, c := range []struct {
+		name, html, want string
+	}{
+		{"text", "<p>\n  a   b\n  <em> c </em>\n</p>\n<div>\n d\n</div>", "<p>a b <em> c </em></p><div>d</div>"},
+		{"comment", "<p>a</p>\n<!-- x\n y -->\n<p>b</p>", "<p>a</p><p>b</p>"},
+		{"pre", "<div>\n<pre><code>a  b\n\n  c\n</code></pre>\n</div>", "<div><pre><code>a  b\n\n  c\n</code></pre></div>"},
+		{"pre attributes", "<pre class=\"x\">\n  a\n</pre>\n<PRE>\tb </PRE>", "<pre class=\"x\">\n  a\n</pre><PRE>\tb </PRE>"},
+		{"textarea", "<form>\n<textarea> a\n  b </textarea>\n</form>", "<form><textarea> a\n  b </textarea></form>"},
+		{"style", "<style>\n  a > b  {\n    color: red;\n  }\n  /* c */\n</style>", "<style>a>b{color:red}</style>"},
+		{"script", "<script>\n  var a = 1\n\n  f(a)\n</script>", "<script>var a = 1\nf(a)</script>"},
+	} {
+		if got := minifyHTML(c.html); got != c.want {
+			t.Errorf("%s: got %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestHasUnclosedRawElem(t *testing.T) {
+	for _, c := range []struct {
+		html string
+		want bool
+	}{
+		{"<p>a</p>", false},
+		{"<pre>a</pre><p>b</p>", false},
+		{"<pre>a</pre><div><pre>b\n", true},
+		{"<div><textarea>a", true},
+		{"<p>a</p><!-- b", true},
+		{"<p>a <preview>b</preview></p>", false},
+	} {
+		if got := hasUnclosedRawElem([]byte(c.html)); got != c.want {
+			t.Errorf("hasUnclosedRawElem(%q) = %t, want %t", c.html, got, c.want)
+		}
+	}
+}
+
+// TestMinifyCode checks that the code blocks (including raw HTML ones
+// spanning the chunks of the document) are not changed by minification.
+func TestMinifyCode(t *testing.T) {
+	src := "package api\n"
+	tmpl := "# API\n\n```go\nfunc  f() {\n\n\treturn  1\n}\n```\n\n" +
+		"<div class=\"example\"><pre><code>a   b\n\n    c\n</code></pre></div>\n\nText  after.\n"
+	var pres [2][]string
+	for i, minify := range []bool{false, true} {
+		d := newTestDoc(t, src, tmpl, Options{Minify: minify, Engine: engineGoldmark})
+		var b bytes.Buffer
+		if _, err := d.WriteTo(&b); err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range rawElemRe.FindAll(b.Bytes(), -1) {
+			if hasTagPrefix(string(m), "<pre") {
+				pres[i] = append(pres[i], string(m))
+			}
+		}
+	}
+	if len(pres[0]) != 2 || len(pres[1]) != 2 {
+		t.Fatalf("got pre elements %q and (minified) %q, want 2", pres[0], pres[1])
+	}
+	for i := range pres[0] {
+		if pres[0][i] != pres[1][i] {
+			t.Errorf("pre element changed by minification from\n%s\nto\n%s", pres[0][i], pres[1][i])
+		}
+	}
+}
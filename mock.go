@@ -0,0 +1,148 @@
+package jsondoc
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// MockHandler returns an HTTP handler responding to the requests to
+// the documented endpoints with sample outputs of the endpoints.
+func (d *JSONDoc) MockHandler() (http.Handler, error) {
+	c, err := d.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer d.end(c)
+	return c.mockHandler()
+}
+
+func (d *JSONDoc) mockHandler() (http.Handler, error) {
+	type route struct {
+		method, path, contentType string
+		disposition               string
+		status                    int // status of the responses with no body
+		body                      []byte
+	}
+	var routes []route
+	for _, e := range d.endpoints {
+		r, err := d.sampleResponse(e)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, route{e.Method, e.Path, r.ContentType, r.Disposition, r.Status, r.Body})
+	}
+	return allowCORS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := http.StatusNotFound
+		for _, rt := range routes {
+			if !matchPath(rt.path, r.URL.Path) {
+				continue
+			}
+			if rt.method != r.Method {
+				status = http.StatusMethodNotAllowed
+				continue
+			}
+			if rt.body == nil {
+				w.WriteHeader(rt.status)
+				return
+			}
+			w.Header().Set("Content-Type", rt.contentType)
+			if rt.disposition != "" {
+				w.Header().Set("Content-Disposition", rt.disposition)
+			}
+			w.Write(rt.body)
+			return
+		}
+		http.Error(w, http.StatusText(status), status)
+	})), nil
+}
+
+// sampleResponse is the sample response of an endpoint.
+type sampleResponse struct {
+	Status                   int
+	ContentType, Disposition string
+	Body                     []byte // nil for the responses with no body
+}
+
+// sampleResponse returns the sample response of the endpoint (as
+// served by MockHandler) of its declared content type (an empty body
+// for the content types with no samples, such as CBOR).
+func (d *JSONDoc) sampleResponse(e *endpoint) (sampleResponse, error) {
+	r := sampleResponse{Status: http.StatusOK, ContentType: "application/json"}
+	if e.File != nil {
+		// an empty file
+		r.ContentType, r.Disposition, r.Body = e.File.ContentType, e.File.Disposition, []byte{}
+	} else if e.OutputContentType == xmlContentType {
+		r.ContentType = xmlContentType
+		s, err := d.sampleXML(e.Output)
+		if err != nil {
+			return r, err
+		}
+		r.Body = []byte(s + "\n")
+	} else if e.Output != "" && e.OutputContentType == ndjsonContentType {
+		r.ContentType = ndjsonContentType
+		var err error
+		if r.Body, err = d.sampleStream(e); err != nil {
+			return r, err
+		}
+	} else if e.Output != "" && e.OutputContentType == csvContentType {
+		r.ContentType = csvContentType
+		var err error
+		if r.Body, err = d.sampleCSV(e); err != nil {
+			return r, err
+		}
+	} else if e.Output != "" && e.codec != nil {
+		// values encoded with the codecs are not sampled
+		r.ContentType, r.Body = e.codec.ContentType, []byte{}
+	} else if e.Output != "" && e.OutputContentType != "" {
+		// such as raw binary data
+		r.ContentType, r.Body = e.OutputContentType, []byte{}
+	} else if e.Output != "" {
+		v, err := d.outputSample(e)
+		if err != nil {
+			return r, err
+		}
+		if r.Body, err = json.MarshalIndent(v, "", "  "); err != nil {
+			return r, err
+		}
+		r.Body = append(r.Body, '\n')
+	}
+	if r.Body == nil {
+		r.Status, r.ContentType = http.StatusNoContent, ""
+		if e.NoOutputStatus != 0 {
+			r.Status = e.NoOutputStatus
+		}
+	}
+	return r, nil
+}
+
+// matchPath reports whether path matches the endpoint path pattern in
+// which segments in braces (such as "{id}") match any single segment.
+func matchPath(pattern, path string) bool {
+	ps := strings.Split(strings.Trim(pattern, "/"), "/")
+	s := strings.Split(strings.Trim(path, "/"), "/")
+	if len(ps) != len(s) {
+		return false
+	}
+	for i, p := range ps {
+		if p != s[i] && !(strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}")) {
+			return false
+		}
+	}
+	return true
+}
+
+// allowCORS allows cross origin requests to h (so that the mock may be
+// used from the "Try it" consoles).
+func allowCORS(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		if r.Method == "OPTIONS" && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", r.Header.Get("Access-Control-Request-Method"))
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
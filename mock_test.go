@@ -0,0 +1,87 @@
+package jsondoc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMockContentTypes(t *testing.T) {
+	d, err := New("example/index.md", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := d.MockHandler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/item/export", nil))
+	if ct := w.Header().Get("Content-Type"); ct != csvContentType {
+		t.Errorf("content type of /item/export = %q, want %q", ct, csvContentType)
+	}
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != streamLines+1 || len(rows[0]) != 3 || rows[0][0] != "id" {
+		t.Errorf("unexpected CSV rows %q", rows)
+	}
+
+}
+
+func TestMockCodecContentType(t *testing.T) {
+	d := newTestDoc(t, "package api\n\ntype Report struct {\n\tName string `json:\"name\"`\n}\n",
+		"{{endpoint \"GET\" \"/report\"}}\n{{contentType \"application/cbor\"}}\n\n{{output \"Report\"}}\n", Options{})
+	h, err := d.MockHandler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/report", nil))
+	if ct := w.Header().Get("Content-Type"); ct != "application/cbor" || w.Code != 200 || w.Body.Len() != 0 {
+		t.Errorf("response of /report = %d %q %q, want an empty application/cbor body", w.Code, ct, w.Body)
+	}
+}
+
+func TestHARContentTypes(t *testing.T) {
+	d, err := New("example/index.md", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := d.WriteHAR(&b); err != nil {
+		t.Fatal(err)
+	}
+	var har struct {
+		Log struct {
+			Entries []struct {
+				Comment  string
+				Response struct {
+					Content struct {
+						MimeType string
+						Text     string
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(b.Bytes(), &har); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, e := range har.Log.Entries {
+		if e.Comment != "POST /item/export" {
+			continue
+		}
+		found = true
+		if c := e.Response.Content; c.MimeType != csvContentType || !bytes.HasPrefix([]byte(c.Text), []byte("id,")) {
+			t.Errorf("response of POST /item/export = %q %q, want CSV", c.MimeType, c.Text)
+		}
+	}
+	if !found {
+		t.Error("no entry of POST /item/export")
+	}
+}
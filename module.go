@@ -0,0 +1,333 @@
+package jsondoc
+
+import (
+	"archive/tar"
+	"bufio"
+	"fmt"
+	"go/build"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// module is the documenting module: packages are resolved as if
+// imported from its root directory and its internal packages may be
+// imported.
+type module struct {
+	dir  string // root directory
+	path string // import path of the root directory
+
+	download    bool      // download dependencies missing from the module cache
+	downloaded  sync.Once // downloads the dependencies
+	downloadErr error     // error of downloading the dependencies
+}
+
+// newModule returns the module rooted in the directory dir. Its import
+// path is the module path declared in its go.mod file or (without the
+// go.mod file) the import path of the directory in GOPATH.
+func newModule(dir string) (*module, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	path, err := modulePath(filepath.Join(dir, "go.mod"))
+	if os.IsNotExist(err) {
+		p, err := build.ImportDir(dir, build.FindOnly)
+		if err != nil {
+			return nil, err
+		}
+		if p.ImportPath == "" || p.ImportPath == "." {
+			return nil, fmt.Errorf("module directory %s has no go.mod file and is not in GOPATH", dir)
+		}
+		path = p.ImportPath
+	} else if err != nil {
+		return nil, err
+	}
+	return &module{dir: dir, path: path}, nil
+}
+
+// checkout points the module to a copy of its tree at the git
+// revision rev (such as a tag) and returns the commit. The copies are
+// kept in the user cache directory so that regenerating the
+// documentation of a release does not extract it again.
+func (m *module) checkout(rev string) (string, error) {
+	out, err := exec.Command("git", "-C", m.dir, "rev-parse", "--verify", rev+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("revision %s of module %s not found: %v", rev, m.path, err)
+	}
+	commit := strings.TrimSpace(string(out))
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(cache, "jsondoc", "at", filepath.FromSlash(m.path)+"@"+commit)
+	if _, err := os.Stat(dir); err == nil {
+		m.dir = dir
+		return commit, nil
+	}
+	tmp := dir + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return "", err
+	}
+	cmd := exec.Command("git", "archive", "--format=tar", commit+":./")
+	cmd.Dir = m.dir
+	r, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+	err = extractTar(tmp, r)
+	if werr := cmd.Wait(); werr != nil {
+		return "", fmt.Errorf("git archive: %v\n%s", werr, stderr.String())
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return "", err
+	}
+	m.dir = dir
+	return commit, nil
+}
+
+// extractTar extracts the directories and regular files of the tar
+// archive to the directory dir.
+func extractTar(dir string, r io.Reader) error {
+	t := tar.NewReader(r)
+	for {
+		h, err := t.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		name := filepath.Clean(filepath.FromSlash(h.Name))
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("invalid file name %s in archive", h.Name)
+		}
+		name = filepath.Join(dir, name)
+		switch h.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(name, 0755)
+		case tar.TypeReg:
+			err = writeFile(name, t)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func writeFile(name string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// modulePath returns the module path declared in the named go.mod
+// file.
+func modulePath(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 2 || fields[0] != "module" {
+			continue
+		}
+		path := fields[1]
+		if strings.HasPrefix(path, `"`) || strings.HasPrefix(path, "`") {
+			if path, err = strconv.Unquote(path); err != nil {
+				return "", fmt.Errorf("%s: invalid module path %s", filename, fields[1])
+			}
+		}
+		return path, nil
+	}
+	if err := s.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%s: no module directive", filename)
+}
+
+// internalParent returns the import path of the parent of the
+// internal element of the import path (and false if there is none).
+func internalParent(path string) (string, bool) {
+	switch {
+	case path == "internal" || strings.HasPrefix(path, "internal/"):
+		return "", true
+	case strings.HasSuffix(path, "/internal"):
+		return strings.TrimSuffix(path, "/internal"), true
+	}
+	if i := strings.LastIndex(path, "/internal/"); i != -1 {
+		return path[:i], true
+	}
+	return "", false
+}
+
+// allowed reports whether the package with the import path may be
+// imported from the module (internal packages only from the tree
+// rooted at the parent of the internal directory, so internal packages
+// of the standard library are not allowed).
+func (m *module) allowed(path string) bool {
+	parent, ok := internalParent(path)
+	return !ok || m.path == parent || strings.HasPrefix(m.path, parent+"/")
+}
+
+// workspace maps the paths of the modules used in a go.work file to
+// their root directories.
+type workspace map[string]string
+
+// findWorkspace returns the workspace of the go.work file named by the
+// GOWORK environment variable or found in the directory dir or its
+// parents (nil if there is none or GOWORK is "off").
+func findWorkspace(dir string) (workspace, error) {
+	switch filename := os.Getenv("GOWORK"); filename {
+	case "off":
+		return nil, nil
+	case "":
+	default:
+		return readWorkspace(filename)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		filename := filepath.Join(dir, "go.work")
+		if _, err := os.Stat(filename); err == nil {
+			return readWorkspace(filename)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// readWorkspace reads the use directives of the named go.work file.
+func readWorkspace(filename string) (workspace, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	block := false
+	for _, line := range strings.Split(string(b), "\n") {
+		if i := strings.Index(line, "//"); i != -1 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case block && fields[0] == ")":
+			block = false
+			continue
+		case block:
+		case fields[0] == "use" && len(fields) == 2 && fields[1] == "(":
+			block = true
+			continue
+		case fields[0] == "use" && len(fields) == 2:
+			fields = fields[1:]
+		default:
+			continue
+		}
+		dir := fields[0]
+		if strings.HasPrefix(dir, `"`) || strings.HasPrefix(dir, "`") {
+			if dir, err = strconv.Unquote(dir); err != nil {
+				return nil, fmt.Errorf("%s: invalid use directive %s", filename, fields[0])
+			}
+		}
+		dirs = append(dirs, dir)
+	}
+	w := make(workspace)
+	for _, dir := range dirs {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(filename), dir)
+		}
+		path, err := modulePath(filepath.Join(dir, "go.mod"))
+		if err != nil {
+			return nil, err
+		}
+		w[path] = dir
+	}
+	return w, nil
+}
+
+// dir returns the directory of the package with the import path if it
+// belongs to a module of the workspace (the one with the longest
+// matching path).
+func (w workspace) dir(path string) (string, bool) {
+	mod := ""
+	for p := range w {
+		if (path == p || strings.HasPrefix(path, p+"/")) && len(p) > len(mod) {
+			mod = p
+		}
+	}
+	if mod == "" {
+		return "", false
+	}
+	return filepath.Join(w[mod], filepath.FromSlash(strings.TrimPrefix(path, mod))), true
+}
+
+// importPackage returns the package with the import path resolved
+// relative to the documenting module (if configured). Packages of the
+// modules of the go.work workspace and the packages imported with
+// relative paths in the template are found in their directories.
+func (d *JSONDoc) importPackage(path string, mode build.ImportMode) (*build.Package, error) {
+	if dir, ok := d.localDirs[path]; ok {
+		return d.buildContext(path).ImportDir(dir, mode)
+	}
+	if d.module != nil && !d.module.allowed(path) {
+		return nil, fmt.Errorf("use of internal package %s not allowed in module %s", path, d.module.path)
+	}
+	if dir, ok := d.workspace.dir(path); ok {
+		return d.buildContext(path).ImportDir(dir, mode)
+	}
+	if d.module == nil {
+		return d.buildContext(path).Import(path, "", mode)
+	}
+	p, err := d.buildContext(path).Import(path, d.module.dir, mode)
+	if err != nil && d.module.download {
+		if err := d.module.downloadDeps(); err != nil {
+			return nil, err
+		}
+		p, err = d.buildContext(path).Import(path, d.module.dir, mode)
+	}
+	return p, err
+}
+
+// downloadDeps downloads the dependencies of the module to the module
+// cache (once, returning the error of the download to all the calls).
+func (m *module) downloadDeps() error {
+	m.downloaded.Do(func() {
+		fmt.Fprintf(os.Stderr, "jsondoc: downloading dependencies of module %s\n", m.path)
+		cmd := exec.Command("go", "mod", "download")
+		cmd.Dir = m.dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			m.downloadErr = fmt.Errorf("go mod download: %v\n%s", err, out)
+		}
+	})
+	return m.downloadErr
+}
@@ -0,0 +1,328 @@
+package jsondoc
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestModulePath(t *testing.T) {
+	for _, tt := range []struct {
+		gomod, path, err string
+	}{
+		{gomod: "module example.com/api\n\ngo 1.21\n", path: "example.com/api"},
+		{gomod: "// comment\nmodule \"example.com/quoted\"\n", path: "example.com/quoted"},
+		{gomod: "module `example.com/raw`\n", path: "example.com/raw"},
+		{gomod: "module \"example.com/bad\n", err: "invalid module path"},
+		{gomod: "go 1.21\n", err: "no module directive"},
+	} {
+		name := filepath.Join(t.TempDir(), "go.mod")
+		writeTestFile(t, name, tt.gomod)
+		path, err := modulePath(name)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("modulePath(%q): got error %v, want %q", tt.gomod, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("modulePath(%q): %v", tt.gomod, err)
+		} else if path != tt.path {
+			t.Errorf("modulePath(%q) = %q, want %q", tt.gomod, path, tt.path)
+		}
+	}
+}
+
+func TestNewModule(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "go.mod"), "module example.com/api\n")
+	m, err := newModule(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.path != "example.com/api" || m.dir != dir {
+		t.Errorf("got module %s in %s, want example.com/api in %s", m.path, m.dir, dir)
+	}
+	if _, err := newModule(t.TempDir()); err == nil {
+		t.Error("got no error for a directory without go.mod outside GOPATH")
+	}
+}
+
+func TestInternalParent(t *testing.T) {
+	for _, tt := range []struct {
+		path, parent string
+		ok           bool
+	}{
+		{"internal", "", true},
+		{"internal/poll", "", true},
+		{"example.com/api/internal", "example.com/api", true},
+		{"example.com/api/internal/db", "example.com/api", true},
+		{"example.com/api/v2/internal/db/internal/sql", "example.com/api/v2/internal/db", true},
+		{"example.com/api", "", false},
+		{"example.com/internals/db", "", false},
+		{"example.com/api/myinternal", "", false},
+	} {
+		parent, ok := internalParent(tt.path)
+		if parent != tt.parent || ok != tt.ok {
+			t.Errorf("internalParent(%q) = %q, %v, want %q, %v", tt.path, parent, ok, tt.parent, tt.ok)
+		}
+	}
+}
+
+func TestModuleAllowed(t *testing.T) {
+	m := &module{path: "example.com/api/server"}
+	for _, tt := range []struct {
+		path string
+		ok   bool
+	}{
+		{"example.com/other", true},
+		{"example.com/api/server/internal/db", true},
+		{"example.com/api/internal/db", true},
+		{"example.com/internal/db", true},
+		{"example.com/other/internal/db", false},
+		{"example.com/api/server/cmd/internal/flags", false},
+		{"internal/poll", false},
+	} {
+		if ok := m.allowed(tt.path); ok != tt.ok {
+			t.Errorf("allowed(%q) = %v, want %v", tt.path, ok, tt.ok)
+		}
+	}
+}
+
+func TestImportInternalPackage(t *testing.T) {
+	d := &JSONDoc{module: &module{path: "example.com/api"}}
+	_, err := d.importPackage("example.com/other/internal/db", 0)
+	if err == nil || !strings.Contains(err.Error(), "use of internal package example.com/other/internal/db not allowed in module example.com/api") {
+		t.Errorf("got error %v, want use of internal package not allowed", err)
+	}
+}
+
+func TestReadWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "api", "go.mod"), "module example.com/api\n")
+	writeTestFile(t, filepath.Join(dir, "api", "v2", "go.mod"), "module example.com/api/v2\n")
+	writeTestFile(t, filepath.Join(dir, "lib", "go.mod"), "module example.com/lib\n")
+	writeTestFile(t, filepath.Join(dir, "go.work"), `go 1.21
+
+use ./api // the server
+use (
+	"./api/v2"
+	// ./old
+	lib
+)
+
+replace example.com/x => ./x
+`)
+	w, err := readWorkspace(filepath.Join(dir, "go.work"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := workspace{
+		"example.com/api":    filepath.Join(dir, "api"),
+		"example.com/api/v2": filepath.Join(dir, "api", "v2"),
+		"example.com/lib":    filepath.Join(dir, "lib"),
+	}
+	if len(w) != len(want) {
+		t.Errorf("got workspace %v, want %v", w, want)
+	}
+	for path, d := range want {
+		if w[path] != d {
+			t.Errorf("got directory %q of module %s, want %q", w[path], path, d)
+		}
+	}
+	for _, tt := range []struct {
+		path, dir string
+		ok        bool
+	}{
+		{"example.com/api", filepath.Join(dir, "api"), true},
+		{"example.com/api/server", filepath.Join(dir, "api", "server"), true},
+		{"example.com/api/v2/server", filepath.Join(dir, "api", "v2", "server"), true},
+		{"example.com/apis", "", false},
+		{"example.com/other", "", false},
+	} {
+		d, ok := w.dir(tt.path)
+		if d != tt.dir || ok != tt.ok {
+			t.Errorf("dir(%q) = %q, %v, want %q, %v", tt.path, d, ok, tt.dir, tt.ok)
+		}
+	}
+}
+
+func TestReadWorkspaceErrors(t *testing.T) {
+	for _, tt := range []struct {
+		gowork, err string
+	}{
+		{"use \"./api\n", "invalid use directive"},
+		{"use ./missing\n", "go.mod"},
+	} {
+		name := filepath.Join(t.TempDir(), "go.work")
+		writeTestFile(t, name, tt.gowork)
+		if _, err := readWorkspace(name); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("readWorkspace(%q): got error %v, want %q", tt.gowork, err, tt.err)
+		}
+	}
+}
+
+func TestFindWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "api", "go.mod"), "module example.com/api\n")
+	writeTestFile(t, filepath.Join(dir, "go.work"), "use ./api\n")
+	sub := filepath.Join(dir, "api", "server")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GOWORK", "")
+	w, err := findWorkspace(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w["example.com/api"] != filepath.Join(dir, "api") {
+		t.Errorf("got workspace %v found from %s", w, sub)
+	}
+
+	t.Setenv("GOWORK", "off")
+	if w, err := findWorkspace(sub); w != nil || err != nil {
+		t.Errorf("got workspace %v, %v with GOWORK=off", w, err)
+	}
+
+	other := t.TempDir()
+	writeTestFile(t, filepath.Join(other, "lib", "go.mod"), "module example.com/lib\n")
+	writeTestFile(t, filepath.Join(other, "my.work"), "use ./lib\n")
+	t.Setenv("GOWORK", filepath.Join(other, "my.work"))
+	w, err = findWorkspace(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(w) != 1 || w["example.com/lib"] != filepath.Join(other, "lib") {
+		t.Errorf("got workspace %v with GOWORK set", w)
+	}
+}
+
+func testTar(t *testing.T, files ...string) *bytes.Buffer {
+	t.Helper()
+	var b bytes.Buffer
+	w := tar.NewWriter(&b)
+	for _, name := range files {
+		h := &tar.Header{Name: name, Mode: 0o644, Typeflag: tar.TypeReg, Size: int64(len(name))}
+		if strings.HasSuffix(name, "/") {
+			h.Typeflag, h.Mode, h.Size = tar.TypeDir, 0o755, 0
+		}
+		if err := w.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		if h.Typeflag == tar.TypeReg {
+			if _, err := w.Write([]byte(name)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &b
+}
+
+func TestExtractTar(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tree")
+	if err := extractTar(dir, testTar(t, "go.mod", "api/", "api/api.go", "cmd/server/main.go", "empty/")); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"go.mod", "api/api.go", "cmd/server/main.go"} {
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Error(err)
+		} else if string(b) != name {
+			t.Errorf("got content %q of %s", b, name)
+		}
+	}
+	if fi, err := os.Stat(filepath.Join(dir, "empty")); err != nil || !fi.IsDir() {
+		t.Errorf("empty directory not extracted: %v", err)
+	}
+
+	for _, name := range []string{"../escape.go", "/abs.go", "api/../../escape.go"} {
+		dir := filepath.Join(t.TempDir(), "tree")
+		err := extractTar(dir, testTar(t, name))
+		if err == nil || !strings.Contains(err.Error(), "invalid file name") {
+			t.Errorf("extracting %s: got error %v, want invalid file name", name, err)
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escape.go")); err == nil {
+			t.Errorf("extracting %s: file written outside the directory", name)
+		}
+	}
+}
+
+func TestCheckout(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the git command")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git command not found")
+	}
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+	t.Setenv("HOME", cache)
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	writeTestFile(t, filepath.Join(dir, "go.mod"), "module example.com/api\n")
+	writeTestFile(t, filepath.Join(dir, "api", "api.go"), "package api // v1\n")
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "v1")
+	git("tag", "v1.0.0")
+	writeTestFile(t, filepath.Join(dir, "api", "api.go"), "package api // v2\n")
+
+	m, err := newModule(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := m.checkout("v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := git("rev-parse", "HEAD"); commit != want {
+		t.Errorf("got commit %s, want %s", commit, want)
+	}
+	if !strings.HasPrefix(m.dir, cache) || !strings.HasSuffix(m.dir, "@"+commit) {
+		t.Errorf("got checkout directory %s", m.dir)
+	}
+	b, err := os.ReadFile(filepath.Join(m.dir, "api", "api.go"))
+	if err != nil {
+		t.Fatal(err)
+	} else if string(b) != "package api // v1\n" {
+		t.Errorf("got api.go %q from the checkout", b)
+	}
+
+	// the checkout is reused
+	m2 := &module{dir: dir, path: m.path}
+	if c, err := m2.checkout("v1.0.0"); err != nil || c != commit || m2.dir != m.dir {
+		t.Errorf("got checkout %s, %s, %v, want %s, %s", m2.dir, c, err, m.dir, commit)
+	}
+
+	m3 := &module{dir: dir, path: m.path}
+	if _, err := m3.checkout("v9.9.9"); err == nil || !strings.Contains(err.Error(), "revision v9.9.9 of module example.com/api not found") {
+		t.Errorf("got error %v, want revision not found", err)
+	}
+}
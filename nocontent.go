@@ -0,0 +1,78 @@
+package jsondoc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// noInput documents that the requests to the current endpoint have no
+// body. The level of the section may be given as the optional
+// argument.
+func (d *JSONDoc) noInput(level ...int) (string, error) {
+	e := d.currentEndpoint()
+	if e == nil {
+		return "", errors.New("noInput must follow an endpoint")
+	}
+	if e.Input != "" {
+		return "", fmt.Errorf("noInput: endpoint %s has input %s", e.Title(), e.Input)
+	}
+	l, err := d.sectionLevel(e, level)
+	if err != nil {
+		return "", fmt.Errorf("noInput: %v", err)
+	}
+	id := d.endpointSectionID(e, "input")
+	return fmt.Sprintf("%s Input (none) {#%s}\n<div>\n<p>The request has no body.</p>\n</div>\n", heading(l), id), nil
+}
+
+// noOutput documents that the current endpoint responds with no body
+// and the given status (204 No Content if not given).
+func (d *JSONDoc) noOutput(status ...string) (string, error) {
+	e := d.currentEndpoint()
+	if e == nil {
+		return "", errors.New("noOutput must follow an endpoint")
+	}
+	if e.Output != "" || e.File != nil {
+		return "", fmt.Errorf("noOutput: endpoint %s has output", e.Title())
+	}
+	if len(status) > 1 {
+		return "", errors.New("noOutput: expected at most one status code")
+	}
+	code := http.StatusNoContent
+	if len(status) == 1 {
+		n, err := strconv.Atoi(status[0])
+		if err != nil || n < 200 || n > 299 {
+			return "", fmt.Errorf("noOutput: invalid status code %q of a successful response", status[0])
+		}
+		code = n
+	}
+	e.NoOutputStatus = code
+	l, err := d.sectionLevel(e, nil)
+	if err != nil {
+		return "", fmt.Errorf("noOutput: %v", err)
+	}
+	id := d.endpointSectionID(e, "output")
+	return fmt.Sprintf("%s Output (none) {#%s}\n<div>\n<p><span class=\"badge\">%s</span></p>\n<p>The response has no body.</p>\n</div>\n", heading(l), id, statusLine(code)), nil
+}
+
+// endpointSectionID returns the markdown header id of the input or
+// output section (or the section of the response headers) of the
+// endpoint which does not document a type (see sectionID).
+func (d *JSONDoc) endpointSectionID(e *endpoint, kind string) string {
+	d.section = e.ID
+	title := map[string]string{"input": "Input", "output": "Output", "headers": "Response headers"}[kind]
+	id := d.uniqueID(e.ID + "-" + kind)
+	d.addAnchor(anchor{ID: id, Kind: kind, Title: e.Title() + " " + title, Method: e.Method, Path: e.Path})
+	d.addEdge(e.ID, id)
+	return id
+}
+
+// statusLine returns the status code followed by its text (such as
+// "204 No Content").
+func statusLine(code int) string {
+	if text := http.StatusText(code); text != "" {
+		return strconv.Itoa(code) + " " + text
+	}
+	return strconv.Itoa(code)
+}
@@ -0,0 +1,294 @@
+package jsondoc
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WriteOpenAPI writes an OpenAPI 3.0 description (in JSON, which is
+// also valid YAML) of the documented endpoints with their input and
+// output types in components/schemas.
+func (d *JSONDoc) WriteOpenAPI(w io.Writer) error {
+	c, err := d.begin()
+	if err != nil {
+		return err
+	}
+	defer d.end(c)
+	return c.writeOpenAPI(w)
+}
+
+func (d *JSONDoc) writeOpenAPI(w io.Writer) error {
+	if err := d.execute(); err != nil {
+		return err
+	}
+	g := newSchemaGen(d, "#/components/schemas/")
+	version := d.config.Version
+	if version == "" {
+		version = "1.0.0"
+	}
+	title := d.title
+	if title == "" {
+		title = "API"
+	}
+	spec := object{
+		{"openapi", "3.0.3"},
+		{"info", object{{"title", title}, {"version", version}}},
+	}
+	if len(d.serverList) > 0 {
+		var servers []object
+		for _, s := range d.serverList {
+			servers = append(servers, object{{"url", s.URL}, {"description", s.Name}})
+		}
+		spec = append(spec, member{"servers", servers})
+	}
+	var paths object
+	for _, e := range d.endpoints {
+		op, err := d.openAPIOperation(g, e)
+		if err != nil {
+			return err
+		}
+		method := strings.ToLower(e.Method)
+		if i := paths.index(e.Path); i != -1 {
+			item := paths[i].Value.(object)
+			if item.index(method) == -1 {
+				paths[i].Value = append(item, member{method, op})
+			}
+			continue
+		}
+		paths = append(paths, member{e.Path, object{{method, op}}})
+	}
+	if paths == nil {
+		paths = object{}
+	}
+	spec = append(spec, member{"paths", paths})
+	if len(g.defs) > 0 {
+		spec = append(spec, member{"components", object{{"schemas", g.defs}}})
+	}
+	b, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// openAPIOperation returns the OpenAPI operation object of the
+// endpoint.
+func (d *JSONDoc) openAPIOperation(g *schemaGen, e *endpoint) (object, error) {
+	g.codec = e.codec
+	defer func() { g.codec = nil }()
+	op := object{{"operationId", clientMethodName(e)}, {"summary", e.Title()}}
+	if ps := pathParams(e.Path); len(ps) > 0 {
+		var params []object
+		for _, p := range ps {
+			params = append(params, object{{"name", p}, {"in", "path"}, {"required", true}, {"schema", object{{"type", "string"}}}})
+		}
+		op = append(op, member{"parameters", params})
+	}
+	if e.InputContentType == multipartContentType || e.InputContentType == urlEncodedContentType {
+		content, err := g.formContent(e.Input, e.InputContentType)
+		if err != nil {
+			return nil, err
+		}
+		op = append(op, member{"requestBody", object{{"required", true}, {"content", content}}})
+	} else if e.InputContentType == xmlContentType {
+		op = append(op, member{"requestBody", object{{"required", true}, {"content", object{{xmlContentType, object{}}}}}})
+	} else if e.Input != "" {
+		s, err := g.typeSchema(e.Input)
+		if err != nil {
+			return nil, err
+		}
+		op = append(op, member{"requestBody", object{{"required", true}, {"content", mediaContent(e.InputContentType, payloadSchema(e.InputContentType, s))}}})
+	}
+	responses, err := d.openAPIResponses(g, e)
+	if err != nil {
+		return nil, err
+	}
+	return append(op, member{"responses", responses}), nil
+}
+
+// openAPIResponses returns the OpenAPI responses object of the endpoint
+// (including the responses with the standard error object).
+func (d *JSONDoc) openAPIResponses(g *schemaGen, e *endpoint) (object, error) {
+	var responses object
+	switch {
+	case e.File != nil:
+		response := object{{"description", "OK"}}
+		if e.File.Disposition != "" {
+			response = append(response, member{"headers", object{{"Content-Disposition", object{{"schema", object{{"type", "string"}, {"example", e.File.Disposition}}}}}}})
+		}
+		schema := object{{"type", "string"}, {"format", "binary"}}
+		if e.File.MaxSize != "" {
+			schema = append(schema, member{"description", "Maximum size: " + e.File.MaxSize})
+		}
+		responses = object{{"200", append(response, member{"content", object{{e.File.ContentType, object{{"schema", schema}}}}})}}
+	case e.Output == "" && e.NoOutputStatus != 0:
+		responses = object{{strconv.Itoa(e.NoOutputStatus), object{{"description", http.StatusText(e.NoOutputStatus)}}}}
+	case e.Output == "":
+		responses = object{{"204", object{{"description", "No content"}}}}
+	case e.OutputContentType == xmlContentType:
+		responses = object{{"200", object{{"description", "OK"}, {"content", object{{xmlContentType, object{}}}}}}}
+	default:
+		s, err := g.typeSchema(e.Output)
+		if err != nil {
+			return nil, err
+		}
+		switch e.Container {
+		case "array":
+			s = object{{"type", "array"}, {"items", s}}
+		case "object":
+			s = object{{"type", "object"}, {"additionalProperties", s}}
+		}
+		if e.Envelope != "" {
+			if s, err = g.envelopeSchema(e.Envelope, s); err != nil {
+				return nil, err
+			}
+		}
+		responses = object{{"200", object{{"description", "OK"}, {"content", mediaContent(e.OutputContentType, payloadSchema(e.OutputContentType, s))}}}}
+	}
+	if len(e.ResponseHeaders) > 0 {
+		// the headers of the successful response (with the
+		// Content-Disposition header of files)
+		r := responses[0].Value.(object)
+		headers := openAPIHeaders(e.ResponseHeaders)
+		if len(r) > 1 && r[1].Key == "headers" {
+			r[1].Value = append(r[1].Value.(object), headers...)
+		} else {
+			r = append(object{r[0], {"headers", headers}}, r[1:]...)
+		}
+		responses[0].Value = r
+	}
+	if len(e.ErrorCodes) == 0 {
+		return responses, nil
+	}
+	g.codec = nil // errors are reported in JSON
+	s, err := g.typeSchema(e.Error)
+	if err != nil {
+		return nil, err
+	}
+	for _, code := range e.ErrorCodes {
+		n, _ := strconv.Atoi(code)
+		responses = append(responses, member{code, object{{"description", http.StatusText(n)}, {"content", mediaContent("", s)}}})
+	}
+	return responses, nil
+}
+
+// mediaContent returns the content with the schema of the given
+// content type (or application/json if empty).
+func mediaContent(contentType string, schema interface{}) object {
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	return object{{contentType, object{{"schema", schema}}}}
+}
+
+// payloadSchema returns the schema of the content of the given content
+// type with the values of the schema s (which is the schema of each of
+// the values of NDJSON).
+func payloadSchema(contentType string, s interface{}) interface{} {
+	switch contentType {
+	case csvContentType:
+		return object{{"type", "string"}}
+	case binaryContentType:
+		return object{{"type", "string"}, {"format", "binary"}}
+	}
+	return s
+}
+
+// typeSchema returns the schema referring to the type given by name
+// (as in the input and output actions).
+func (g *schemaGen) typeSchema(name string) (interface{}, error) {
+	t, c, err := g.d.lookupTypeName(name)
+	if err != nil {
+		return nil, err
+	}
+	return g.ref(t, c)
+}
+
+// formContent returns the content of the form request body (sent with
+// the given content type) described by the type name: the schema of
+// the form and the encoding of its parts with content types other than
+// default.
+func (g *schemaGen) formContent(name, contentType string) (object, error) {
+	t, c, err := g.d.lookupTypeName(name)
+	if err != nil {
+		return nil, err
+	}
+	parts, err := g.d.formParts(t, c)
+	if err != nil {
+		return nil, err
+	}
+	var props, encoding object
+	var required []string
+	for _, p := range parts {
+		var s interface{} = object{{"type", "string"}, {"format", "binary"}}
+		if p.Multiple {
+			s = object{{"type", "array"}, {"items", s}}
+		} else if !p.File {
+			if s, err = g.schema(p.typ, p.c); err != nil {
+				return nil, err
+			}
+		}
+		if p.Description != "" {
+			s = withDescription(s, p.Description)
+		}
+		props = append(props, member{p.Name, s})
+		if !p.Optional {
+			required = append(required, p.Name)
+		}
+		if contentType == multipartContentType && p.ContentType != g.d.defaultPartContentType(p, p.c) {
+			encoding = append(encoding, member{p.Name, object{{"contentType", p.ContentType}}})
+		}
+	}
+	schema := object{{"type", "object"}}
+	if len(props) > 0 {
+		schema = append(schema, member{"properties", props})
+	}
+	if len(required) > 0 {
+		schema = append(schema, member{"required", required})
+	}
+	media := object{{"schema", schema}}
+	if len(encoding) > 0 {
+		media = append(media, member{"encoding", encoding})
+	}
+	return object{{contentType, media}}, nil
+}
+
+// envelopeSchema returns the (inline) schema of the envelope type env
+// with the schema of its data field replaced by data.
+func (g *schemaGen) envelopeSchema(env string, data interface{}) (interface{}, error) {
+	t, c, err := g.d.lookupTypeName(env)
+	if err != nil {
+		return nil, err
+	}
+	f, _, err := g.d.envelopeField(t, c)
+	if err != nil {
+		return nil, err
+	}
+	g.override[f.Type] = data
+	defer delete(g.override, f.Type)
+	return g.schema(t.Type, c)
+}
+
+// index returns the index of the member with the given key (or -1).
+func (o object) index(key string) int {
+	for i, m := range o {
+		if m.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexFold is like index but matches the key ignoring case.
+func (o object) indexFold(key string) int {
+	for i, m := range o {
+		if strings.EqualFold(m.Key, key) {
+			return i
+		}
+	}
+	return -1
+}
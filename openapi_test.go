@@ -0,0 +1,158 @@
+package jsondoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const openAPISrc = `package api
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Base struct {
+	Created time.Time ` + "`json:\"created\"`" + `
+}
+
+type Input struct {
+	Base
+	Name    string          ` + "`json:\"name\"`" + `
+	Ratio   float32         ` + "`json:\"ratio,omitempty\"`" + `
+	Count   int64           ` + "`json:\"count\"`" + `
+	Data    []byte          ` + "`json:\"data\"`" + `
+	Tags    []string        ` + "`json:\"tags\"`" + `
+	Counts  map[string]int  ` + "`json:\"counts\"`" + `
+	Item    *Item           ` + "`json:\"item\"`" + `
+	Main    Item            ` + "`json:\"main\"`" + ` // the main item
+	Raw     json.RawMessage ` + "`json:\"raw\"`" + `
+	Any     any             ` + "`json:\"any\"`" + `
+	Skipped string          ` + "`json:\"-\"`" + `
+	private string
+}
+
+// Item is an item.
+type Item struct {
+	ID int ` + "`json:\"id\"`" + `
+}
+`
+
+func TestOpenAPISchemas(t *testing.T) {
+	d := newTestDoc(t, openAPISrc, "{{endpoint \"POST\" \"/input\"}}\n\n{{input \"Input\"}}\n", Options{})
+	var b bytes.Buffer
+	if err := d.WriteOpenAPI(&b); err != nil {
+		t.Fatal(err)
+	}
+	var spec map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &spec); err != nil {
+		t.Fatal(err)
+	}
+	schemas := mapAt(spec, "components", "schemas")
+	input := mapAt(schemas, "Input")
+	for _, c := range []struct {
+		field, want string
+	}{
+		{"created", `{"type": "string", "format": "date-time"}`},
+		{"name", `{"type": "string"}`},
+		{"ratio", `{"type": "number", "format": "float"}`},
+		{"count", `{"type": "integer", "format": "int64"}`},
+		{"data", `{"type": "string", "format": "byte"}`},
+		{"tags", `{"type": "array", "items": {"type": "string"}}`},
+		{"counts", `{"type": "object", "additionalProperties": {"type": "integer"}}`},
+		{"item", `{"$ref": "#/components/schemas/Item"}`},
+		{"main", `{"allOf": [{"$ref": "#/components/schemas/Item"}], "description": "the main item"}`},
+		{"raw", `{}`},
+		{"any", `{}`},
+	} {
+		var want interface{}
+		if err := json.Unmarshal([]byte(c.want), &want); err != nil {
+			t.Fatal(err)
+		}
+		if got := mapAt(input, "properties")[c.field]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got schema %v, want %v", c.field, got, want)
+		}
+	}
+	if n := len(mapAt(input, "properties")); n != 11 {
+		t.Errorf("got %d properties, want 11", n)
+	}
+	want := []interface{}{"created", "name", "count", "data", "tags", "counts", "item", "main", "raw", "any"}
+	if !reflect.DeepEqual(input["required"], want) {
+		t.Errorf("got required %v, want %v", input["required"], want)
+	}
+	if item := mapAt(schemas, "Item"); item["description"] != "Item is an item." {
+		t.Errorf("got Item schema %v, want it described", item)
+	}
+}
+
+// TestOpenAPIExample checks the structure of the OpenAPI description of
+// the example.
+func TestOpenAPIExample(t *testing.T) {
+	d, err := New("example/index.md", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := d.WriteOpenAPI(&b); err != nil {
+		t.Fatal(err)
+	}
+	var spec map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &spec); err != nil {
+		t.Fatal(err)
+	}
+	if spec["openapi"] != "3.0.3" || mapAt(spec, "info")["title"] == nil || mapAt(spec, "info")["version"] == nil {
+		t.Errorf("invalid header: openapi %v, info %v", spec["openapi"], spec["info"])
+	}
+	schemas := mapAt(spec, "components", "schemas")
+	for _, ref := range regexp.MustCompile(`"\$ref": "([^"]*)"`).FindAllStringSubmatch(b.String(), -1) {
+		name := strings.TrimPrefix(ref[1], "#/components/schemas/")
+		if name == ref[1] || schemas[name] == nil {
+			t.Errorf("unresolved reference %s", ref[1])
+		}
+	}
+	paths := mapAt(spec, "paths")
+	if len(paths) == 0 {
+		t.Fatal("no paths")
+	}
+	operationIDs := make(map[string]bool)
+	for _, path := range unionKeys(paths, nil) {
+		params := make(map[string]bool)
+		for _, m := range pathParamRe.FindAllString(path, -1) {
+			params[m[1:len(m)-1]] = true
+		}
+		for _, method := range httpMethods {
+			op := mapAt(paths, path, method)
+			if op == nil {
+				continue
+			}
+			where := method + " " + path
+			id, _ := op["operationId"].(string)
+			if id == "" || operationIDs[id] {
+				t.Errorf("%s: missing or duplicate operationId %q", where, id)
+			}
+			operationIDs[id] = true
+			if len(mapAt(op, "responses")) == 0 {
+				t.Errorf("%s: no responses", where)
+			}
+			declared := make(map[string]bool)
+			list, _ := op["parameters"].([]interface{})
+			for _, p := range list {
+				p, _ := p.(map[string]interface{})
+				if p["in"] == "path" {
+					name, _ := p["name"].(string)
+					declared[name] = true
+					if p["required"] != true || !params[name] {
+						t.Errorf("%s: invalid path parameter %v", where, p)
+					}
+				}
+			}
+			if len(declared) != len(params) {
+				t.Errorf("%s: got path parameters %v, want %v", where, declared, params)
+			}
+		}
+	}
+}
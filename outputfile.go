@@ -0,0 +1,91 @@
+package jsondoc
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// fileOutput is the output of an endpoint responding with a file
+// (rather than a value of a documented type).
+type fileOutput struct {
+	ContentType string
+	Disposition string // value of the Content-Disposition header (if any)
+	MaxSize     string // maximum size as given to the outputFile action (if any)
+	maxSize     int64  // maximum size in bytes (zero if not limited)
+}
+
+// outputFile documents the output of the current endpoint as a file of
+// the given content type. The optional arguments are the value of the
+// Content-Disposition header of the response (such as "attachment;
+// filename=report.pdf") and the maximum size of the file (in bytes or
+// with a unit such as "10 MB" or "1 GiB").
+func (d *JSONDoc) outputFile(contentType string, args ...string) (string, error) {
+	d.b.Reset()
+	e := d.currentEndpoint()
+	if e == nil {
+		return "", errors.New("outputFile must follow an endpoint")
+	}
+	if contentType == "" || len(args) > 2 {
+		return "", fmt.Errorf("outputFile: expected the content type optionally followed by the disposition and the maximum size")
+	}
+	f := &fileOutput{ContentType: contentType}
+	if len(args) > 0 {
+		f.Disposition = args[0]
+	}
+	if len(args) > 1 {
+		n, err := parseSize(args[1])
+		if err != nil {
+			return "", fmt.Errorf("outputFile: %v", err)
+		}
+		f.MaxSize, f.maxSize = strings.TrimSpace(args[1]), n
+	}
+	e.File, e.OutputContentType = f, contentType
+	l, err := d.sectionLevel(e, nil)
+	if err != nil {
+		return "", fmt.Errorf("outputFile: %v", err)
+	}
+	id := d.endpointSectionID(e, "output")
+	fmt.Fprintf(&d.b, "%s Output (file, %s) {#%s}\n<div>\n", heading(l), markdownEscapeString(contentType), id)
+	d.writeContentType(contentType)
+	d.b.WriteString("<p>File with the following properties:</p>\n<table>\n<caption>Properties of the file</caption>\n")
+	fmt.Fprintf(&d.b, "<tr>\n<th scope=\"row\">Content-Type</th>\n<td><code>%s</code></td>\n</tr>\n", html.EscapeString(f.ContentType))
+	if f.Disposition != "" {
+		fmt.Fprintf(&d.b, "<tr>\n<th scope=\"row\">Content-Disposition</th>\n<td><code>%s</code></td>\n</tr>\n", html.EscapeString(f.Disposition))
+	}
+	if f.MaxSize != "" {
+		fmt.Fprintf(&d.b, "<tr>\n<th scope=\"row\">Maximum size</th>\n<td>%s</td>\n</tr>\n", html.EscapeString(f.MaxSize))
+	}
+	d.b.WriteString("</table>\n</div>\n")
+	return d.b.String(), nil
+}
+
+// sizeUnits are the units of sizes given to the outputFile action.
+var sizeUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"KB":  1000,
+	"MB":  1000 * 1000,
+	"GB":  1000 * 1000 * 1000,
+	"KiB": 1 << 10,
+	"MiB": 1 << 20,
+	"GiB": 1 << 30,
+}
+
+// parseSize returns the number of bytes of the size (such as "512",
+// "10 MB" or "1.5 GiB").
+func parseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if i == -1 {
+		i = len(s)
+	}
+	unit, ok := sizeUnits[strings.TrimSpace(s[i:])]
+	v, err := strconv.ParseFloat(s[:i], 64)
+	if !ok || err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(v * float64(unit)), nil
+}
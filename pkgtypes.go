@@ -0,0 +1,104 @@
+package jsondoc
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"html"
+	"strings"
+)
+
+// typeFilter selects the types documented by the types action.
+type typeFilter struct {
+	prefix, suffix string
+	all            bool // also unexported types
+}
+
+// parseTypeFilter returns the filter given with the arguments of the
+// types action: "prefix=X", "suffix=X" and "all".
+func parseTypeFilter(args []string) (typeFilter, error) {
+	var f typeFilter
+	for _, arg := range args {
+		switch {
+		case arg == "all":
+			f.all = true
+		case strings.HasPrefix(arg, "prefix="):
+			f.prefix = strings.TrimPrefix(arg, "prefix=")
+		case strings.HasPrefix(arg, "suffix="):
+			f.suffix = strings.TrimPrefix(arg, "suffix=")
+		default:
+			return f, fmt.Errorf("unknown filter %q", arg)
+		}
+	}
+	return f, nil
+}
+
+func (f typeFilter) match(name string) bool {
+	return (f.all || ast.IsExported(name)) && strings.HasPrefix(name, f.prefix) && strings.HasSuffix(name, f.suffix)
+}
+
+// packageTypes documents all the exported types of the imported
+// package (as named in the import action, "." for the default one)
+// selected with the optional filters (see parseTypeFilter) in the order
+// of their declarations, for example as a reference appendix. The types
+// already rendered are only listed with links to them.
+func (d *JSONDoc) packageTypes(pkgName string, filters ...string) (string, error) {
+	f, err := parseTypeFilter(filters)
+	if err != nil {
+		return "", fmt.Errorf("types %s: %v", pkgName, err)
+	}
+	path, err := d.importedPath(pkgName)
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", fmt.Errorf("types: package %s is not imported", pkgName)
+	}
+	pkg, err := d.parsedPackage(path)
+	if err != nil {
+		return "", err
+	}
+	d.b.Reset()
+	d.sections++
+	d.section = fmt.Sprintf("section-%d", d.sections)
+	d.typeLevel = d.headingLevel() + 1
+	d.graphParent = ""
+	var above []string
+	n := 0
+	for _, file := range sortedFiles(pkg) {
+		for _, decl := range file.Decls {
+			g, ok := decl.(*ast.GenDecl)
+			if !ok || g.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range g.Specs {
+				t := spec.(*ast.TypeSpec)
+				if !f.match(t.Name.Name) || t.Assign.IsValid() {
+					continue
+				}
+				n++
+				c := &context{path, pkg, file}
+				queued := len(d.renderQueue)
+				id := d.renderLater(t.Name.Name, nil, c)
+				if id != "" && len(d.renderQueue) == queued {
+					above = append(above, fmt.Sprintf(`<a href="#%s">%s</a>`, html.EscapeString(id), html.EscapeString(t.Name.Name)))
+				}
+			}
+		}
+	}
+	if n == 0 {
+		return "", fmt.Errorf("types %s: no matching types in package %s", pkgName, path)
+	}
+	var b bytes.Buffer
+	b.WriteString("<div>\n")
+	if len(above) > 0 {
+		fmt.Fprintf(&b, "<p>Types described above: %s.</p>\n", strings.Join(above, ", "))
+	}
+	if err := d.renderQueued(); err != nil {
+		return "", err
+	}
+	b.Write(d.b.Bytes())
+	b.WriteString("</div>\n")
+	return b.String(), nil
+}
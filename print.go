@@ -0,0 +1,57 @@
+package jsondoc
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// cover returns the cover page of the printed documentation with the
+// title, the version of the API (if configured) and the date of
+// generation.
+func (d *JSONDoc) cover() (string, error) {
+	t, err := generationTime()
+	if err != nil {
+		return "", err
+	}
+	if d.stamp != nil {
+		t = d.stamp.Time
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "<section class=\"cover\">\n<p class=\"cover-title\">%s</p>\n", html.EscapeString(d.title))
+	if d.config.Version != "" {
+		fmt.Fprintf(&b, "<p class=\"cover-version\">API version %s</p>\n", html.EscapeString(d.config.Version))
+	}
+	fmt.Fprintf(&b, "<p class=\"cover-date\">%s</p>\n</section>\n", html.EscapeString(t.Format(d.locale().Date)))
+	return b.String(), nil
+}
+
+// cssString returns s as a CSS string (safe to embed in the style
+// element).
+func cssString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ", "<", `\3c `)
+	return `"` + r.Replace(s) + `"`
+}
+
+// pagebreak starts a new page in the printed documentation.
+func (d *JSONDoc) pagebreak() string {
+	return "<div class=\"page-break\"></div>\n"
+}
+
+// keepTogether starts the part of the documentation (ended with
+// endKeepTogether) which is not split across printed pages (if it fits
+// on a page).
+func (d *JSONDoc) keepTogether() string {
+	d.keepOpen++
+	return "<div class=\"keep-together\">\n\n"
+}
+
+// endKeepTogether ends the part started with keepTogether.
+func (d *JSONDoc) endKeepTogether() (string, error) {
+	if d.keepOpen == 0 {
+		return "", errors.New("endKeepTogether without keepTogether")
+	}
+	d.keepOpen--
+	return "\n</div>\n", nil
+}
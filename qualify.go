@@ -0,0 +1,27 @@
+package jsondoc
+
+import "sort"
+
+// qualifiedName returns the name of the named type declared in the
+// package of the context qualified (with Options.QualifyTypes) with
+// the name the package is imported with in the template (such as
+// "items.size") so that the types of the same name in different
+// packages have distinct headings and anchors. The package name is
+// used for the default package (imported as ".") and for the packages
+// not imported in the template.
+func (d *JSONDoc) qualifiedName(name string, c *context) string {
+	if !d.qualifyTypes || c == nil {
+		return name
+	}
+	var names []string
+	for n, path := range d.imports {
+		if path == c.Path && n != "." {
+			names = append(names, n)
+		}
+	}
+	if len(names) == 0 {
+		return c.Package.Name + "." + name
+	}
+	sort.Strings(names)
+	return names[0] + "." + name
+}
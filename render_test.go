@@ -0,0 +1,94 @@
+package jsondoc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderTypes(t *testing.T) {
+	tests := []struct {
+		name      string
+		src, tmpl string
+		want      []string // substrings of the markdown
+	}{
+		{
+			name: "generic field",
+			src: `package api
+
+type Box[T any] struct {
+	Value T ` + "`json:\"value\"`" + `
+}
+
+type Pair[K comparable, V any] struct {
+	Key K ` + "`json:\"key\"`" + `
+	Val V ` + "`json:\"val\"`" + `
+}
+
+type outer struct {
+	Box   Box[int]          ` + "`json:\"box\"`" + `
+	Pair  Pair[string, int] ` + "`json:\"pair\"`" + `
+	Boxes []*Box[string]    ` + "`json:\"boxes\"`" + `
+}
+`,
+			tmpl: `{{output "outer"}}`,
+			want: []string{
+				`<a href="#type-Box-1">Box</a>[int]`,
+				`<a href="#type-Pair-1">Pair</a>[string, int]`,
+				`array of <a href="#type-Box-1">Box</a>[string]`,
+				`<td>T</td>`,
+			},
+		},
+		{
+			name: "definition of a named type",
+			src: `package api
+
+type User struct {
+	Name string ` + "`json:\"name\"`" + ` // name of the user
+}
+
+type Users []User
+
+type Admins Users
+`,
+			tmpl: `{{output "Admins"}}`,
+			want: []string{`JSON array of values of type <a href="#type-User-1">User</a>.`, `<caption>Fields of type User</caption>`},
+		},
+		{
+			name: "named slice",
+			src: `package api
+
+type UserID int64
+
+type IDs []UserID
+`,
+			tmpl: `{{output "IDs"}}`,
+			want: []string{`JSON array of values of type <a href="#type-UserID-1">UserID</a>.`, `JSON value of type int64.`},
+		},
+		{
+			name: "generic named type",
+			src: `package api
+
+type Box[T any] struct {
+	Value T ` + "`json:\"value\"`" + `
+}
+
+type IntBox Box[int]
+`,
+			tmpl: `{{output "IntBox"}}`,
+			want: []string{`value of type <a href="#type-Box-1">Box</a>[int]`, `<td>T</td>`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := renderMarkdown(t, tt.src, tt.tmpl)
+			for _, s := range tt.want {
+				if !strings.Contains(md, s) {
+					t.Errorf("%q not found in\n%s", s, md)
+				}
+			}
+			if strings.Contains(md, "&amp;{") {
+				t.Errorf("AST dumped in\n%s", md)
+			}
+		})
+	}
+}
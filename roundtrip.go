@@ -0,0 +1,296 @@
+package jsondoc
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"io"
+)
+
+// WriteRoundTripTest writes Go source of a test for the package
+// imported in the template with the given name which, for each
+// documented struct type declared in it (inputs and outputs of the
+// endpoints and struct types they refer to), marshals synthesized
+// values with encoding/json and verifies that the produced keys and
+// their optionality are exactly as documented. This catches
+// divergences caused by tags or custom marshalers jsondoc does not
+// understand.
+func (d *JSONDoc) WriteRoundTripTest(w io.Writer, pkgName string) error {
+	c, err := d.begin()
+	if err != nil {
+		return err
+	}
+	defer d.end(c)
+	return c.writeRoundTripTest(w, pkgName)
+}
+
+func (d *JSONDoc) writeRoundTripTest(w io.Writer, pkgName string) error {
+	path, err := d.importedPath(pkgName)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return fmt.Errorf("name %s is not imported in the template", pkgName)
+	}
+	g := &roundTripGen{d: d, path: path, done: make(map[*ast.TypeSpec]bool)}
+	for _, e := range d.endpoints {
+		var names []string
+		if e.jsonInput() {
+			names = append(names, e.Input)
+		}
+		if e.Output != "" && e.OutputContentType == "" && e.codec == nil {
+			names = append(names, e.Output, e.Envelope)
+		}
+		for _, name := range names {
+			if name == "" {
+				continue
+			}
+			ts, c, err := d.lookupTypeName(name)
+			if err != nil {
+				return err
+			}
+			g.enqueue(ts, c)
+		}
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by jsondoc; DO NOT EDIT.\n\npackage %s\n\nimport (\n\"encoding/json\"\n\"reflect\"\n\"testing\"\n\"time\"\n)\n\n", d.packageName(path))
+	b.WriteString("// jsondocTypes lists the documented types with their JSON keys (true\n// for the keys documented as optional).\nvar jsondocTypes = []struct {\nname string\nvalue interface{}\nkeys map[string]bool\n}{\n")
+	for i := 0; i < len(g.queue); i++ {
+		q := g.queue[i]
+		keys, err := g.keys(nil, q.t.Type.(*ast.StructType), q.c)
+		if err != nil {
+			return fmt.Errorf("type %s: %v", q.t.Name.Name, err)
+		}
+		fmt.Fprintf(&b, "{%q, %s{}, map[string]bool{", q.t.Name.Name, q.t.Name.Name)
+		for _, m := range keys {
+			fmt.Fprintf(&b, "%q: %v, ", m.Key, m.Value)
+		}
+		b.WriteString("}},\n")
+	}
+	b.WriteString("}\n")
+	b.WriteString(roundTripTest)
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting generated code: %v", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+type roundTripGen struct {
+	d     *JSONDoc
+	path  string // path of the package the test is generated for
+	queue []queueElem
+	done  map[*ast.TypeSpec]bool
+}
+
+// enqueue schedules the check of the type (if it is a struct type
+// declared in the package) and of the struct types it refers to.
+func (g *roundTripGen) enqueue(t *ast.TypeSpec, c *context) {
+	if g.done[t] || c.Path != g.path || t.TypeParams != nil {
+		return
+	}
+	g.done[t] = true
+	if _, ok := t.Type.(*ast.StructType); ok {
+		g.queue = append(g.queue, queueElem{t: t, c: c})
+	}
+	g.refs(t.Type, c)
+}
+
+// refs enqueues the named types the type expression refers to.
+func (g *roundTripGen) refs(t ast.Expr, c *context) {
+	switch t := t.(type) {
+	case *ast.Ident, *ast.SelectorExpr:
+		if ts, c, err := g.d.lookupType(t, c); err == nil && ts != nil {
+			g.enqueue(ts, c)
+		}
+	case *ast.StarExpr:
+		g.refs(t.X, c)
+	case *ast.ArrayType:
+		g.refs(t.Elt, c)
+	case *ast.MapType:
+		g.refs(t.Value, c)
+	case *ast.StructType:
+		for _, f := range t.Fields.List {
+			g.refs(f.Type, c)
+		}
+	}
+}
+
+// keys appends the documented JSON keys of the fields of the struct
+// type (including the fields of embedded structs) with their
+// optionality.
+func (g *roundTripGen) keys(keys object, t *ast.StructType, c *context) (object, error) {
+	for _, f := range t.Fields.List {
+		if typ := g.d.inlined(f); typ != nil {
+			if s, ok := typ.(*ast.StarExpr); ok {
+				typ = s.X
+			}
+			ts, c, err := g.d.lookupType(typ, c)
+			if err != nil {
+				return nil, err
+			}
+			if ts == nil {
+				continue
+			}
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				if keys, err = g.keys(keys, st, c); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		for _, ident := range f.Names {
+			key, omitempty, err := g.d.jsonKey(ident.Name, f.Tag)
+			if err == NotExported {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			keys = append(keys, member{key, omitempty})
+		}
+	}
+	return keys, nil
+}
+
+const roundTripTest = `
+// TestJSONDocRoundTrip verifies that encoding/json produces exactly
+// the documented keys of the types: all of them for values with all
+// the fields set and only the required ones for zero values.
+func TestJSONDocRoundTrip(t *testing.T) {
+	for _, tt := range jsondocTypes {
+		typ := reflect.TypeOf(tt.value)
+		full := reflect.New(typ).Elem()
+		jsondocFill(full, 0)
+		keys, err := jsondocKeys(full)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		for k := range tt.keys {
+			if !keys[k] {
+				t.Errorf("%s: documented key %q is not produced by encoding/json", tt.name, k)
+			}
+		}
+		for k := range keys {
+			if _, ok := tt.keys[k]; !ok {
+				t.Errorf("%s: key %q produced by encoding/json is not documented", tt.name, k)
+			}
+		}
+		keys, err = jsondocKeys(reflect.New(typ).Elem())
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		for k, optional := range tt.keys {
+			if optional && keys[k] {
+				t.Errorf("%s: key %q is documented as optional but is present in the zero value", tt.name, k)
+			} else if !optional && !keys[k] {
+				t.Errorf("%s: key %q is documented as required but is omitted from the zero value", tt.name, k)
+			}
+		}
+	}
+}
+
+// jsondocKeys returns the keys of the JSON object v is marshaled to.
+func jsondocKeys(v reflect.Value) (map[string]bool, error) {
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool)
+	for k := range m {
+		keys[k] = true
+	}
+	return keys, nil
+}
+
+// jsondocValues are the non zero values of the types without exported
+// fields to be set.
+var jsondocValues = map[reflect.Type]reflect.Value{
+	reflect.TypeOf(time.Time{}): reflect.ValueOf(time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)),
+}
+
+// jsondocFill sets v (and its exported fields, elements, ...) to non
+// zero values.
+func jsondocFill(v reflect.Value, depth int) {
+	if depth > 4 {
+		return
+	}
+	if v.Kind() == reflect.Struct {
+		// the exported fields of embedded structs of unexported types
+		// are settable (and promoted) even if v is not
+		jsondocFillFields(v, depth)
+		return
+	}
+	if !v.CanSet() {
+		return
+	}
+	if x, ok := jsondocValues[v.Type()]; ok {
+		v.Set(x)
+		return
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString("x")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	case reflect.Pointer:
+		p := reflect.New(v.Type().Elem())
+		jsondocFill(p.Elem(), depth+1)
+		v.Set(p)
+	case reflect.Slice:
+		s := reflect.MakeSlice(v.Type(), 1, 1)
+		jsondocFill(s.Index(0), depth+1)
+		v.Set(s)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			jsondocFill(v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		m := reflect.MakeMap(v.Type())
+		k := reflect.New(v.Type().Key()).Elem()
+		k.SetString("x")
+		e := reflect.New(v.Type().Elem()).Elem()
+		jsondocFill(e, depth+1)
+		m.SetMapIndex(k, e)
+		v.Set(m)
+	case reflect.Interface:
+		if v.NumMethod() == 0 {
+			v.Set(reflect.ValueOf("x"))
+		}
+	}
+}
+
+// jsondocFillFields sets the struct (if it is of a type with a known
+// value) or its exported fields and the exported fields of its embedded
+// structs.
+func jsondocFillFields(v reflect.Value, depth int) {
+	if x, ok := jsondocValues[v.Type()]; ok {
+		if v.CanSet() {
+			v.Set(x)
+		}
+		return
+	}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.IsExported() || (f.Anonymous && f.Type.Kind() == reflect.Struct) {
+			jsondocFill(v.Field(i), depth+1)
+		}
+	}
+}
+`
@@ -0,0 +1,306 @@
+package jsondoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// object is a JSON object which preserves the order of its members
+// (so that samples list keys in the order of struct fields).
+type object []member
+
+type member struct {
+	Key   string
+	Value interface{}
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, err := json.Marshal(m.Key)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		v, err := json.Marshal(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// sampleByName returns a sample JSON value of the named type (as given
+// to the input and output actions).
+func (d *JSONDoc) sampleByName(name string) (interface{}, error) {
+	t, c, err := d.lookupTypeName(name)
+	if err != nil {
+		return nil, err
+	}
+	return d.sample(t.Type, c, fakeField{}, make(map[*ast.TypeSpec]bool)), nil
+}
+
+// sample returns a sample JSON value (composed of object, []interface{},
+// string, int, float64, bool and nil values) conforming to the given
+// type. The fake field (the JSON key of the value, if any, and the
+// directives of its field) is used to choose realistic fake values.
+// Types present in seen are not expanded again (so that recursive types
+// result in finite samples).
+func (d *JSONDoc) sample(t ast.Expr, c *context, fake fakeField, seen map[*ast.TypeSpec]bool) interface{} {
+	switch t := t.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return d.fakeString(fake)
+		case "bool":
+			return d.rand.Intn(2) == 1
+		case "float32", "float64":
+			return d.fakeFloat(fake)
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune":
+			return d.fakeInt(fake)
+		}
+		return d.sampleNamed(t, c, fake, seen)
+	case *ast.SelectorExpr:
+		if name, ok := d.cType(t, c); ok {
+			if float, ok := cNumeric[name]; ok && float {
+				return d.fakeFloat(fake)
+			} else if ok {
+				return d.fakeInt(fake)
+			}
+			return nil
+		}
+		if ident, ok := t.X.(*ast.Ident); ok {
+			if path, err := d.findImportIdent(c.File, ident.Name); err == nil && path == "time" {
+				switch t.Sel.Name {
+				case "Time":
+					return d.fakeTime().Format(time.RFC3339)
+				case "Duration":
+					return d.fakeInt(fake)
+				}
+			}
+		}
+		return d.sampleNamed(t, c, fake, seen)
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		// the values of the type parameters are not sampled
+		return d.sampleNamed(t, c, fake, seen)
+	case *ast.StarExpr:
+		return d.sample(t.X, c, fake, seen)
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && (ident.Name == "byte" || ident.Name == "uint8") {
+			return "ZXhhbXBsZQ==" // encoded as base64 string
+		}
+		a := []interface{}{}
+		for i := 0; i < sampleLen(fake.Limits); i++ {
+			a = append(a, d.sample(t.Elt, c, fake.elem(), seen))
+		}
+		return a
+	case *ast.MapType:
+		o := object{}
+		keys := make(map[string]bool)
+		for i := 0; i < sampleLen(fake.Limits); i++ {
+			k := d.pick(fakeWords)
+			if keys[k] {
+				k += strconv.Itoa(i)
+			}
+			keys[k] = true
+			o = append(o, member{k, d.sample(t.Value, c, fake.elem(), seen)})
+		}
+		return o
+	case *ast.StructType:
+		o := object{}
+		d.sampleFields(&o, t, c, seen)
+		return o
+	}
+	return nil
+}
+
+func (d *JSONDoc) sampleNamed(t ast.Expr, c *context, fake fakeField, seen map[*ast.TypeSpec]bool) interface{} {
+	ts, c, err := d.lookupType(t, c)
+	if err != nil || ts == nil || seen[ts] {
+		return nil
+	}
+	if v, ok := d.sampleConst(ts, c); ok {
+		return v
+	}
+	seen[ts] = true
+	v := d.sample(ts.Type, c, fake, seen)
+	delete(seen, ts)
+	return v
+}
+
+// sampleConst returns one of the constants of the named type (or a
+// bitwise OR of some of its flags) if it has any.
+func (d *JSONDoc) sampleConst(t *ast.TypeSpec, c *context) (interface{}, bool) {
+	if _, ok := t.Type.(*ast.Ident); !ok || t.Name.Obj == nil {
+		return nil, false
+	}
+	values, err := d.enumValues(t, c)
+	if err != nil || len(values) == 0 {
+		return nil, false
+	}
+	if isBitmask(values) {
+		var bits uint64
+		for _, v := range values {
+			if d.rand.Intn(2) == 1 {
+				bits |= v.bits
+			}
+		}
+		if bits == 0 {
+			bits = values[len(values)-1].bits // a flag (the zero value is rarely illustrative)
+		}
+		if bits > math.MaxInt64 {
+			return bits, true
+		}
+		return int(bits), true
+	}
+	return constJSON(values[d.rand.Intn(len(values))].Value)
+}
+
+// constJSON returns the JSON value of the constant with the given exact
+// value (see enumValue).
+func constJSON(s string) (interface{}, bool) {
+	if v, err := strconv.Unquote(s); err == nil {
+		return v, true
+	}
+	if v, err := strconv.ParseBool(s); err == nil {
+		return v, true
+	}
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return int(v), true
+	}
+	if v, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return v, true
+	}
+	if r, ok := new(big.Rat).SetString(s); ok {
+		v, _ := r.Float64()
+		return v, true
+	}
+	return nil, false
+}
+
+// sampleLen returns the number of the elements of a sample array (or
+// of the members of a sample object) within the limits.
+func sampleLen(l limits) int {
+	switch {
+	case l.Min != nil && *l.Min > 1:
+		return int(math.Min(math.Ceil(*l.Min), 100))
+	case l.Max != nil && *l.Max < 1:
+		return 0
+	}
+	return 1
+}
+
+// emptyJSON reports whether the sample value is omitted by omitempty
+// (false, 0, an empty string, array or object, or null).
+func emptyJSON(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case int:
+		return v == 0
+	case uint64:
+		return v == 0
+	case float64:
+		return v == 0
+	case []interface{}:
+		return len(v) == 0
+	case object:
+		return len(v) == 0
+	}
+	return false
+}
+
+func (d *JSONDoc) sampleFields(o *object, t *ast.StructType, c *context, seen map[*ast.TypeSpec]bool) {
+	for _, f := range t.Fields.List {
+		if typ := d.inlined(f); typ != nil {
+			if s, ok := typ.(*ast.StarExpr); ok {
+				typ = s.X
+			}
+			ts, c, err := d.lookupType(typ, c)
+			if err != nil || ts == nil || seen[ts] {
+				continue
+			}
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				seen[ts] = true
+				d.sampleFields(o, st, c, seen)
+				delete(seen, ts)
+			}
+			continue
+		}
+		for _, ident := range f.Names {
+			key, omitempty, err := d.jsonKey(ident.Name, f.Tag)
+			if err != nil {
+				continue
+			}
+			v := d.sample(f.Type, c, fieldFake(key, f), seen)
+			if omitempty && emptyJSON(v) {
+				continue
+			}
+			*o = append(*o, member{key, v})
+		}
+	}
+}
+
+// lookupType returns the type declaration of the type referred to by
+// an identifier or a selector expression in the given context. It
+// returns nil declaration (and nil error) for builtin types.
+func (d *JSONDoc) lookupType(t ast.Expr, c *context) (*ast.TypeSpec, *context, error) {
+	if ts, c, ok := d.resolveType(t, c); ok {
+		return ts, c, nil
+	}
+	t = genericType(t)
+	var o *ast.Object
+	var err error
+	switch t := t.(type) {
+	case *ast.Ident:
+		o, c, err = d.findObject(t.Name, c.Package, c.Path)
+	case *ast.SelectorExpr:
+		ident, ok := t.X.(*ast.Ident)
+		if !ok {
+			return nil, nil, fmt.Errorf("type %v: expected identifier before '.'", t)
+		}
+		var path string
+		path, err = d.findImportIdent(c.File, ident.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if path == "C" {
+			// C types of cgo are not documented (like builtin types)
+			return nil, nil, nil
+		}
+		var pkg *ast.Package
+		pkg, err = d.parsedPackage(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		o, c, err = d.findObject(t.Sel.Name, pkg, path)
+	default:
+		return nil, nil, fmt.Errorf("unsupported type %v", t)
+	}
+	if err != nil || o == nil {
+		return nil, nil, err
+	}
+	ts, ok := o.Decl.(*ast.TypeSpec)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s is not a type", o.Name)
+	}
+	ts, c = d.unalias(ts, c)
+	return ts, c, nil
+}
@@ -0,0 +1,73 @@
+package jsondoc
+
+import "testing"
+
+const sampleTestSource = `package api
+
+type status string
+
+const (
+	statusOK    status = "ok"
+	statusError status = "error"
+)
+
+type priority int
+
+const (
+	low  priority = 10
+	high priority = 20
+)
+
+type flags uint8
+
+const (
+	flagA flags = 1 << iota
+	flagB
+	flagC
+)
+
+type report struct {
+	Status   status   ` + "`json:\"status\"`" + `
+	Priority priority ` + "`json:\"priority\"`" + `
+	Flags    flags    ` + "`json:\"flags\"`" + `
+	Error    string   ` + "`json:\"error,omitempty\"`" + `
+	Tags     []string ` + "`json:\"tags\" validate:\"min=3\"`" + `
+	// max: 0
+	Notes []string ` + "`json:\"notes,omitempty\"`" + `
+}
+`
+
+func TestSampleFields(t *testing.T) {
+	d := newTestDoc(t, sampleTestSource, "# API\n", Options{})
+	if err := d.execute(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		v, err := d.sampleByName("report")
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := make(map[string]interface{})
+		for _, f := range v.(object) {
+			m[f.Key] = f.Value
+		}
+		if s := m["status"]; s != "ok" && s != "error" {
+			t.Errorf("status = %v, want one of the constants", s)
+		}
+		if p := m["priority"]; p != 10 && p != 20 {
+			t.Errorf("priority = %v, want one of the constants", p)
+		}
+		if f, ok := m["flags"].(int); !ok || f < 1 || f > 7 {
+			t.Errorf("flags = %v, want a bitwise OR of the flags", m["flags"])
+		}
+		if e, ok := m["error"]; ok {
+			t.Errorf("empty optional error = %q is present", e)
+		}
+		if n := len(m["tags"].([]interface{})); n != 3 {
+			t.Errorf("%d tags, want 3", n)
+		}
+		if _, ok := m["notes"]; ok {
+			t.Errorf("empty optional notes are present")
+		}
+	}
+}
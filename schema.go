@@ -0,0 +1,342 @@
+package jsondoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"html"
+	"strings"
+)
+
+// schemaGen generates JSON Schemas of Go types. Named types are
+// referred to with $ref (prefixed with refPrefix) and their schemas
+// are collected in defs.
+type schemaGen struct {
+	d         *JSONDoc
+	refPrefix string // such as "#/components/schemas/"
+	codec     *codec // encoding of the values (nil for JSON)
+	names     map[schemaKey]string
+	used      map[string]bool
+	defs      object // map: name -> schema (in order of definition)
+
+	// override maps type expressions to the schemas used instead of
+	// them (the data field of an envelope).
+	override map[ast.Expr]interface{}
+}
+
+// schemaKey identifies the schema of a named type in an encoding.
+type schemaKey struct {
+	t     *ast.TypeSpec
+	codec *codec
+}
+
+func newSchemaGen(d *JSONDoc, refPrefix string) *schemaGen {
+	return &schemaGen{d: d, refPrefix: refPrefix, names: make(map[schemaKey]string), used: make(map[string]bool),
+		override: make(map[ast.Expr]interface{})}
+}
+
+// ref returns the schema referring to the named type (generating its
+// definition if needed).
+func (g *schemaGen) ref(t *ast.TypeSpec, c *context) (object, error) {
+	name, err := g.define(t, c)
+	if err != nil {
+		return nil, err
+	}
+	return object{{"$ref", g.refPrefix + name}}, nil
+}
+
+// define generates the definition of the named type (if not already
+// generated) and returns its name in defs.
+func (g *schemaGen) define(t *ast.TypeSpec, c *context) (string, error) {
+	key := schemaKey{t, g.codec}
+	if name := g.names[key]; name != "" {
+		return name, nil
+	}
+	name := t.Name.Name
+	if g.codec != nil {
+		name += "_" + g.codec.Tag
+	}
+	name = g.uniqueName(name, c)
+	g.names[key] = name
+	i := len(g.defs)
+	g.defs = append(g.defs, member{name, nil})
+	s, err := g.schema(t.Type, c)
+	if err != nil {
+		return "", fmt.Errorf("type %s: %v", t.Name.Name, err)
+	}
+	if doc := typeDoc(t, c); doc != "" {
+		if o, ok := s.(object); ok {
+			s = append(o, member{"description", doc})
+		}
+	}
+	g.defs[i].Value = s
+	return name, nil
+}
+
+func (g *schemaGen) uniqueName(name string, c *context) string {
+	if g.used[name] && c != nil {
+		name += "_" + c.Package.Name
+	}
+	s := name
+	for i := 2; g.used[s]; i++ {
+		s = fmt.Sprintf("%s%d", name, i)
+	}
+	g.used[s] = true
+	return s
+}
+
+// typeDoc returns the doc comment of the type declaration (if any)
+// without the directives.
+func typeDoc(t *ast.TypeSpec, c *context) string {
+	var lines []string
+	for _, line := range strings.Split(typeSpecDoc(t, c).Text(), "\n") {
+		if m := directiveRe.FindStringSubmatch(strings.TrimSpace(line)); m == nil || typeDirectives[m[1]] == "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// schema returns the JSON Schema of the type expression.
+func (g *schemaGen) schema(t ast.Expr, c *context) (interface{}, error) {
+	if s, ok := g.override[t]; ok {
+		return s, nil
+	}
+	switch t := t.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return object{{"type", "string"}}, nil
+		case "bool":
+			return object{{"type", "boolean"}}, nil
+		case "float32":
+			return object{{"type", "number"}, {"format", "float"}}, nil
+		case "float64":
+			return object{{"type", "number"}, {"format", "double"}}, nil
+		case "int32", "rune":
+			return object{{"type", "integer"}, {"format", "int32"}}, nil
+		case "int64":
+			return object{{"type", "integer"}, {"format", "int64"}}, nil
+		case "int", "int8", "int16", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte":
+			return object{{"type", "integer"}}, nil
+		case "any":
+			return object{}, nil
+		case "error":
+			return object{{"type", "string"}}, nil
+		}
+		return g.named(t, c)
+	case *ast.SelectorExpr:
+		if name, ok := g.d.cType(t, c); ok {
+			if float, ok := cNumeric[name]; ok && float {
+				return object{{"type", "number"}}, nil
+			} else if ok {
+				return object{{"type", "integer"}}, nil
+			}
+			return object{}, nil
+		}
+		if ident, ok := t.X.(*ast.Ident); ok {
+			if path, err := g.d.findImportIdent(c.File, ident.Name); err == nil {
+				switch path + "." + t.Sel.Name {
+				case "time.Time":
+					return object{{"type", "string"}, {"format", "date-time"}}, nil
+				case "time.Duration":
+					return object{{"type", "integer"}, {"format", "int64"}}, nil
+				case "encoding/json.RawMessage":
+					return object{}, nil
+				}
+			}
+		}
+		return g.named(t, c)
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		// the schema of the generic type (with any values of its type
+		// parameters)
+		return g.named(t, c)
+	case *ast.StarExpr:
+		return g.schema(t.X, c)
+	case *ast.InterfaceType:
+		return object{}, nil
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && (ident.Name == "byte" || ident.Name == "uint8") {
+			return object{{"type", "string"}, {"format", "byte"}}, nil
+		}
+		items, err := g.schema(t.Elt, c)
+		if err != nil {
+			return nil, err
+		}
+		return object{{"type", "array"}, {"items", items}}, nil
+	case *ast.MapType:
+		values, err := g.schema(t.Value, c)
+		if err != nil {
+			return nil, err
+		}
+		return object{{"type", "object"}, {"additionalProperties", values}}, nil
+	case *ast.StructType:
+		var props object
+		var required []string
+		if err := g.fields(&props, &required, t, c); err != nil {
+			return nil, err
+		}
+		s := object{{"type", "object"}}
+		if len(props) > 0 {
+			s = append(s, member{"properties", props})
+		}
+		if len(required) > 0 {
+			s = append(s, member{"required", required})
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("unsupported type %v", t)
+}
+
+func (g *schemaGen) named(t ast.Expr, c *context) (interface{}, error) {
+	ts, c, err := g.d.lookupType(t, c)
+	if err != nil {
+		return nil, err
+	}
+	if ts == nil {
+		return object{}, nil
+	}
+	return g.ref(ts, c)
+}
+
+// fields appends the properties (and names of required properties) of
+// the struct type (including the fields of embedded structs).
+func (g *schemaGen) fields(props *object, required *[]string, t *ast.StructType, c *context) error {
+	for _, f := range t.Fields.List {
+		if typ := g.d.inlined(f); typ != nil {
+			if s, ok := typ.(*ast.StarExpr); ok {
+				typ = s.X
+			}
+			ts, c, err := g.d.lookupType(typ, c)
+			if err != nil {
+				return err
+			}
+			if ts == nil {
+				continue
+			}
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				if err := g.fields(props, required, st, c); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		for _, ident := range f.Names {
+			key, omitempty, err := g.d.jsonKey(ident.Name, f.Tag)
+			if g.codec != nil {
+				key, omitempty, err = g.codec.key(ident.Name, f.Tag)
+			}
+			if err == NotExported {
+				continue
+			} else if err != nil {
+				return err
+			}
+			s, err := g.schema(f.Type, c)
+			if err != nil {
+				return err
+			}
+			fc := parseFieldComment(f)
+			if fc.Format != "" {
+				s = withFormat(s, fc.Format)
+			}
+			l, err := fieldLimits(f, fc)
+			if err != nil {
+				return fmt.Errorf("field %s: %v", ident.Name, err)
+			}
+			s = withLimits(s, l, g.d.valueKind(f.Type, c))
+			if desc := fc.schemaDescription(); desc != "" {
+				s = withDescription(s, desc)
+			}
+			*props = append(*props, member{key, s})
+			if !omitempty {
+				*required = append(*required, key)
+			}
+		}
+	}
+	return nil
+}
+
+// withDescription returns the schema with the description added (a
+// $ref is wrapped in allOf as its siblings are ignored).
+func withDescription(s interface{}, doc string) interface{} {
+	o, ok := s.(object)
+	if !ok {
+		return s
+	}
+	if len(o) == 1 && o[0].Key == "$ref" {
+		return object{{"allOf", []interface{}{o}}, {"description", doc}}
+	}
+	return append(o[:len(o):len(o)], member{"description", doc})
+}
+
+// withFormat returns the schema with the format keyword set (a $ref is
+// wrapped in allOf as its siblings are ignored).
+func withFormat(s interface{}, format string) interface{} {
+	o, ok := s.(object)
+	if !ok {
+		return s
+	}
+	if len(o) == 1 && o[0].Key == "$ref" {
+		return object{{"allOf", []interface{}{o}}, {"format", format}}
+	}
+	o = append(object(nil), o...)
+	if i := o.index("format"); i != -1 {
+		o[i].Value = format
+		return o
+	}
+	return append(o, member{"format", format})
+}
+
+// withLimits returns the schema with the keywords of the limits of the
+// values of the given kind added (a $ref is wrapped in allOf as its
+// siblings are ignored).
+func withLimits(s interface{}, l limits, kind string) interface{} {
+	keywords, ok := limitKeywords[kind]
+	o, isObject := s.(object)
+	if !ok || !isObject || l.Min == nil && l.Max == nil {
+		return s
+	}
+	if len(o) == 1 && o[0].Key == "$ref" {
+		o = object{{"allOf", []interface{}{o}}}
+	} else {
+		o = append(object(nil), o...)
+	}
+	for i, v := range []*float64{l.Min, l.Max} {
+		if v == nil {
+			continue
+		}
+		var n interface{} = *v
+		if kind != "number" {
+			n = int(*v)
+		}
+		o = append(o, member{keywords[i], n})
+	}
+	return o
+}
+
+// jsonSchemaDialect is the JSON Schema version of the schemas embedded
+// with schemaInline.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaInline embeds the JSON Schema of the type given by name (as in
+// the input and output actions) with the schemas of the referenced
+// named types in $defs as a collapsible code block (for the readers
+// copying it into their own validators).
+func (d *JSONDoc) schemaInline(name string) (string, error) {
+	g := newSchemaGen(d, "#/$defs/")
+	t, c, err := d.lookupTypeName(name)
+	if err != nil {
+		return "", fmt.Errorf("schemaInline %s: %v", name, err)
+	}
+	root, err := g.define(t, c)
+	if err != nil {
+		return "", fmt.Errorf("schemaInline %s: %v", name, err)
+	}
+	s := object{{"$schema", jsonSchemaDialect}, {"$ref", "#/$defs/" + root}, {"$defs", g.defs}}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("<details class=\"schema\">\n<summary>JSON Schema of %s</summary>\n<pre class=\"example\"><code class=\"language-json\">%s</code></pre>\n</details>\n",
+		html.EscapeString(typeIdent(name)), html.EscapeString(string(b))), nil
+}
@@ -0,0 +1,117 @@
+package jsondoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"html"
+	"strings"
+)
+
+// The search index lists the types and the fields of the whole
+// documentation so the search action leaves a placeholder replaced
+// with the index when the template was executed.
+
+const searchPlaceholder = "<!--jsondoc-search-->"
+
+// searchEntry is a field or a type found with the search box.
+type searchEntry struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`           // "field" or "type"
+	Type      string `json:"type,omitempty"` // the type the field belongs to
+	ID        string `json:"id"`             // id of the section of the type
+	Endpoints []int  `json:"endpoints"`      // indexes of the endpoints referring to the type
+}
+
+type searchEndpoint struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// search marks the place of the search box matching (fuzzily) the key
+// names of the fields and the names of the types of the documentation
+// and showing the endpoints they are used in.
+func (d *JSONDoc) search() string {
+	d.searchUsed = true
+	return "<div class=\"search\">\n" + searchPlaceholder + "\n</div>\n"
+}
+
+// addSearchFields records the fields of the object type being rendered
+// (in the section or the type given by graphParent) for the search
+// index.
+func (d *JSONDoc) addSearchFields(fields []field) {
+	if d.graphParent == "" {
+		return
+	}
+	for _, f := range fields {
+		name := html.UnescapeString(strings.TrimSuffix(f.Name, " (optional)"))
+		if s, err := unquoteKey(name); err == nil {
+			name = s
+		}
+		d.searchFields = append(d.searchFields, searchEntry{Name: name, Kind: "field", Type: d.typeName, ID: d.graphParent})
+	}
+}
+
+// unquoteKey returns the object key given (quoted) in the tables.
+func unquoteKey(s string) (string, error) {
+	var key string
+	err := json.Unmarshal([]byte(s), &key)
+	return key, err
+}
+
+// resolveSearch returns the markdown with the search box and its index
+// inserted in place of the search action.
+func (d *JSONDoc) resolveSearch(md []byte) ([]byte, error) {
+	if !d.searchUsed {
+		return md, nil
+	}
+	index := struct {
+		Endpoints []searchEndpoint `json:"endpoints"`
+		Entries   []searchEntry    `json:"entries"`
+	}{Endpoints: []searchEndpoint{}}
+	reach := make(map[string][]int) // map: section or type id -> endpoints referring to it
+	for i, e := range d.endpoints {
+		index.Endpoints = append(index.Endpoints, searchEndpoint{e.ID, e.Title()})
+		for id := range d.reachable(e.ID) {
+			reach[id] = append(reach[id], i)
+		}
+	}
+	for _, t := range d.namedTypes {
+		index.Entries = append(index.Entries, searchEntry{Name: t.Name, Kind: "type", ID: t.ID})
+	}
+	index.Entries = append(index.Entries, d.searchFields...)
+	for i := range index.Entries {
+		index.Entries[i].Endpoints = reach[index.Entries[i].ID]
+		if index.Entries[i].Endpoints == nil {
+			index.Entries[i].Endpoints = []int{}
+		}
+	}
+	b, err := json.Marshal(index)
+	if err != nil {
+		return nil, err
+	}
+	var s bytes.Buffer
+	s.WriteString("<label for=\"jsondoc-search\">Search fields and types</label>\n")
+	s.WriteString("<input type=\"search\" id=\"jsondoc-search\" autocomplete=\"off\" placeholder=\"such as request_id\" aria-controls=\"jsondoc-search-results\">\n")
+	s.WriteString("<ul id=\"jsondoc-search-results\" aria-live=\"polite\"></ul>\n")
+	s.WriteString("<script type=\"application/json\" id=\"jsondoc-search-index\">")
+	s.Write(b)
+	s.WriteString("</script>")
+	return bytes.Replace(md, []byte(searchPlaceholder), s.Bytes(), 1), nil
+}
+
+// reachable returns the ids of the sections and the types referred to
+// (directly or indirectly) from the section with the given id.
+func (d *JSONDoc) reachable(id string) map[string]bool {
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+	for len(queue) > 0 {
+		id, queue = queue[0], queue[1:]
+		for _, s := range d.graph[id] {
+			if !seen[s] {
+				seen[s] = true
+				queue = append(queue, s)
+			}
+		}
+	}
+	return seen
+}
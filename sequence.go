@@ -0,0 +1,91 @@
+package jsondoc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The sequence action may refer to endpoints documented later in the
+// template so it leaves a placeholder replaced with the diagram when
+// the whole template was executed.
+
+var sequencePlaceholderRe = regexp.MustCompile(`<!--jsondoc-sequence:([0-9]+)-->`)
+
+// sequence returns (a placeholder of) the mermaid sequence diagram of
+// the workflow composed of the given documented endpoints (such as
+// "POST /orders") in order: the requests sent by the client and the
+// responses of the server.
+func (d *JSONDoc) sequence(steps ...string) (string, error) {
+	if len(steps) == 0 {
+		return "", errors.New("sequence: no endpoints given")
+	}
+	d.sequences = append(d.sequences, steps)
+	return fmt.Sprintf("<!--jsondoc-sequence:%d-->", len(d.sequences)-1), nil
+}
+
+// findEndpoint returns the documented endpoint given as "METHOD /path"
+// (or nil if not found).
+func (d *JSONDoc) findEndpoint(s string) *endpoint {
+	f := strings.Fields(s)
+	if len(f) != 2 {
+		return nil
+	}
+	for _, e := range d.endpoints {
+		if e.Method == strings.ToUpper(f[0]) && e.Path == f[1] {
+			return e
+		}
+	}
+	return nil
+}
+
+// resolveSequences returns the markdown with the placeholders of the
+// sequence actions replaced with the diagrams.
+func (d *JSONDoc) resolveSequences(md []byte) ([]byte, error) {
+	var err error
+	md = sequencePlaceholderRe.ReplaceAllFunc(md, func(b []byte) []byte {
+		i, _ := strconv.Atoi(string(sequencePlaceholderRe.FindSubmatch(b)[1]))
+		s, e := d.sequenceDiagram(d.sequences[i])
+		if e != nil && err == nil {
+			err = e
+		}
+		return []byte(s)
+	})
+	return md, err
+}
+
+// sequenceDiagram returns the fenced mermaid code block of the sequence
+// diagram of the given endpoints.
+func (d *JSONDoc) sequenceDiagram(steps []string) (string, error) {
+	var b bytes.Buffer
+	b.WriteString("\n```mermaid\nsequenceDiagram\n    participant Client\n    participant Server\n")
+	for _, s := range steps {
+		e := d.findEndpoint(s)
+		if e == nil {
+			return "", fmt.Errorf("sequence: endpoint %q is not documented", s)
+		}
+		request := e.Title()
+		if e.Input != "" {
+			request += " (" + typeIdent(e.Input) + ")"
+		}
+		response := "204 No Content"
+		if e.NoOutputStatus != 0 {
+			response = statusLine(e.NoOutputStatus)
+		}
+		if e.File != nil {
+			response = "file (" + e.File.ContentType + ")"
+		}
+		if e.Output != "" {
+			response = containerTitle(e.Container, typeIdent(e.Output))
+			if e.Envelope != "" {
+				response += " in " + typeIdent(e.Envelope)
+			}
+		}
+		fmt.Fprintf(&b, "    Client->>Server: %s\n    Server-->>Client: %s\n", request, response)
+	}
+	b.WriteString("```\n")
+	return b.String(), nil
+}
@@ -0,0 +1,43 @@
+package jsondoc
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+)
+
+// server is a base URL of the API in some environment.
+type server struct {
+	Name string // such as "Production" or "Sandbox"
+	URL  string
+}
+
+// servers declares the base URLs of the API given as name and URL
+// pairs and returns the table listing them. The first server is used
+// by the snippets and the "Try it" consoles unless -base-url is given.
+func (d *JSONDoc) servers(args ...string) (string, error) {
+	if len(args) == 0 || len(args)%2 != 0 {
+		return "", fmt.Errorf("servers: expected name and URL pairs")
+	}
+	var b bytes.Buffer
+	b.WriteString("<div>\n<table>\n<caption>Servers of the API</caption>\n<tr>\n<th scope=\"col\">Environment</th>\n<th scope=\"col\">Base URL</th>\n</tr>\n")
+	for i := 0; i < len(args); i += 2 {
+		s := server{args[i], args[i+1]}
+		d.serverList = append(d.serverList, s)
+		fmt.Fprintf(&b, "<tr>\n<td>%s</td>\n<td><code>%s</code></td>\n</tr>\n", html.EscapeString(s.Name), html.EscapeString(s.URL))
+	}
+	b.WriteString("</table>\n</div>\n")
+	return b.String(), nil
+}
+
+// apiBaseURL returns the base URL used in examples: the one given with
+// -base-url, the first declared server or fallback (in this order).
+func (d *JSONDoc) apiBaseURL(fallback string) string {
+	if d.baseURL != "" {
+		return d.baseURL
+	}
+	if len(d.serverList) > 0 {
+		return d.serverList[0].URL
+	}
+	return fallback
+}
@@ -0,0 +1,349 @@
+package jsondoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// snippetLang is a language of request snippets.
+type snippetLang struct {
+	Name  string // used in HTML attributes
+	Title string // displayed on the tab
+	gen   func(r *snippetRequest) string
+}
+
+// snippetLangs lists the supported languages of the snippets.
+var snippetLangs = []snippetLang{
+	{"curl", "curl", curlSnippet},
+	{"httpie", "HTTPie", httpieSnippet},
+	{"fetch", "JavaScript (fetch)", fetchSnippet},
+	{"axios", "JavaScript (axios)", axiosSnippet},
+	{"python", "Python (requests)", pythonSnippet},
+}
+
+// defaultSnippets lists the languages of snippets used if not
+// configured otherwise.
+var defaultSnippets = []string{"curl", "fetch", "axios", "python"}
+
+func findSnippetLang(name string) *snippetLang {
+	for i := range snippetLangs {
+		if snippetLangs[i].Name == name {
+			return &snippetLangs[i]
+		}
+	}
+	return nil
+}
+
+// snippetRequest is the request shown in the snippets.
+type snippetRequest struct {
+	Method string
+	URL    string
+	Body   interface{} // sample input (nil if the endpoint has no input)
+	Form   []member    // sample form (string and sampleFile values)
+
+	Multipart bool // the form is sent as multipart/form-data
+
+	Raw         string // sample input in other format (such as XML)
+	ContentType string // content type of Raw
+
+	TextOutput bool // the output is not JSON
+}
+
+// body returns the JSON body indented with the given prefix.
+func (r *snippetRequest) body(prefix string) string {
+	b, err := json.MarshalIndent(r.Body, prefix, "  ")
+	if err != nil {
+		return "null"
+	}
+	return string(b)
+}
+
+// defaultBaseURL is used in snippets if neither -base-url is given
+// nor servers are declared.
+const defaultBaseURL = "https://api.example.com"
+
+// snippets returns the HTML of the request snippets (in all supported
+// languages selectable with tabs) for the current endpoint.
+func (d *JSONDoc) snippets() (string, error) {
+	e := d.currentEndpoint()
+	if e == nil {
+		return "", errors.New("snippets must follow an endpoint")
+	}
+	if e.codec != nil {
+		return "", fmt.Errorf("snippets: %s is not supported", e.codec.ContentType)
+	}
+	r, err := d.sampleRequest(e)
+	if err != nil {
+		return "", fmt.Errorf("snippets: %v", err)
+	}
+	names := d.config.Snippets
+	if len(names) == 0 {
+		names = defaultSnippets
+	}
+	var langs []*snippetLang
+	for _, name := range names {
+		langs = append(langs, findSnippetLang(name))
+	}
+	var b bytes.Buffer
+	b.WriteString("<div class=\"snippets\">\n<p class=\"snippet-tabs\">")
+	for _, l := range langs {
+		fmt.Fprintf(&b, `<button type="button" data-lang="%s">%s</button>`, l.Name, html.EscapeString(l.Title))
+	}
+	b.WriteString("</p>\n")
+	for _, l := range langs {
+		// empty lines would end the HTML block in CommonMark
+		code := strings.Replace(html.EscapeString(l.gen(r)), "\n\n", "\n&#10;", -1)
+		fmt.Fprintf(&b, "<pre class=\"snippet\" data-lang=\"%s\"><code>%s</code></pre>\n", l.Name, code)
+	}
+	b.WriteString("</div>\n")
+	d.snippetsUsed = true
+	return b.String(), nil
+}
+
+// sampleRequest returns the sample request of the endpoint (as shown
+// in the snippets).
+func (d *JSONDoc) sampleRequest(e *endpoint) (*snippetRequest, error) {
+	r := &snippetRequest{Method: e.Method, URL: d.apiBaseURL(defaultBaseURL) + e.Path, TextOutput: e.OutputContentType != ""}
+	if e.InputContentType == multipartContentType || e.InputContentType == urlEncodedContentType {
+		form, err := d.formSample(e)
+		if err != nil {
+			return nil, err
+		}
+		r.Form = form
+		r.Multipart = e.InputContentType == multipartContentType
+	} else if e.InputContentType == xmlContentType {
+		s, err := d.sampleXML(e.Input)
+		if err != nil {
+			return nil, err
+		}
+		r.Raw, r.ContentType = s, xmlContentType
+	} else if e.InputContentType == ndjsonContentType {
+		v, err := d.inputSample(e)
+		if err != nil {
+			return nil, err
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		r.Raw, r.ContentType = string(b), ndjsonContentType
+	} else if e.InputContentType != "" {
+		return nil, fmt.Errorf("%s input is not supported", e.InputContentType)
+	} else if e.Input != "" {
+		v, err := d.inputSample(e)
+		if err != nil {
+			return nil, err
+		}
+		r.Body = v
+	}
+	return r, nil
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// formItem returns the form part m in the syntax of curl -F and HTTPie
+// (files are given by name after sep).
+func formItem(m member, sep string) string {
+	if f, ok := m.Value.(sampleFile); ok {
+		return m.Key + sep + f.Name + ";type=" + f.ContentType
+	}
+	return m.Key + "=" + m.Value.(string)
+}
+
+func curlSnippet(r *snippetRequest) string {
+	s := "curl -X " + r.Method + " " + shellQuote(r.URL)
+	for _, m := range r.Form {
+		if r.Multipart {
+			s += " \\\n  -F " + shellQuote(formItem(m, "=@"))
+		} else {
+			s += " \\\n  --data-urlencode " + shellQuote(formItem(m, "=@"))
+		}
+	}
+	if r.Body != nil {
+		s += " \\\n  -H 'Content-Type: application/json' \\\n  -d " + shellQuote(r.body("  "))
+	}
+	if r.Raw != "" {
+		s += " \\\n  -H " + shellQuote("Content-Type: "+r.ContentType) + " \\\n  --data-binary " + shellQuote(r.Raw)
+	}
+	return s
+}
+
+// httpieSafe matches HTTPie request items which need not be quoted.
+var httpieSafe = regexp.MustCompile(`^[A-Za-z0-9_./:=@,+-]*$`)
+
+func httpieSnippet(r *snippetRequest) string {
+	items := []string{"http", r.Method, r.URL}
+	if len(r.Form) > 0 {
+		items = []string{"http", "--form", r.Method, r.URL}
+		for _, m := range r.Form {
+			item := formItem(m, "@")
+			if !httpieSafe.MatchString(item) {
+				item = shellQuote(item)
+			}
+			items = append(items, item)
+		}
+		return strings.Join(items[:4], " ") + " \\\n  " + strings.Join(items[4:], " \\\n  ")
+	}
+	if r.Raw != "" {
+		return "echo " + shellQuote(r.Raw) + " | \\\n  http " + r.Method + " " + r.URL + " " + shellQuote("Content-Type:"+r.ContentType)
+	}
+	o, ok := r.Body.(object)
+	if r.Body != nil && !ok {
+		items = append([]string{"echo", shellQuote(r.body("")), "|"}, items...)
+	}
+	for _, m := range o {
+		item := m.Key + "=" + fmt.Sprint(m.Value)
+		if _, ok := m.Value.(string); !ok {
+			b, _ := json.Marshal(m.Value)
+			item = m.Key + ":=" + string(b)
+		}
+		if !httpieSafe.MatchString(item) {
+			item = shellQuote(item)
+		}
+		items = append(items, item)
+	}
+	if len(o) > 0 {
+		// keep the request line and put each field on a separate line
+		return strings.Join(items[:3], " ") + " \\\n  " + strings.Join(items[3:], " \\\n  ")
+	}
+	return strings.Join(items, " ")
+}
+
+// formDataSnippet returns JavaScript code building the form as
+// FormData (files are taken from a file input element) or, if it is
+// not multipart, as URLSearchParams.
+func formDataSnippet(r *snippetRequest) string {
+	s := "const form = new URLSearchParams();\n"
+	if r.Multipart {
+		s = "const form = new FormData();\n"
+	}
+	for _, m := range r.Form {
+		if f, ok := m.Value.(sampleFile); ok {
+			s += fmt.Sprintf("form.append(%s, fileInput.files[0], %s);\n", jsString(m.Key), jsString(f.Name))
+			continue
+		}
+		s += fmt.Sprintf("form.append(%s, %s);\n", jsString(m.Key), jsString(m.Value.(string)))
+	}
+	return s
+}
+
+func fetchSnippet(r *snippetRequest) string {
+	s := fmt.Sprintf("const response = await fetch(%s, {\n  method: %q,\n", jsString(r.URL), r.Method)
+	if r.Form != nil {
+		s = formDataSnippet(r) + s + "  body: form,\n"
+	}
+	if r.Body != nil {
+		s += "  headers: {\"Content-Type\": \"application/json\"},\n  body: JSON.stringify(" + r.body("  ") + "),\n"
+	}
+	if r.Raw != "" {
+		s += "  headers: {\"Content-Type\": " + jsString(r.ContentType) + "},\n  body: " + jsString(r.Raw) + ",\n"
+	}
+	if r.TextOutput {
+		return s + "});\nconst data = await response.text();"
+	}
+	return s + "});\nconst data = await response.json();"
+}
+
+func axiosSnippet(r *snippetRequest) string {
+	s := fmt.Sprintf("const { data } = await axios({\n  method: %q,\n  url: %s,\n", strings.ToLower(r.Method), jsString(r.URL))
+	if r.Form != nil {
+		s = formDataSnippet(r) + s + "  data: form,\n"
+	}
+	if r.Body != nil {
+		s += "  data: " + r.body("  ") + ",\n"
+	}
+	if r.Raw != "" {
+		s += "  headers: {\"Content-Type\": " + jsString(r.ContentType) + "},\n  data: " + jsString(r.Raw) + ",\n"
+	}
+	return s + "});"
+}
+
+func pythonSnippet(r *snippetRequest) string {
+	var s string
+	switch r.Method {
+	case "GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS":
+		s = fmt.Sprintf("response = requests.%s(\n    %s,\n", strings.ToLower(r.Method), jsString(r.URL))
+	default:
+		s = fmt.Sprintf("response = requests.request(\n    %q,\n    %s,\n", r.Method, jsString(r.URL))
+	}
+	if r.Body != nil {
+		s += "    json=" + pythonValue(r.Body, "    ") + ",\n"
+	}
+	if r.Raw != "" {
+		s += "    headers={\"Content-Type\": " + jsString(r.ContentType) + "},\n    data=" + jsString(r.Raw) + ",\n"
+	}
+	if r.Form != nil {
+		data, files := "", ""
+		for _, m := range r.Form {
+			if f, ok := m.Value.(sampleFile); ok {
+				files += fmt.Sprintf("        %s: (%s, open(%s, \"rb\"), %s),\n", jsString(m.Key), jsString(f.Name), jsString(f.Name), jsString(f.ContentType))
+				continue
+			}
+			data += fmt.Sprintf("        %s: %s,\n", jsString(m.Key), jsString(m.Value.(string)))
+		}
+		if data != "" {
+			s += "    data={\n" + data + "    },\n"
+		}
+		if files != "" {
+			s += "    files={\n" + files + "    },\n"
+		}
+	}
+	if r.TextOutput {
+		return "import requests\n\n" + s + ")\nresponse.raise_for_status()\ndata = response.text"
+	}
+	return "import requests\n\n" + s + ")\nresponse.raise_for_status()\ndata = response.json()"
+}
+
+// pythonValue returns the Python literal of the JSON value v (with
+// nested lines indented with the given prefix).
+func pythonValue(v interface{}, prefix string) string {
+	switch v := v.(type) {
+	case nil:
+		return "None"
+	case bool:
+		if v {
+			return "True"
+		}
+		return "False"
+	case object:
+		if len(v) == 0 {
+			return "{}"
+		}
+		s := "{\n"
+		for _, m := range v {
+			s += prefix + "    " + jsString(m.Key) + ": " + pythonValue(m.Value, prefix+"    ") + ",\n"
+		}
+		return s + prefix + "}"
+	case []interface{}:
+		if len(v) == 0 {
+			return "[]"
+		}
+		s := "[\n"
+		for _, e := range v {
+			s += prefix + "    " + pythonValue(e, prefix+"    ") + ",\n"
+		}
+		return s + prefix + "]"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "None"
+	}
+	return string(b)
+}
+
+// jsString returns s as a JavaScript string literal.
+func jsString(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
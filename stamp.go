@@ -0,0 +1,94 @@
+package jsondoc
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"os/exec"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stamp holds the generation metadata embedded in the documentation
+// (with -stamp) so that published documents are traceable to the
+// source revision.
+type stamp struct {
+	Version string    // version of jsondoc
+	Commit  string    // git commit of the documented module (if known)
+	Time    time.Time // time of generation
+}
+
+// newStamp returns the generation metadata. If commit is empty it is
+// obtained with git from the given directory (if possible). The time
+// is taken from SOURCE_DATE_EPOCH (if set) for reproducible builds.
+func newStamp(dir, commit string) (*stamp, error) {
+	s := &stamp{Version: jsondocVersion(), Commit: commit}
+	if s.Commit == "" {
+		cmd := exec.Command("git", "rev-parse", "HEAD")
+		cmd.Dir = dir
+		if out, err := cmd.Output(); err == nil {
+			s.Commit = strings.TrimSpace(string(out))
+		}
+	}
+	t, err := generationTime()
+	if err != nil {
+		return nil, err
+	}
+	s.Time = t
+	return s, nil
+}
+
+// jsondocVersion returns the version of the jsondoc module ("(devel)"
+// if not known).
+func jsondocVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		// jsondoc is the main module of the command and a dependency
+		// of services using Handler
+		mods := append([]*debug.Module{&info.Main}, info.Deps...)
+		for _, m := range mods {
+			if m.Path == "github.com/lukpank/jsondoc" && m.Version != "" {
+				return m.Version
+			}
+		}
+	}
+	return "(devel)"
+}
+
+// generationTime returns the time of generation: the current time or
+// the time given with SOURCE_DATE_EPOCH (if set) for reproducible
+// builds.
+func generationTime() (time.Time, error) {
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		sec, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH: %v", err)
+		}
+		return time.Unix(sec, 0).UTC(), nil
+	}
+	return time.Now().UTC(), nil
+}
+
+// head returns the meta tags with the metadata.
+func (s *stamp) head() string {
+	h := fmt.Sprintf("<meta name=\"generator\" content=\"jsondoc %s\">\n", html.EscapeString(s.Version))
+	if s.Commit != "" {
+		h += fmt.Sprintf("<meta name=\"jsondoc:commit\" content=\"%s\">\n", html.EscapeString(s.Commit))
+	}
+	return h + fmt.Sprintf("<meta name=\"jsondoc:generated\" content=\"%s\">\n", s.Time.Format(time.RFC3339))
+}
+
+// footer returns the footer with the metadata (with the time in the
+// given layout).
+func (s *stamp) footer(layout string) string {
+	f := "<footer class=\"stamp\">Generated by jsondoc " + html.EscapeString(s.Version)
+	if s.Commit != "" {
+		commit := s.Commit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		f += " from commit <code>" + html.EscapeString(commit) + "</code>"
+	}
+	return f + " on " + s.Time.Format(layout) + ".</footer>\n"
+}
@@ -0,0 +1,60 @@
+package jsondoc
+
+import (
+	"fmt"
+	"html"
+	"os"
+)
+
+// Stats are the statistics of the generated documentation.
+type Stats struct {
+	Endpoints     int // documented endpoints
+	Types         int // rendered named and anonymous types
+	Undescribed   int // fields of the rendered types with no description
+	ExternalTypes int // types of other packages referenced but not expanded
+	Warnings      int // warnings reported while generating
+}
+
+// Stats returns the statistics of the documentation once the template
+// was executed (by any of the Write methods).
+func (d *JSONDoc) Stats() Stats {
+	if !d.calls.done.Load() {
+		return Stats{}
+	}
+	s := d.stats()
+	d.calls.mu.Lock()
+	defer d.calls.mu.Unlock()
+	s.Warnings += d.calls.warnings
+	return s
+}
+
+func (d *JSONDoc) stats() Stats {
+	return Stats{len(d.endpoints), d.renderedTypes, d.undescribed, len(d.externalTypes), d.warnings}
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%s, %s, %s without description, %s, %s", plural(s.Endpoints, "endpoint"), plural(s.Types, "type"),
+		plural(s.Undescribed, "field"), plural(s.ExternalTypes, "external type"), plural(s.Warnings, "warning"))
+}
+
+// plural returns the number followed by the noun (in plural form if
+// needed).
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// footer returns the footer with the statistics embedded in the HTML
+// output.
+func (s Stats) footer() string {
+	return "<footer class=\"stats\">Documentation statistics: " + html.EscapeString(s.String()) + ".</footer>\n"
+}
+
+// warnf reports the warning (on standard error) counting it in the
+// statistics.
+func (d *JSONDoc) warnf(format string, args ...interface{}) {
+	d.warnings++
+	fmt.Fprintf(os.Stderr, format, args...)
+}
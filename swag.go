@@ -0,0 +1,198 @@
+package jsondoc
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// With -swag the handler action also reads the annotations of
+// swaggo/swag (such as "@Summary", "@Param", "@Success" and "@Router")
+// in the doc comments of the handlers so that projects documented with
+// swag may be documented with jsondoc without rewriting the comments.
+
+// swagAnnotations are the metadata of the endpoint of a handler given
+// with the annotations of swag (other than its input and output types,
+// see handlerTypes).
+type swagAnnotations struct {
+	Summary     string
+	Description string
+	Method      string
+	Path        string
+	Params      []swagParam // parameters other than the body
+	Errors      []swagError // error types (in the order of the first @Failure)
+}
+
+// swagParam is a parameter of the request given with @Param.
+type swagParam struct {
+	Name, In, Type string
+	Required       bool
+	Description    string
+}
+
+// swagError is an error type of the endpoint and its status codes
+// (given with @Failure).
+type swagError struct {
+	Type  string
+	Codes []string
+}
+
+var (
+	swagRouterRe      = regexp.MustCompile(`^(\S+)\s+\[(\w+)\]`)
+	swagResponseRe    = regexp.MustCompile(`^(\d+)\s+\{(\w+)\}\s+(\S+)`)
+	swagCompositionRe = regexp.MustCompile(`^([\w.\[\]*]+)\{(\w+)=([^{},]+)\}$`)
+)
+
+// parseSwag returns the annotations of swag of the handler setting the
+// input and output types not yet known to the types of the body
+// parameter and of the first successful response.
+func (d *JSONDoc) parseSwag(fn *ast.FuncDecl, c *context, h *handlerTypes) swagAnnotations {
+	var s swagAnnotations
+	var desc []string
+	for _, line := range strings.Split(fn.Doc.Text(), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "@") {
+			continue
+		}
+		name, arg := line, ""
+		if i := strings.IndexAny(line, " \t"); i != -1 {
+			name, arg = line[:i], strings.TrimSpace(line[i+1:])
+		}
+		switch strings.ToLower(name) {
+		case "@summary":
+			s.Summary = arg
+		case "@description":
+			desc = append(desc, arg)
+		case "@router":
+			if m := swagRouterRe.FindStringSubmatch(arg); m != nil {
+				s.Method, s.Path = strings.ToUpper(m[2]), m[1]
+			}
+		case "@param":
+			p, ok := parseSwagParam(arg)
+			if !ok {
+				d.warnf("handler %s: invalid @Param %q", fn.Name.Name, arg)
+			} else if p.In != "body" {
+				s.Params = append(s.Params, p)
+			} else if h.Input == "" {
+				if name, container := d.swagType(p.Type, c); container == "" {
+					h.Input = name
+				}
+			}
+		case "@success":
+			m := swagResponseRe.FindStringSubmatch(arg)
+			if m == nil || h.Output != "" || (m[2] != "object" && m[2] != "array") {
+				break
+			}
+			env, data := "", m[3]
+			if cm := swagCompositionRe.FindStringSubmatch(m[3]); cm != nil {
+				env, data = cm[1], cm[3]
+			}
+			name, container := d.swagType(data, c)
+			if name == "" {
+				break
+			}
+			if m[2] == "array" && container == "" {
+				container = "array"
+			}
+			if env != "" {
+				if env, _ = d.swagType(env, c); env == "" {
+					break
+				}
+			}
+			h.Output, h.Container, h.Envelope = name, container, env
+		case "@failure":
+			m := swagResponseRe.FindStringSubmatch(arg)
+			if m == nil || m[2] != "object" {
+				break
+			}
+			name, container := d.swagType(m[3], c)
+			if name == "" || container != "" {
+				break
+			}
+			s.addError(name, m[1])
+		}
+	}
+	s.Description = strings.Join(desc, "\n")
+	return s
+}
+
+// parseSwagParam parses the argument of @Param (the name, the kind, the
+// type, whether it is required and the quoted description followed by
+// optional attributes ignored by jsondoc).
+func parseSwagParam(arg string) (swagParam, bool) {
+	f := strings.Fields(arg)
+	if len(f) < 4 {
+		return swagParam{}, false
+	}
+	required, err := strconv.ParseBool(f[3])
+	if err != nil {
+		return swagParam{}, false
+	}
+	p := swagParam{Name: f[0], In: f[1], Type: f[2], Required: required}
+	rest := strings.TrimSpace(arg)
+	for i := 0; i < 4; i++ {
+		rest = strings.TrimSpace(rest[len(f[i]):])
+	}
+	if strings.HasPrefix(rest, `"`) {
+		if i := strings.IndexByte(rest[1:], '"'); i != -1 {
+			p.Description = rest[1 : i+1]
+		}
+	}
+	return p, true
+}
+
+// swagType returns the name (as given to the input and output actions)
+// of the type written in a swag annotation (such as "model.Account" or
+// "[]model.Account") and its container (see handlerType).
+func (d *JSONDoc) swagType(s string, c *context) (name, container string) {
+	t, err := parser.ParseExpr(s)
+	if err != nil {
+		return "", ""
+	}
+	return d.handlerType(t, c)
+}
+
+// addError adds the status code of the error type.
+func (s *swagAnnotations) addError(name, code string) {
+	for i := range s.Errors {
+		if s.Errors[i].Type == name {
+			s.Errors[i].Codes = append(s.Errors[i].Codes, code)
+			return
+		}
+	}
+	s.Errors = append(s.Errors, swagError{name, []string{code}})
+}
+
+// empty reports whether no metadata of the endpoint is given.
+func (s *swagAnnotations) empty() bool {
+	return s.Summary == "" && s.Description == "" && s.Method == "" && len(s.Params) == 0 && len(s.Errors) == 0
+}
+
+// render returns the summary, the description (markdown) and the
+// table of the parameters of the endpoint.
+func (s *swagAnnotations) render() string {
+	var b bytes.Buffer
+	for _, p := range []string{s.Summary, s.Description} {
+		if p != "" {
+			b.WriteString(p + "\n\n")
+		}
+	}
+	if len(s.Params) == 0 {
+		return b.String()
+	}
+	b.WriteString("<div>\n<table>\n<caption>Parameters of the request</caption>\n<tr>\n<th scope=\"col\">Name</th>\n<th scope=\"col\">In</th>\n<th scope=\"col\">Type</th>\n<th scope=\"col\">Required</th>\n<th scope=\"col\">Description</th>\n</tr>\n")
+	for _, p := range s.Params {
+		required := "no"
+		if p.Required {
+			required = "yes"
+		}
+		fmt.Fprintf(&b, "<tr>\n<td><code>%s</code></td>\n<td>%s</td>\n<td>%s</td>\n<td>%s</td>\n<td>%s</td>\n</tr>\n", html.EscapeString(p.Name), html.EscapeString(p.In), html.EscapeString(p.Type), required, html.EscapeString(p.Description))
+	}
+	b.WriteString("</table>\n</div>\n\n")
+	return b.String()
+}
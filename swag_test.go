@@ -0,0 +1,120 @@
+package jsondoc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const swagSrc = `package api
+
+import "net/http"
+
+type Item struct {
+	ID int ` + "`json:\"id\"`" + `
+}
+
+type Response struct {
+	Data interface{} ` + "`json:\"data\"`" + `
+}
+
+type APIError struct {
+	Message string ` + "`json:\"message\"`" + `
+}
+
+// ListItems godoc
+//
+//	@Summary		List items
+//	@Description	Lists the items
+//	@Description	of the shop.
+//	@Param			shop	path	string	true	"name of the shop"	minlength(1)
+//	@Param			limit	query	int		false	"maximum number of items"
+//	@Param			filter	body	Item	true	"the filter"
+//	@Param			broken
+//	@Success		200	{object}	Response{data=[]Item}
+//	@Failure		400	{object}	APIError
+//	@Failure		404	{object}	APIError
+//	@Failure		500	{string}	string
+//	@Router			/shops/{shop}/items [get]
+func ListItems(w http.ResponseWriter, r *http.Request) {}
+`
+
+func TestParseSwag(t *testing.T) {
+	d := newTestDoc(t, swagSrc, "# API\n", Options{Swag: true})
+	if err := d.execute(); err != nil {
+		t.Fatal(err)
+	}
+	fn, c, err := d.lookupFunc("ListItems")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var h handlerTypes
+	warnings := d.warnings
+	s := d.parseSwag(fn, c, &h)
+	want := swagAnnotations{
+		Summary:     "List items",
+		Description: "Lists the items\nof the shop.",
+		Method:      "GET",
+		Path:        "/shops/{shop}/items",
+		Params: []swagParam{
+			{Name: "shop", In: "path", Type: "string", Required: true, Description: "name of the shop"},
+			{Name: "limit", In: "query", Type: "int", Description: "maximum number of items"},
+		},
+		Errors: []swagError{{"APIError", []string{"400", "404"}}},
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("got %+v, want %+v", s, want)
+	}
+	if wantTypes := (handlerTypes{Input: "Item", Output: "Item", Container: "array", Envelope: "Response"}); h != wantTypes {
+		t.Errorf("got types %+v, want %+v", h, wantTypes)
+	}
+	if d.warnings != warnings+1 {
+		t.Errorf("got %d warnings, want one of the invalid @Param", d.warnings-warnings)
+	}
+}
+
+func TestParseSwagParam(t *testing.T) {
+	for _, c := range []struct {
+		arg  string
+		want swagParam
+		ok   bool
+	}{
+		{`id path int true "ID of the item"`, swagParam{"id", "path", "int", true, "ID of the item"}, true},
+		{`q query string false "a  query" default(x)`, swagParam{"q", "query", "string", false, "a  query"}, true},
+		{`q query string false`, swagParam{"q", "query", "string", false, ""}, true},
+		{`q query string`, swagParam{}, false},
+		{`q query string maybe "x"`, swagParam{}, false},
+	} {
+		p, ok := parseSwagParam(c.arg)
+		if p != c.want || ok != c.ok {
+			t.Errorf("parseSwagParam(%q) = %+v, %t, want %+v, %t", c.arg, p, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestSwagHandler(t *testing.T) {
+	d := newTestDoc(t, swagSrc, "{{handler \"ListItems\"}}\n", Options{Swag: true})
+	var b strings.Builder
+	if err := d.WriteMarkdown(&b); err != nil {
+		t.Fatal(err)
+	}
+	md := b.String()
+	for _, s := range []string{
+		"## GET `/shops/{shop}/items`",
+		"List items\n\nLists the items\nof the shop.\n\n",
+		"<td><code>shop</code></td>\n<td>path</td>\n<td>string</td>\n<td>yes</td>\n<td>name of the shop</td>",
+		"<td><code>limit</code></td>\n<td>query</td>\n<td>int</td>\n<td>no</td>",
+		"### Input (Item)",
+		"### Output (array of Item",
+		"APIError",
+	} {
+		if !strings.Contains(md, s) {
+			t.Errorf("%q not found in\n%s", s, md)
+		}
+	}
+	// without -swag the annotations are ignored
+	d = newTestDoc(t, swagSrc, "{{handler \"ListItems\"}}\n", Options{})
+	if err := d.execute(); err == nil || !strings.Contains(err.Error(), "no input or output types found") {
+		t.Errorf("got error %v without -swag, want no types found", err)
+	}
+}
@@ -0,0 +1,796 @@
+package jsondoc
+
+import "text/template"
+
+const htmlHeader = `
+<!DOCTYPE html>
+<html lang="{{.Lang}}"{{if .RTL}} dir="rtl"{{end}}>
+<head>
+<meta http-equiv="Content-Type" content="text/html; charset=UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+{{.Head}}
+<style>
+@media print {
+    body {
+        margin: 1em;
+    }
+    nav, a.anchor, form.console, p.snippet-tabs, button.nav-toggle, a.skip-link, div.search {
+        display: none;
+    }
+    table, td, th {
+        border: solid 1px #000000;
+    }
+    h1[id^="endpoint-"], h2[id^="endpoint-"], h3[id^="endpoint-"], h4[id^="endpoint-"] {
+        break-before: page;
+    }
+    h1, h2, h3, h4, h5, h6 {
+        break-after: avoid;
+    }
+    pre, tr, div.admonition, div.keep-together {
+        break-inside: avoid;
+    }
+    div.page-break {
+        break-after: page;
+    }
+    section.cover {
+        display: flex;
+        flex-direction: column;
+        justify-content: center;
+        height: 90vh;
+        break-after: page;
+        text-align: center;
+        font-family: sans-serif;
+    }
+    p.cover-title {
+        font-size: 250%;
+        font-weight: bold;
+    }
+}
+@media screen {
+    section.cover {
+        display: none;
+    }
+}
+@page {
+    margin: 2cm 1.5cm;
+{{if .PrintTitle}}    @top-center {
+        content: {{.PrintTitle}};
+        font-family: sans-serif;
+        font-size: 80%;
+        color: #757575;
+    }
+{{end}}    @bottom-right {
+        content: counter(page) " / " counter(pages);
+        font-family: sans-serif;
+        font-size: 80%;
+        color: #757575;
+    }
+}
+{{if .Cover}}@page :first {
+    @top-center {
+        content: none;
+    }
+    @bottom-right {
+        content: none;
+    }
+}
+{{end}}@media screen {
+    body {
+        margin: 0 1em 0 300px;
+        border-left: solid 1px #e0e0e0;
+        padding-left: 1em;
+        padding-top: 1px;
+        padding-bottom: 1em;
+    }
+    nav {
+        position: absolute;
+        left: 0px;
+        top: 0px;
+        width: 300px;
+        height: 100%;
+        float: left;
+        font-size: 80%;
+        padding-top: 1em;
+    }
+    nav ul {
+        list-style-type:none;
+        padding-left: 1em;
+    }
+    h1, h2, h3 {
+        padding-left: 3px;
+    }
+    h4 {
+        padding-left: 2em;
+    }
+    h2, h3 {
+        margin-top: 2em;
+        padding-bottom: 3px;
+        border-bottom: solid 3px #c5cae9;
+    }
+    p, table {
+        margin-left: 2em;
+    }
+    table, td, th {
+        border: solid 1px #c5cae9;
+    }
+    a {
+        color: #5c6bc0;
+    }
+    a:visited {
+        color: #ab47bc;
+    }
+    :target {
+        color : #1abc9c;
+    }
+}
+button.nav-toggle {
+    display: none;
+}
+@media screen and (max-width: 800px) {
+    body {
+        margin: 0;
+        border-left: none;
+        border-right: none;
+        padding: 0 0.7em 1em 0.7em;
+    }
+    button.nav-toggle {
+        display: block;
+        position: sticky;
+        top: 0;
+        z-index: 5;
+        width: 100%;
+        margin: 0;
+        padding: 0.7em;
+        font-size: 100%;
+        text-align: start;
+        border: none;
+        border-bottom: solid 1px #c5cae9;
+        background-color: #e8eaf6;
+        cursor: pointer;
+    }
+    nav {
+        display: none;
+        position: static;
+        width: auto;
+        height: auto;
+        float: none;
+        font-size: 100%;
+        border-bottom: solid 1px #c5cae9;
+    }
+    nav.open {
+        display: block;
+    }
+    p, table, div.snippets, div.admonition, form.console {
+        margin-left: 0;
+        margin-right: 0;
+    }
+    h4 {
+        padding-left: 0;
+        padding-right: 0;
+    }
+    table {
+        display: block;
+        max-width: 100%;
+        overflow-x: auto;
+    }
+}
+h1, h2, h3, h4 {
+    font-family: sans-serif;
+}
+table {
+    border-collapse: collapse;
+    page-break-inside: avoid;
+}
+td, th {
+    padding: 0.7em;
+}
+th {
+    background-color: #e8eaf6;
+}
+a.anchor {
+    visibility: hidden;
+    margin-left: 0.3em;
+    text-decoration: none;
+    color: #c5cae9;
+}
+h1:hover a.anchor, h2:hover a.anchor, h3:hover a.anchor, h4:hover a.anchor, a.anchor:focus {
+    visibility: visible;
+}
+a.skip-link {
+    position: absolute;
+    left: -10000px;
+    top: 0;
+}
+a.skip-link:focus {
+    left: 1em;
+    top: 1em;
+    z-index: 10;
+    padding: 0.5em 1em;
+    background-color: #ffffff;
+    border: solid 2px #1a237e;
+}
+a:focus-visible, button:focus-visible, input:focus-visible, textarea:focus-visible, select:focus-visible {
+    outline: solid 3px #ff6f00;
+    outline-offset: 2px;
+}
+caption {
+    position: absolute;
+    width: 1px;
+    height: 1px;
+    overflow: hidden;
+    clip: rect(0 0 0 0);
+    white-space: nowrap;
+}
+footer.stamp, footer.stats {
+    margin-top: 3em;
+    font-size: 80%;
+    color: #757575;
+}
+header.branding {
+    display: flex;
+    align-items: center;
+    gap: 1em;
+    padding: 0.5em 0;
+    border-bottom: solid 1px #e0e0e0;
+}
+header.branding img.logo {
+    max-height: 3em;
+}
+footer.branding {
+    margin-top: 1em;
+    padding-top: 0.5em;
+    border-top: solid 1px #e0e0e0;
+    font-size: 80%;
+}
+a.term {
+    text-decoration: underline dotted;
+}
+span.badge {
+    padding: 0.1em 0.5em;
+    border-radius: 0.8em;
+    font-family: monospace;
+    font-size: 90%;
+    color: #1a237e;
+    background-color: #e8eaf6;
+}
+div.admonition {
+    margin: 1em 0 1em 2em;
+    padding: 0.3em 1em;
+    border-left: solid 4px;
+}
+div.admonition p {
+    margin-left: 0;
+}
+p.admonition-title {
+    font-weight: bold;
+}
+div.admonition.note {
+    border-color: #1e88e5;
+    background-color: #e3f2fd;
+}
+div.admonition.warning {
+    border-color: #fb8c00;
+    background-color: #fff3e0;
+}
+div.admonition.danger {
+    border-color: #e53935;
+    background-color: #ffebee;
+}
+div.footnotes {
+    font-size: 90%;
+}
+form.console {
+    margin: 1em 0 1em 2em;
+    padding: 0.5em 0;
+    border: dashed 1px #c5cae9;
+}
+form.console p.console-title {
+    font-family: sans-serif;
+    font-weight: bold;
+}
+div.snippets {
+    margin-left: 2em;
+}
+p.snippet-tabs button {
+    border: solid 1px #c5cae9;
+    background-color: #ffffff;
+    padding: 0.3em 0.7em;
+    cursor: pointer;
+}
+p.snippet-tabs button.active {
+    background-color: #e8eaf6;
+}
+pre.snippet, pre.example {
+    padding: 0.7em;
+    background-color: #f5f5f5;
+    overflow-x: auto;
+}
+dl.fields {
+    margin-left: 2em;
+}
+dl.fields dt {
+    font-weight: bold;
+    margin-top: 0.7em;
+}
+dl.fields dd {
+    margin-left: 1.5em;
+}
+dl.fields dd p {
+    margin: 0.2em 0;
+}
+dl.fields p.field-type {
+    color: #616161;
+}
+div.search input {
+    width: 100%;
+    max-width: 30em;
+}
+ul#jsondoc-search-results {
+    list-style-type: none;
+    padding-left: 0;
+}
+div.tree {
+    margin-left: 2em;
+}
+div.tree ul {
+    list-style-type: none;
+    padding-left: 1.2em;
+    border-left: dotted 1px #bdbdbd;
+}
+div.tree summary {
+    cursor: pointer;
+}
+pre.annotated {
+    padding: 0.7em;
+    margin-left: 2em;
+    background-color: #f5f5f5;
+    overflow-x: auto;
+}
+pre.annotated span.comment {
+    color: #616161;
+}
+details.schema {
+    margin-left: 2em;
+}
+details.schema summary {
+    cursor: pointer;
+}
+@media screen {
+    div.snippets pre.snippet {
+        display: none;
+    }
+    div.snippets pre.snippet.active {
+        display: block;
+    }
+}
+form.console pre.console-response {
+    margin-left: 2em;
+    white-space: pre-wrap;
+}
+{{if .HighContrast}}body {
+    color: #000000;
+    background-color: #ffffff;
+    border-color: #000000;
+}
+a {
+    color: #0000cc;
+    text-decoration: underline;
+}
+a:visited {
+    color: #551a8b;
+}
+:target {
+    color: #000000;
+    background-color: #ffff00;
+}
+h2, h3 {
+    border-bottom-color: #000000;
+}
+table, td, th, p.snippet-tabs button, form.console {
+    border: solid 1px #000000;
+}
+th, span.badge, p.snippet-tabs button.active {
+    color: #ffffff;
+    background-color: #000000;
+}
+a.anchor, footer.stamp, footer.stats {
+    color: #000000;
+}
+pre.snippet, pre.example {
+    background-color: #ffffff;
+    border: solid 1px #000000;
+}
+{{end}}{{if .RTL}}@media screen {
+    body {
+        margin: 0 300px 0 1em;
+        border-left: none;
+        border-right: solid 1px #e0e0e0;
+        padding-left: 0;
+        padding-right: 1em;
+    }
+    nav {
+        left: auto;
+        right: 0px;
+        float: right;
+    }
+    nav ul {
+        padding-left: 0;
+        padding-right: 1em;
+    }
+    h1, h2, h3 {
+        padding-left: 0;
+        padding-right: 3px;
+    }
+    h4 {
+        padding-left: 0;
+        padding-right: 2em;
+    }
+    p, table {
+        margin-left: 0;
+        margin-right: 2em;
+    }
+}
+td, th {
+    text-align: right;
+}
+a.anchor {
+    margin-left: 0;
+    margin-right: 0.3em;
+}
+div.admonition {
+    margin: 1em 2em 1em 0;
+    border-left: none;
+    border-right: solid 4px;
+}
+div.admonition p {
+    margin-right: 0;
+}
+form.console {
+    margin: 1em 2em 1em 0;
+}
+div.snippets {
+    margin-left: 0;
+    margin-right: 2em;
+}
+form.console pre.console-response {
+    margin-left: 0;
+    margin-right: 2em;
+}
+pre, code {
+    direction: ltr;
+    text-align: left;
+    unicode-bidi: embed;
+}
+@media screen and (max-width: 800px) {
+    body {
+        margin: 0;
+        border-right: none;
+        padding: 0 0.7em 1em 0.7em;
+    }
+    nav {
+        float: none;
+    }
+    p, table, div.snippets, div.admonition, form.console {
+        margin-right: 0;
+    }
+}
+{{end}}</style>
+</head>
+<body>
+`
+
+var htmlHeaderTmpl = template.Must(template.New("header").Parse(htmlHeader))
+
+// pageHeader is the data of htmlHeader.
+type pageHeader struct {
+	Title        string // HTML escaped title
+	Head         string // additional HTML elements of the head
+	Lang         string // language of the documentation
+	PrintTitle   string // title in running headers of printed pages (CSS string)
+	Cover        bool   // the cover page is the first printed page
+	RTL          bool   // right-to-left layout
+	HighContrast bool   // high-contrast theme
+}
+
+const htmlFooter = "\n" + anchorsJS + navJS + "</body>\n</html>\n"
+
+// anchorsJS copies the links of the "¶" anchors into the clipboard.
+const anchorsJS = `<script>
+document.querySelectorAll("a.anchor").forEach(function(a) {
+    a.addEventListener("click", function() {
+        if (navigator.clipboard) {
+            navigator.clipboard.writeText(a.href);
+        }
+    });
+});
+</script>
+`
+
+// navJS opens and closes the table of contents with the menu button
+// (shown on narrow screens) and closes it when a link is followed.
+const navJS = `<script>
+(function() {
+    var button = document.querySelector("button.nav-toggle");
+    var nav = document.getElementById("jsondoc-nav");
+    if (!button || !nav) {
+        return;
+    }
+    function setOpen(open) {
+        nav.classList.toggle("open", open);
+        button.setAttribute("aria-expanded", open ? "true" : "false");
+    }
+    button.addEventListener("click", function() {
+        setOpen(!nav.classList.contains("open"));
+    });
+    nav.querySelectorAll("a").forEach(function(a) {
+        a.addEventListener("click", function() {
+            setOpen(false);
+        });
+    });
+})();
+</script>
+`
+
+const table = `
+<p>{{.Format}} {{.Prefix}}object{{.S}} with the following fields:</p>
+<table>
+<caption>Fields of {{.Caption}}</caption>
+<tr>
+<th scope="col">Key name</th>
+<th scope="col">Value type</th>
+<th scope="col">Description</th>
+</tr>
+{{range .Fields}}
+<tr>
+<td>{{.Name}}</td>
+<td>{{.Type}}</td>
+<td>{{.Description}}</td>
+</tr>
+{{end}}
+</table>
+`
+
+const fieldList = `
+<p>{{.Format}} {{.Prefix}}object{{.S}} with the following fields:</p>
+<dl class="fields" aria-label="Fields of {{.Caption}}">
+{{range .Fields}}<dt>{{.Name}}</dt>
+<dd><p class="field-type">{{.Type}}</p>{{if .Description}}
+<p>{{.Description}}</p>{{end}}</dd>
+{{end}}</dl>
+`
+
+const formTable = `
+{{if .Multipart}}<p>Multipart form with the following parts:</p>{{else}}<p>URL encoded form with the following fields:</p>{{end}}
+<table>
+<caption>{{if .Multipart}}Parts{{else}}Fields{{end}} of the form</caption>
+<tr>
+{{if .Multipart}}<th scope="col">Part name</th>
+<th scope="col">Part type</th>
+<th scope="col">Content type</th>{{else}}<th scope="col">Field name</th>
+<th scope="col">Value type</th>{{end}}
+<th scope="col">Description</th>
+</tr>
+{{range .Rows}}
+<tr>
+<td>{{.Name}}</td>
+<td>{{.Type}}</td>
+{{if $.Multipart}}<td>{{.ContentType}}</td>
+{{end}}<td>{{.Description}}</td>
+</tr>
+{{end}}
+</table>
+`
+
+const xmlTable = `
+<p>XML element <code>&lt;{{.Root}}&gt;</code> with the following content:</p>
+<table>
+<caption>Content of XML element &lt;{{.Root}}&gt;</caption>
+<tr>
+<th scope="col">Name</th>
+<th scope="col">Kind</th>
+<th scope="col">Value type</th>
+<th scope="col">Description</th>
+</tr>
+{{range .Rows}}
+<tr>
+<td>{{.Name}}</td>
+<td>{{.Kind}}</td>
+<td>{{.Type}}</td>
+<td>{{.Description}}</td>
+</tr>
+{{end}}
+</table>
+`
+
+const console = `<form class="console" data-method="{{.Method | html}}" data-path="{{.Path | html}}">
+<p class="console-title">Try it</p>
+{{if .Fields}}<table>
+{{range .Fields}}<tr>
+<td>{{printf "%q" .Key | html}}</td>
+<td>{{if eq .Kind "boolean"}}<input type="checkbox" aria-label="{{.Key | html}}" data-key="{{.Key | html}}" data-kind="boolean">{{else if eq .Kind "json"}}<textarea aria-label="{{.Key | html}}" data-key="{{.Key | html}}" data-kind="json" rows="4" cols="40">{{.Value | html}}</textarea>{{else}}<input type="{{if eq .Kind "number"}}number{{else}}text{{end}}" aria-label="{{.Key | html}}" data-key="{{.Key | html}}" data-kind="{{.Kind}}" value="{{.Value | html}}">{{end}}</td>
+</tr>
+{{end}}</table>
+{{else if .Body}}<p><textarea class="console-body" aria-label="Request body" rows="8" cols="60">{{.Body | html}}</textarea></p>
+{{end}}<p><button type="submit">Send {{.Method | html}} request</button></p>
+<pre class="console-response"></pre>
+</form>
+`
+
+const consoleJS = `<script>
+var jsondocBaseURL = {{.}};
+document.querySelectorAll("form.console").forEach(function(f) {
+    f.addEventListener("submit", function(e) {
+        e.preventDefault();
+        var out = f.querySelector(".console-response");
+        var body = {};
+        try {
+            var raw = f.querySelector(".console-body");
+            if (raw) {
+                body = JSON.parse(raw.value);
+            }
+            f.querySelectorAll("[data-key]").forEach(function(el) {
+                var v = el.value;
+                switch (el.getAttribute("data-kind")) {
+                case "number":
+                    if (v === "") {
+                        return;
+                    }
+                    v = Number(v);
+                    break;
+                case "boolean":
+                    v = el.checked;
+                    break;
+                case "json":
+                    if (v === "") {
+                        return;
+                    }
+                    v = JSON.parse(v);
+                    break;
+                }
+                body[el.getAttribute("data-key")] = v;
+            });
+        } catch (err) {
+            out.textContent = "Invalid input: " + err;
+            return;
+        }
+        var method = f.getAttribute("data-method");
+        var url = jsondocBaseURL + f.getAttribute("data-path");
+        var opts = {method: method, headers: {}};
+        if (method === "GET" || method === "HEAD" || method === "DELETE") {
+            var q = new URLSearchParams(body).toString();
+            if (q !== "") {
+                url += "?" + q;
+            }
+        } else {
+            opts.headers["Content-Type"] = "application/json";
+            opts.body = JSON.stringify(body);
+        }
+        out.textContent = "Sending request...";
+        fetch(url, opts).then(function(r) {
+            return r.text().then(function(t) {
+                try {
+                    t = JSON.stringify(JSON.parse(t), null, 2);
+                } catch (err) {
+                }
+                out.textContent = r.status + " " + r.statusText + "\n\n" + t;
+            });
+        }).catch(function(err) {
+            out.textContent = "Request failed: " + err;
+        });
+    });
+});
+</script>
+`
+
+// searchJS matches the query (ignoring case and punctuation, so that
+// "requestid" finds request_id) with the names in the search index: a
+// substring match ranks above the characters matched in order.
+const searchJS = `<script>
+(function() {
+    var input = document.getElementById("jsondoc-search");
+    var results = document.getElementById("jsondoc-search-results");
+    var index = JSON.parse(document.getElementById("jsondoc-search-index").textContent);
+    function normalize(s) {
+        return s.toLowerCase().replace(/[^a-z0-9]/g, "");
+    }
+    function score(q, s) {
+        var i = s.indexOf(q);
+        if (i >= 0) {
+            return i + s.length - q.length;
+        }
+        var gaps = 0, j = 0;
+        for (i = 0; i < s.length && j < q.length; i++) {
+            if (s[i] === q[j]) {
+                j++;
+            } else if (j > 0) {
+                gaps++;
+            }
+        }
+        return j === q.length ? 1000 + gaps : -1;
+    }
+    index.entries.forEach(function(e) {
+        e.key = normalize(e.name);
+    });
+    function link(id, text) {
+        var a = document.createElement("a");
+        a.href = "#" + id;
+        a.textContent = text;
+        return a;
+    }
+    input.addEventListener("input", function() {
+        results.textContent = "";
+        var q = normalize(input.value);
+        if (!q) {
+            return;
+        }
+        var found = [];
+        index.entries.forEach(function(e) {
+            var s = score(q, e.key);
+            if (s >= 0) {
+                found.push({entry: e, score: s});
+            }
+        });
+        found.sort(function(a, b) {
+            return a.score - b.score || a.entry.name.localeCompare(b.entry.name);
+        });
+        found.slice(0, 20).forEach(function(f) {
+            var e = f.entry;
+            var li = document.createElement("li");
+            li.appendChild(link(e.id, e.name));
+            li.appendChild(document.createTextNode(e.kind === "field" ? " field of type " + e.type : " type"));
+            if (e.endpoints.length > 0) {
+                li.appendChild(document.createTextNode(" in "));
+                e.endpoints.forEach(function(i, n) {
+                    if (n > 0) {
+                        li.appendChild(document.createTextNode(", "));
+                    }
+                    li.appendChild(link(index.endpoints[i].id, index.endpoints[i].title));
+                });
+            }
+            results.appendChild(li);
+        });
+        if (found.length === 0) {
+            var li = document.createElement("li");
+            li.textContent = "No matching fields or types.";
+            results.appendChild(li);
+        }
+    });
+})();
+</script>
+`
+
+const snippetsJS = `<script>
+function jsondocSelectLanguage(lang) {
+    document.querySelectorAll("div.snippets").forEach(function(div) {
+        var sel = lang;
+        if (!div.querySelector("button[data-lang='" + lang + "']")) {
+            sel = div.querySelector("button[data-lang]").getAttribute("data-lang");
+        }
+        div.querySelectorAll("[data-lang]").forEach(function(el) {
+            el.classList.toggle("active", el.getAttribute("data-lang") === sel);
+        });
+    });
+    try {
+        localStorage.setItem("jsondoc-language", lang);
+    } catch (err) {
+    }
+}
+document.querySelectorAll("p.snippet-tabs button").forEach(function(b) {
+    b.addEventListener("click", function() {
+        jsondocSelectLanguage(b.getAttribute("data-lang"));
+    });
+});
+(function() {
+    var lang = null;
+    try {
+        lang = localStorage.getItem("jsondoc-language");
+    } catch (err) {
+    }
+    var first = document.querySelector("p.snippet-tabs button");
+    if (first) {
+        jsondocSelectLanguage(lang || first.getAttribute("data-lang"));
+    }
+})();
+</script>
+`
@@ -0,0 +1,78 @@
+package jsondoc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// timingPhases are the phases of the generation in the order of
+// Timings.
+var timingPhases = []string{"parse", "typecheck", "resolve", "render"}
+
+// timer measures the time spent in the (nested) phases of the
+// generation. The time of a phase does not include the time of the
+// phases started within it (such as parsing a package imported while
+// executing the template).
+type timer struct {
+	phases map[string]time.Duration
+	stack  []string
+	last   time.Time // start of the current interval of the innermost phase
+}
+
+// start starts the phase and returns the function ending it.
+func (t *timer) start(phase string) func() {
+	now := time.Now()
+	if t.phases == nil {
+		t.phases = make(map[string]time.Duration)
+	}
+	if n := len(t.stack); n > 0 {
+		t.phases[t.stack[n-1]] += now.Sub(t.last)
+	}
+	t.stack = append(t.stack, phase)
+	t.last = now
+	return func() {
+		now := time.Now()
+		t.phases[phase] += now.Sub(t.last)
+		t.stack = t.stack[:len(t.stack)-1]
+		t.last = now
+	}
+}
+
+// PhaseTime is the time spent in a phase of the generation.
+type PhaseTime struct {
+	Phase    string // "parse", "typecheck", "resolve" or "render"
+	Duration time.Duration
+}
+
+// Timings are the times spent in the phases of the generation: parsing
+// the Go packages, type checking them, resolving the documented types
+// (executing the template) and rendering the HTML.
+type Timings []PhaseTime
+
+// Timings returns the times spent in the phases of the generation so
+// far (all of them after WriteTo).
+func (d *JSONDoc) Timings() Timings {
+	done := d.calls.done.Load() // the times of executing the template are known
+	d.calls.mu.Lock()
+	defer d.calls.mu.Unlock()
+	var ts Timings
+	for _, p := range timingPhases {
+		t := d.calls.phases[p]
+		if done {
+			t += d.timer.phases[p]
+		}
+		ts = append(ts, PhaseTime{p, t})
+	}
+	return ts
+}
+
+func (ts Timings) String() string {
+	var a []string
+	var total time.Duration
+	for _, t := range ts {
+		a = append(a, fmt.Sprintf("%s %v", t.Phase, t.Duration.Round(time.Microsecond)))
+		total += t.Duration
+	}
+	return fmt.Sprintf("%s (total %v)", strings.Join(a, ", "), total.Round(time.Microsecond))
+}
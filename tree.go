@@ -0,0 +1,164 @@
+package jsondoc
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"html"
+	"strings"
+)
+
+// tree renders the whole hierarchy of the payload of the type given by
+// name (as in the input and output actions) as a tree of the fields
+// with collapsible objects and arrays (a complement to the tables of
+// the types which are linked from each other).
+func (d *JSONDoc) tree(name string) (string, error) {
+	t, c, err := d.lookupTypeName(name)
+	if err != nil {
+		return "", fmt.Errorf("tree %s: %v", name, err)
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<div class=\"tree\">\n<p>Structure of %s:</p>\n", html.EscapeString(typeIdent(name)))
+	label, children, cc := d.treeType(t.Type, c)
+	if children == nil {
+		fmt.Fprintf(&b, "<p>%s</p>\n</div>\n", label)
+		return b.String(), nil
+	}
+	fmt.Fprintf(&b, "<p>%s %s</p>\n", d.format(), label)
+	if err := d.writeTree(&b, children, cc, map[*ast.StructType]bool{}, 0); err != nil {
+		return "", fmt.Errorf("tree %s: %v", name, err)
+	}
+	b.WriteString("</div>\n")
+	return b.String(), nil
+}
+
+// treeType returns the description of the values of the type (HTML)
+// and the struct type of the objects (in the containers) with the
+// fields to be shown as the children of its node (if any).
+func (d *JSONDoc) treeType(t ast.Expr, c *context) (string, *ast.StructType, *context) {
+	switch t := t.(type) {
+	case *ast.StarExpr:
+		return d.treeType(t.X, c)
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && (ident.Name == "byte" || ident.Name == "uint8") {
+			return "string (base64 encoded)", nil, nil
+		}
+		label, st, c := d.treeType(t.Elt, c)
+		return "array of " + label, st, c
+	case *ast.MapType:
+		label, st, c := d.treeType(t.Value, c)
+		return "object of " + label, st, c
+	case *ast.StructType:
+		return "object", t, c
+	case *ast.Ident, *ast.SelectorExpr:
+		text := html.EscapeString(typeString(t))
+		if d.stdType(t, c) {
+			return text, nil, nil
+		}
+		ts, c, err := d.lookupType(t, c)
+		if err != nil || ts == nil {
+			return text, nil, nil
+		}
+		if st, ok := ts.Type.(*ast.StructType); ok {
+			return text, st, c
+		}
+		label, st, c := d.treeType(ts.Type, c)
+		if st != nil {
+			return text + " (" + label + ")", st, c
+		}
+		return text, nil, nil
+	}
+	return html.EscapeString(typeString(t)), nil, nil
+}
+
+// typeString returns the name of the type as in the Go source.
+func typeString(t ast.Expr) string {
+	switch t := t.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return fmt.Sprintf("%s.%s", t.X, t.Sel.Name)
+	case *ast.InterfaceType:
+		return "any value"
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		var args []string
+		for _, a := range typeArgs(t) {
+			args = append(args, typeString(a))
+		}
+		return typeString(genericType(t)) + "[" + strings.Join(args, ", ") + "]"
+	}
+	return "unsupported type"
+}
+
+// writeTree writes the fields of the struct as the list of the nodes
+// of the tree (the top levels expanded). The structs on the path are
+// given with parents (to stop at recursive types).
+func (d *JSONDoc) writeTree(b *bytes.Buffer, st *ast.StructType, c *context, parents map[*ast.StructType]bool, depth int) error {
+	parents[st] = true
+	defer delete(parents, st)
+	b.WriteString("<ul>\n")
+	err := d.forEachField(st, c, func(name string, f *ast.Field, c *context) error {
+		label, children, cc := d.treeType(f.Type, c)
+		node := fmt.Sprintf("<code>%s</code> %s", html.EscapeString(name), label)
+		if desc := parseFieldComment(f).Description; desc != "" {
+			node += " - " + d.linkTerms(html.EscapeString(desc))
+		}
+		switch {
+		case children == nil:
+			fmt.Fprintf(b, "<li>%s</li>\n", node)
+		case parents[children]:
+			fmt.Fprintf(b, "<li>%s (recursive)</li>\n", node)
+		default:
+			open := ""
+			if depth == 0 {
+				open = " open"
+			}
+			fmt.Fprintf(b, "<li><details%s><summary>%s</summary>\n", open, node)
+			if err := d.writeTree(b, children, cc, parents, depth+1); err != nil {
+				return err
+			}
+			b.WriteString("</details></li>\n")
+		}
+		return nil
+	})
+	b.WriteString("</ul>\n")
+	return err
+}
+
+// forEachField calls f for the fields of the struct present in the
+// encoded objects (with the fields of the embedded structs) with their
+// key names (as in the tables of the types).
+func (d *JSONDoc) forEachField(st *ast.StructType, c *context, f func(name string, field *ast.Field, c *context) error) error {
+	for _, field := range st.Fields.List {
+		if typ := d.inlined(field); typ != nil {
+			if s, ok := typ.(*ast.StarExpr); ok {
+				typ = s.X
+			}
+			t, c, err := d.lookupType(typ, c)
+			if err != nil {
+				return err
+			}
+			if t == nil {
+				continue
+			}
+			if t, ok := t.Type.(*ast.StructType); ok {
+				if err := d.forEachField(t, c, f); err != nil {
+					return err
+				}
+			}
+		}
+		for _, ident := range field.Names {
+			name, err := d.fieldName(ident.Name, field.Tag)
+			if err == NotExported {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := f(name, field, c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
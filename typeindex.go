@@ -0,0 +1,46 @@
+package jsondoc
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+)
+
+// namedType is a named type rendered in the documentation.
+type namedType struct {
+	Name string
+	Pkg  string // package name
+	ID   string // id of the section the type is rendered in
+}
+
+// typeIndex returns the alphabetical index of the named types rendered
+// so far with links to them and to the endpoints referencing them.
+func (d *JSONDoc) typeIndex() string {
+	types := append([]namedType(nil), d.namedTypes...)
+	sort.SliceStable(types, func(i, j int) bool {
+		a, b := strings.ToLower(types[i].Name), strings.ToLower(types[j].Name)
+		if a != b {
+			return a < b
+		}
+		return types[i].Pkg < types[j].Pkg
+	})
+	var b bytes.Buffer
+	b.WriteString(heading(d.headingLevel()) + " Type index {#type-index}\n\n<div>\n<table>\n<caption>Documented types</caption>\n<tr>\n<th scope=\"col\">Type</th>\n<th scope=\"col\">Package</th>\n<th scope=\"col\">Referenced by</th>\n</tr>\n")
+	for _, t := range types {
+		var refs []string
+		for _, e := range d.endpoints {
+			if d.typeRefs[t.ID][e.ID] {
+				refs = append(refs, fmt.Sprintf(`<a href="#%s">%s</a>`, e.ID, html.EscapeString(e.Title())))
+			}
+		}
+		if len(refs) == 0 {
+			refs = []string{"(no endpoints)"}
+		}
+		fmt.Fprintf(&b, "<tr>\n<td><a href=\"#%s\">%s</a></td>\n<td>%s</td>\n<td>%s</td>\n</tr>\n",
+			html.EscapeString(t.ID), html.EscapeString(t.Name), html.EscapeString(t.Pkg), strings.Join(refs, ", "))
+	}
+	b.WriteString("</table>\n</div>\n")
+	return b.String()
+}
@@ -0,0 +1,214 @@
+package jsondoc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+)
+
+// typeDecl is the declaration of a named type found by the type
+// checker.
+type typeDecl struct {
+	t *ast.TypeSpec
+	c *context
+}
+
+// typesImporter imports packages for the type checker: the packages of
+// the standard library from their export data and the other packages
+// by type checking their (parsed) sources.
+type typesImporter struct {
+	d *JSONDoc
+}
+
+func (imp *typesImporter) Import(path string) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	if d := imp.d; d.loadedPackage(path) == nil {
+		p, err := d.importPackage(path, 0)
+		if err != nil {
+			return nil, err
+		}
+		if p.Goroot {
+			return d.stdImporter.Import(path)
+		}
+	}
+	return imp.d.checkPackage(path)
+}
+
+// checkedPackage returns the type checked package with the given path.
+// Type errors (such as of packages which cannot be imported) are
+// ignored as the declarations which could be checked are enough to
+// resolve the identifiers of the documented types.
+func (d *JSONDoc) checkedPackage(path string) (*types.Package, error) {
+	d.calls.check.Lock()
+	defer d.calls.check.Unlock()
+	return d.checkPackage(path)
+}
+
+// checkPackage is checkedPackage called with the check lock held (by
+// the importer of the type checker).
+func (d *JSONDoc) checkPackage(path string) (*types.Package, error) {
+	if p, ok := d.typesPackages[path]; ok {
+		if p == nil {
+			return nil, fmt.Errorf("import cycle through package %s", path)
+		}
+		return p, nil
+	}
+	pkg, err := d.parsedPackage(path)
+	if err != nil {
+		return nil, err
+	}
+	d.typesPackages[path] = nil
+	defer d.timer.start("typecheck")()
+	files := sortedFiles(pkg)
+	conf := types.Config{Importer: &typesImporter{d}, Error: func(error) {}, FakeImportC: true}
+	p, _ := conf.Check(path, d.fset, files, d.typesInfo)
+	d.typesPackages[path] = p
+	for _, f := range files {
+		c := &context{path, pkg, f}
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				return false
+			case *ast.TypeSpec:
+				if o := d.typesInfo.Defs[n.Name]; o != nil {
+					d.typeDecls[o] = typeDecl{n, c}
+				}
+			}
+			return true
+		})
+	}
+	return p, nil
+}
+
+// typeDef returns the object defined by the identifier (or nil) as
+// recorded by the type checker.
+func (d *JSONDoc) typeDef(ident *ast.Ident) types.Object {
+	d.calls.check.Lock()
+	defer d.calls.check.Unlock()
+	return d.typesInfo.Defs[ident]
+}
+
+// typeUse returns the object the identifier refers to (or nil) as
+// recorded by the type checker.
+func (d *JSONDoc) typeUse(ident *ast.Ident) types.Object {
+	d.calls.check.Lock()
+	defer d.calls.check.Unlock()
+	return d.typesInfo.Uses[ident]
+}
+
+// resolveType returns the declaration of the named type referred to
+// by the identifier (or the selector expression) in the given context
+// as resolved by the type checker (so that dot imports, renamed
+// imports and aliases are followed). It returns false if the type
+// checker could not resolve it and nil declaration (and true) for
+// predeclared types.
+func (d *JSONDoc) resolveType(t ast.Expr, c *context) (*ast.TypeSpec, *context, bool) {
+	var ident *ast.Ident
+	switch t := genericType(t).(type) {
+	case *ast.Ident:
+		ident = t
+	case *ast.SelectorExpr:
+		ident = t.Sel
+	default:
+		return nil, nil, false
+	}
+	if c == nil || c.Package == nil {
+		return nil, nil, false
+	}
+	if _, err := d.checkedPackage(c.Path); err != nil {
+		return nil, nil, false
+	}
+	o, ok := d.typeUse(ident).(*types.TypeName)
+	if !ok {
+		return nil, nil, false
+	}
+	if _, ok := o.Type().(*types.TypeParam); ok {
+		// type parameters are documented by their names (like
+		// predeclared types)
+		return nil, nil, true
+	}
+	return d.typeNameDecl(o)
+}
+
+// genericType returns the generic type of the instantiation of a
+// generic type (such as Box of Box[int]) or t if it is not an
+// instantiation.
+func genericType(t ast.Expr) ast.Expr {
+	switch x := t.(type) {
+	case *ast.IndexExpr:
+		return x.X
+	case *ast.IndexListExpr:
+		return x.X
+	}
+	return t
+}
+
+// typeArgs returns the type arguments of the instantiation of a generic
+// type (or nil if t is not an instantiation).
+func typeArgs(t ast.Expr) []ast.Expr {
+	switch x := t.(type) {
+	case *ast.IndexExpr:
+		return []ast.Expr{x.Index}
+	case *ast.IndexListExpr:
+		return x.Indices
+	}
+	return nil
+}
+
+// typeNameDecl returns the declaration of the named type (following
+// aliases of named types).
+func (d *JSONDoc) typeNameDecl(o *types.TypeName) (*ast.TypeSpec, *context, bool) {
+	if o.Pkg() == nil {
+		return nil, nil, true
+	}
+	if o.IsAlias() {
+		if n, ok := types.Unalias(o.Type()).(*types.Named); ok {
+			o = n.Origin().Obj()
+		}
+	}
+	d.calls.check.Lock()
+	decl, ok := d.typeDecls[o]
+	d.calls.check.Unlock()
+	if !ok {
+		return nil, nil, false
+	}
+	d.reference(decl.t.Name.Obj)
+	return decl.t, decl.c, true
+}
+
+// unalias returns the declaration of the named type the alias
+// declaration refers to (or the declaration itself if it does not
+// declare an alias of a named type).
+func (d *JSONDoc) unalias(t *ast.TypeSpec, c *context) (*ast.TypeSpec, *context) {
+	if !t.Assign.IsValid() {
+		return t, c
+	}
+	if _, err := d.checkedPackage(c.Path); err != nil {
+		return t, c
+	}
+	o, ok := d.typeDef(t.Name).(*types.TypeName)
+	if !ok {
+		return t, c
+	}
+	if ts, c, ok := d.typeNameDecl(o); ok && ts != nil {
+		return ts, c
+	}
+	return t, c
+}
+
+// sortedFiles returns the files of the package sorted by their names.
+func sortedFiles(pkg *ast.Package) []*ast.File {
+	var names []string
+	for name := range pkg.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	files := make([]*ast.File, len(names))
+	for i, name := range names {
+		files[i] = pkg.Files[name]
+	}
+	return files
+}
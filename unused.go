@@ -0,0 +1,68 @@
+package jsondoc
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+)
+
+// Unused returns what the documentation does not reference: the names
+// of the imports (as given to the import action) whose packages are
+// never referenced and the exported types of the other imported
+// packages which no documented type reaches (qualified with the name
+// of the import, such as "another.Address").
+func (d *JSONDoc) Unused() (imports, typeNames []string, err error) {
+	c, err := d.begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer d.end(c)
+	return c.unused()
+}
+
+func (d *JSONDoc) unused() (imports, typeNames []string, err error) {
+	names := make([]string, 0, len(d.imports))
+	for name := range d.imports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pkg := d.loadedPackage(d.imports[name])
+		if pkg == nil {
+			continue
+		}
+		var unreferenced []string
+		used := false
+		for _, file := range sortedFiles(pkg) {
+			for _, decl := range file.Decls {
+				g, ok := decl.(*ast.GenDecl)
+				if !ok || g.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range g.Specs {
+					t := spec.(*ast.TypeSpec)
+					if d.isReferenced(t.Name.Obj) {
+						used = true
+					} else if t.Name.IsExported() {
+						unreferenced = append(unreferenced, qualifiedName(name, t.Name.Name))
+					}
+				}
+			}
+		}
+		if used {
+			typeNames = append(typeNames, unreferenced...)
+		} else {
+			imports = append(imports, name)
+		}
+	}
+	return imports, typeNames, nil
+}
+
+// qualifiedName returns the name of the type of the package imported
+// under the given name as used in the template.
+func qualifiedName(pkgName, name string) string {
+	if pkgName == "." {
+		return name
+	}
+	return pkgName + "." + name
+}
@@ -0,0 +1,54 @@
+package jsondoc
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"text/template"
+)
+
+// unsupportedRe matches the descriptions of unsupported constructs
+// rendered in place of the types.
+var unsupportedRe = regexp.MustCompile(`\(error: [^)]*\)`)
+
+// Validate executes the template (without rendering any output) and
+// returns all the problems found instead of stopping at the first one:
+// errors of the actions (such as missing imports and unknown type
+// names) and unsupported constructs of the documented types. An action
+// which fails renders nothing and the execution continues (only
+// errors of the template itself, such as a wrong number of arguments,
+// stop it).
+func (d *JSONDoc) Validate() []error {
+	var errs []error
+	wrapped := make(template.FuncMap)
+	for name, f := range d.funcs {
+		v := reflect.ValueOf(f)
+		t := v.Type()
+		if t.NumOut() != 2 {
+			continue
+		}
+		wrapped[name] = reflect.MakeFunc(t, func(args []reflect.Value) []reflect.Value {
+			var out []reflect.Value
+			if t.IsVariadic() {
+				out = v.CallSlice(args)
+			} else {
+				out = v.Call(args)
+			}
+			if err, _ := out[1].Interface().(error); err != nil {
+				errs = append(errs, err)
+				return []reflect.Value{reflect.Zero(t.Out(0)), reflect.Zero(t.Out(1))}
+			}
+			return out
+		}).Interface()
+	}
+	if !d.executeOnce(func() { d.t.Funcs(wrapped) }) {
+		return []error{errors.New("validate: the template was already executed")}
+	}
+	if err := d.calls.err; err != nil {
+		errs = append(errs, err)
+	}
+	for _, m := range unsupportedRe.FindAll(d.md.Bytes(), -1) {
+		errs = append(errs, errors.New(string(m[len("(error: "):len(m)-1])))
+	}
+	return errs
+}
@@ -0,0 +1,441 @@
+package jsondoc
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WriteValidators writes Go source of Validate methods for the input
+// types (and struct types they refer to) of the documented endpoints
+// which are declared in the package imported in the template with the
+// given name. The rules are taken from validate struct tags. It also
+// writes a middleware validating requests and a map from endpoints to
+// their input types.
+func (d *JSONDoc) WriteValidators(w io.Writer, pkgName string) error {
+	c, err := d.begin()
+	if err != nil {
+		return err
+	}
+	defer d.end(c)
+	return c.writeValidators(w, pkgName)
+}
+
+func (d *JSONDoc) writeValidators(w io.Writer, pkgName string) error {
+	path, err := d.importedPath(pkgName)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return fmt.Errorf("name %s is not imported in the template", pkgName)
+	}
+	g := &validatorGen{d: d, path: path, imports: map[string]bool{"encoding/json": true, "io": true, "bytes": true, "net/http": true},
+		done: make(map[*ast.TypeSpec]bool)}
+	var inputs []string
+	for _, e := range d.endpoints {
+		if !e.jsonInput() {
+			continue
+		}
+		ts, c, err := d.lookupTypeName(e.Input)
+		if err != nil {
+			return err
+		}
+		if c.Path != path {
+			continue
+		}
+		if _, ok := ts.Type.(*ast.StructType); !ok {
+			d.warnf("warning: %s: input type %s is not a struct\n", e.Title(), e.Input)
+			continue
+		}
+		g.enqueue(ts, c)
+		inputs = append(inputs, fmt.Sprintf("%q: func() validator { return new(%s) },\n", e.Title(), ts.Name.Name))
+	}
+	for i := 0; i < len(g.queue); i++ {
+		if err := g.genType(g.queue[i].t, g.queue[i].c); err != nil {
+			return err
+		}
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by jsondoc; DO NOT EDIT.\n\npackage %s\n\nimport (\n", d.packageName(path))
+	var imports []string
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	for _, imp := range imports {
+		fmt.Fprintf(&b, "%q\n", imp)
+	}
+	b.WriteString(")\n\n")
+	b.WriteString(validatorsHeader)
+	b.WriteString("\n// endpointInputs maps documented endpoints to constructors of their\n// input types.\nvar endpointInputs = map[string]func() validator{\n")
+	for _, s := range inputs {
+		b.WriteString(s)
+	}
+	b.WriteString("}\n")
+	b.Write(g.b.Bytes())
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting generated code: %v", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+const validatorsHeader = `type validator interface {
+	Validate() error
+}
+
+// validateJSON returns a handler which decodes the JSON request body
+// into the value returned by newInput and validates it before passing
+// the request (with the body intact) to next.
+func validateJSON(newInput func() validator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		v := newInput()
+		if err := json.Unmarshal(body, v); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := v.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+`
+
+type validatorGen struct {
+	d       *JSONDoc
+	path    string // path of the package validators are generated for
+	b       bytes.Buffer
+	imports map[string]bool
+	queue   []queueElem
+	done    map[*ast.TypeSpec]bool
+}
+
+// enqueue schedules generation of the Validate method for the given
+// struct type.
+func (g *validatorGen) enqueue(t *ast.TypeSpec, c *context) {
+	if !g.done[t] {
+		g.done[t] = true
+		g.queue = append(g.queue, queueElem{t: t, c: c})
+	}
+}
+
+// localStruct returns the declaration of the named struct type t if it
+// is declared in the package validators are generated for.
+func (g *validatorGen) localStruct(t ast.Expr, c *context) (*ast.TypeSpec, *context) {
+	switch t.(type) {
+	case *ast.Ident, *ast.SelectorExpr:
+	default:
+		return nil, nil
+	}
+	ts, c, err := g.d.lookupType(t, c)
+	if err != nil || ts == nil || c.Path != g.path {
+		return nil, nil
+	}
+	if _, ok := ts.Type.(*ast.StructType); !ok {
+		return nil, nil
+	}
+	return ts, c
+}
+
+func (g *validatorGen) genType(t *ast.TypeSpec, c *context) error {
+	fmt.Fprintf(&g.b, "\n// Validate checks the constraints of the validate struct tags of %s.\nfunc (v *%s) Validate() error {\n", t.Name.Name, t.Name.Name)
+	for _, f := range t.Type.(*ast.StructType).Fields.List {
+		if len(f.Names) == 0 {
+			typ := f.Type
+			ptr := false
+			if s, ok := typ.(*ast.StarExpr); ok {
+				typ, ptr = s.X, true
+			}
+			ts, tc := g.localStruct(typ, c)
+			if ts == nil {
+				continue
+			}
+			g.enqueue(ts, tc)
+			if ptr {
+				fmt.Fprintf(&g.b, "if v.%s != nil {\n", ts.Name.Name)
+			}
+			fmt.Fprintf(&g.b, "if err := v.%s.Validate(); err != nil {\nreturn err\n}\n", ts.Name.Name)
+			if ptr {
+				g.b.WriteString("}\n")
+			}
+			continue
+		}
+		cs, err := parseConstraints(f.Tag)
+		if err != nil {
+			return fmt.Errorf("type %s: %v", t.Name.Name, err)
+		}
+		for _, ident := range f.Names {
+			key, _, err := g.d.jsonKey(ident.Name, f.Tag)
+			if err != nil {
+				continue
+			}
+			if err := g.genField("v."+ident.Name, strconv.Quote(key), f.Type, cs, c); err != nil {
+				return fmt.Errorf("type %s field %s: %v", t.Name.Name, ident.Name, err)
+			}
+		}
+	}
+	g.b.WriteString("return nil\n}\n")
+	return nil
+}
+
+func (g *validatorGen) genField(x, key string, t ast.Expr, cs []constraint, c *context) error {
+	kind := g.d.kindOf(t, c)
+	omitempty := false
+	for _, r := range cs {
+		if r.Name == "omitempty" {
+			omitempty = true
+		}
+	}
+	if omitempty && len(cs) > 1 {
+		fmt.Fprintf(&g.b, "if %s {\n", zeroCheck(x, kind, false))
+	}
+	for _, r := range cs {
+		var cond, msg string
+		switch r.Name {
+		case "omitempty":
+			continue
+		case "required":
+			if kind == "bool" || kind == "struct" {
+				return fmt.Errorf("rule required is not supported for %s values", kind)
+			}
+			cond, msg = zeroCheck(x, kind, true), "is required"
+		case "min", "max", "len":
+			op := map[string]string{"min": "<", "max": ">", "len": "!="}[r.Name]
+			what := map[string]string{"min": "at least ", "max": "at most ", "len": "exactly "}[r.Name]
+			param, err := g.d.limitParam(r, kind, t, c)
+			if err != nil {
+				return err
+			}
+			switch kind {
+			case "string":
+				g.imports["unicode/utf8"] = true
+				cond = fmt.Sprintf("utf8.RuneCountInString(string(%s)) %s %s", x, op, param)
+				msg = fmt.Sprintf("must be %s%s characters long", what, param)
+			case "slice", "map":
+				cond = fmt.Sprintf("len(%s) %s %s", x, op, param)
+				msg = fmt.Sprintf("must have %s%s elements", what, param)
+			case "int", "float":
+				cond = fmt.Sprintf("%s %s %s", x, op, param)
+				msg = fmt.Sprintf("must be %s%s", what, param)
+			}
+		case "oneof":
+			values := strings.Fields(r.Param)
+			var cases []string
+			for _, v := range values {
+				switch kind {
+				case "string":
+					cases = append(cases, strconv.Quote(v))
+				case "int":
+					if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+						return fmt.Errorf("rule oneof: %v", err)
+					}
+					cases = append(cases, v)
+				default:
+					return fmt.Errorf("rule oneof is not supported for %s values", kind)
+				}
+			}
+			fmt.Fprintf(&g.b, "switch %s {\ncase %s:\ndefault:\nreturn errors.New(%q)\n}\n", x, strings.Join(cases, ", "),
+				key+": must be one of "+strings.Join(values, ", "))
+			g.imports["errors"] = true
+			continue
+		case "email":
+			if kind != "string" {
+				return fmt.Errorf("rule email is not supported for %s values", kind)
+			}
+			g.imports["net/mail"] = true
+			fmt.Fprintf(&g.b, "if _, err := mail.ParseAddress(string(%s)); err != nil {\nreturn errors.New(%q)\n}\n", x,
+				key+": must be a valid e-mail address")
+			g.imports["errors"] = true
+			continue
+		}
+		fmt.Fprintf(&g.b, "if %s {\nreturn errors.New(%q)\n}\n", cond, key+": "+msg)
+		g.imports["errors"] = true
+	}
+	if omitempty && len(cs) > 1 {
+		g.b.WriteString("}\n")
+	}
+	return g.genNested(x, key, t, c)
+}
+
+// genNested generates calls of Validate methods for the values of
+// named struct types (directly or by pointer, also as elements of
+// slices and arrays and as values of maps).
+func (g *validatorGen) genNested(x, key string, t ast.Expr, c *context) error {
+	switch tt := t.(type) {
+	case *ast.ArrayType:
+		if ptr, ok := g.validated(tt.Elt, c); ok {
+			fmt.Fprintf(&g.b, "for i := range %s {\n", x)
+			g.genValidate(x+"[i]", ptr, fmt.Sprintf("fmt.Errorf(%q, i, err)", key+"[%d]: %v"))
+			g.b.WriteString("}\n")
+		}
+	case *ast.MapType:
+		if ptr, ok := g.validated(tt.Value, c); ok {
+			fmt.Fprintf(&g.b, "for k, e := range %s {\n", x)
+			g.genValidate("e", ptr, fmt.Sprintf("fmt.Errorf(%q, k, err)", key+"[%v]: %v"))
+			g.b.WriteString("}\n")
+		}
+	default:
+		if ptr, ok := g.validated(t, c); ok {
+			g.genValidate(x, ptr, fmt.Sprintf("fmt.Errorf(%q, err)", key+": %v"))
+		}
+	}
+	return nil
+}
+
+// validated reports whether the values of type t (or the values t
+// points to, then ptr is true) are of a struct type with the generated
+// Validate method.
+func (g *validatorGen) validated(t ast.Expr, c *context) (ptr, ok bool) {
+	if s, isPtr := t.(*ast.StarExpr); isPtr {
+		t, ptr = s.X, true
+	}
+	ts, tc := g.localStruct(t, c)
+	if ts == nil {
+		return false, false
+	}
+	g.enqueue(ts, tc)
+	g.imports["fmt"] = true
+	return ptr, true
+}
+
+// genValidate generates the call of the Validate method of x (skipped
+// if x is a nil pointer) returning errExpr on failure.
+func (g *validatorGen) genValidate(x string, ptr bool, errExpr string) {
+	if ptr {
+		fmt.Fprintf(&g.b, "if %s != nil {\n", x)
+	}
+	fmt.Fprintf(&g.b, "if err := %s.Validate(); err != nil {\nreturn %s\n}\n", x, errExpr)
+	if ptr {
+		g.b.WriteString("}\n")
+	}
+}
+
+// intBits are the sizes of the predeclared integer types (0 for the
+// size of int).
+var intBits = map[string]int{
+	"int": 0, "int8": 8, "int16": 16, "int32": 32, "rune": 32, "int64": 64,
+	"uint": 0, "uint8": 8, "byte": 8, "uint16": 16, "uint32": 32, "uint64": 64, "uintptr": 0,
+}
+
+// limitParam returns the parameter of the min, max or len rule as a Go
+// constant for the values of the given kind of type t: a non-negative
+// integer for lengths, an integer in the range of the type for integers
+// and a finite number for floats.
+func (d *JSONDoc) limitParam(r constraint, kind string, t ast.Expr, c *context) (string, error) {
+	switch kind {
+	case "string", "slice", "map":
+		n, err := strconv.ParseInt(r.Param, 10, 0)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("rule %s=%s: the length must be a non-negative integer", r.Name, r.Param)
+		}
+		return strconv.FormatInt(n, 10), nil
+	case "int":
+		name := d.basicName(t, c)
+		bits, ok := intBits[name]
+		if !ok {
+			bits = 64
+		}
+		if strings.HasPrefix(name, "u") || name == "byte" {
+			n, err := strconv.ParseUint(r.Param, 10, bits)
+			if err != nil {
+				return "", fmt.Errorf("rule %s=%s: expected a non-negative integer in the range of %s", r.Name, r.Param, typeString(t))
+			}
+			return strconv.FormatUint(n, 10), nil
+		}
+		n, err := strconv.ParseInt(r.Param, 10, bits)
+		if err != nil {
+			return "", fmt.Errorf("rule %s=%s: expected an integer in the range of %s", r.Name, r.Param, typeString(t))
+		}
+		return strconv.FormatInt(n, 10), nil
+	case "float":
+		v, err := parseLimit(r.Param)
+		if err != nil {
+			return "", fmt.Errorf("rule %s=%s: %v", r.Name, r.Param, err)
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	}
+	return "", fmt.Errorf("rule %s is not supported for %s values", r.Name, kind)
+}
+
+// basicName returns the name of the predeclared type underlying t
+// (following the definitions of named types) or "" if there is none.
+func (d *JSONDoc) basicName(t ast.Expr, c *context) string {
+	if ident, ok := t.(*ast.Ident); ok {
+		if _, ok := intBits[ident.Name]; ok {
+			return ident.Name
+		}
+		switch ident.Name {
+		case "string", "bool", "float32", "float64":
+			return ident.Name
+		}
+	}
+	ts, c, err := d.lookupType(t, c)
+	if err != nil || ts == nil {
+		return ""
+	}
+	return d.basicName(ts.Type, c)
+}
+
+// zeroCheck returns the Go expression checking whether x of the given
+// kind has zero value (or does not have zero value if zero is false).
+func zeroCheck(x, kind string, zero bool) string {
+	op := "=="
+	if !zero {
+		op = "!="
+	}
+	switch kind {
+	case "string":
+		return fmt.Sprintf(`%s %s ""`, x, op)
+	case "slice", "map":
+		return fmt.Sprintf("len(%s) %s 0", x, op)
+	case "ptr":
+		return fmt.Sprintf("%s %s nil", x, op)
+	}
+	return fmt.Sprintf("%s %s 0", x, op)
+}
+
+// kindOf returns the kind of the given type ("string", "int", "float",
+// "bool", "slice", "map", "ptr", "struct" or "" if not known) following
+// the definitions of named types.
+func (d *JSONDoc) kindOf(t ast.Expr, c *context) string {
+	switch t := t.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return "string"
+		case "bool":
+			return "bool"
+		case "float32", "float64":
+			return "float"
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune":
+			return "int"
+		}
+	case *ast.StarExpr:
+		return "ptr"
+	case *ast.ArrayType:
+		return "slice"
+	case *ast.MapType:
+		return "map"
+	case *ast.StructType:
+		return "struct"
+	}
+	ts, c, err := d.lookupType(t, c)
+	if err != nil || ts == nil {
+		return ""
+	}
+	return d.kindOf(ts.Type, c)
+}
@@ -0,0 +1,104 @@
+package jsondoc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const validatorsSrc = `package api
+
+type Input struct {
+	Name   string           ` + "`json:\"name\" validate:\"required,max=8\"`" + `
+	Count  int8             ` + "`json:\"count\" validate:\"min=-1,max=100\"`" + `
+	Size   uint             ` + "`json:\"size\" validate:\"max=10\"`" + `
+	Ratio  float64          ` + "`json:\"ratio\" validate:\"omitempty,max=0.5\"`" + `
+	Tags   []string         ` + "`json:\"tags\" validate:\"len=2\"`" + `
+	Kind   string           ` + "`json:\"kind\" validate:\"oneof=a b\"`" + `
+	Items  []*Item          ` + "`json:\"items\"`" + `
+	ByName map[string]Item  ` + "`json:\"byName\"`" + `
+	Refs   map[string]*Item ` + "`json:\"refs\"`" + `
+	Main   Item             ` + "`json:\"main\"`" + `
+}
+
+type Item struct {
+	ID int ` + "`json:\"id\" validate:\"min=1\"`" + `
+}
+`
+
+// validatorsTestSrc tests the validators generated for validatorsSrc.
+const validatorsTestSrc = `package api
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	valid := func() Input {
+		return Input{Name: "pen", Count: -1, Tags: []string{"a", "b"}, Kind: "a", Main: Item{ID: 1},
+			Items: []*Item{{ID: 1}, nil}, ByName: map[string]Item{"x": {ID: 1}}, Refs: map[string]*Item{"y": nil}}
+	}
+	v := valid()
+	if err := v.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		change func(*Input)
+		err    string
+	}{
+		{func(v *Input) { v.Name = "" }, ` + "`\"name\": is required`" + `},
+		{func(v *Input) { v.Count = -2 }, ` + "`\"count\": must be at least -1`" + `},
+		{func(v *Input) { v.Size = 11 }, ` + "`\"size\": must be at most 10`" + `},
+		{func(v *Input) { v.Ratio = 0.75 }, ` + "`\"ratio\": must be at most 0.5`" + `},
+		{func(v *Input) { v.Tags = nil }, ` + "`\"tags\": must have exactly 2 elements`" + `},
+		{func(v *Input) { v.Items[0].ID = 0 }, ` + "`\"items\"[0]: \"id\": must be at least 1`" + `},
+		{func(v *Input) { v.ByName["x"] = Item{} }, ` + "`\"byName\"[x]: \"id\": must be at least 1`" + `},
+		{func(v *Input) { v.Refs["y"] = &Item{} }, ` + "`\"refs\"[y]: \"id\": must be at least 1`" + `},
+		{func(v *Input) { v.Main.ID = 0 }, ` + "`\"main\": \"id\": must be at least 1`" + `},
+	} {
+		v := valid()
+		c.change(&v)
+		if err := v.Validate(); err == nil || err.Error() != c.err {
+			t.Errorf("got error %v, want %s", err, c.err)
+		}
+	}
+}
+`
+
+func TestValidators(t *testing.T) {
+	d, dir := newTestPackageDoc(t, validatorsSrc, "{{endpoint \"POST\" \"/input\"}}\n\n{{input \"Input\"}}\n", Options{})
+	var b bytes.Buffer
+	if err := d.WriteValidators(&b, "."); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "validate_gen.go"), b.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "api_test.go"), []byte(validatorsTestSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGo(t, dir, "vet", ".")
+	runGo(t, dir, "test", "-count=1", ".")
+}
+
+func TestValidatorsInvalidLimits(t *testing.T) {
+	for _, c := range []struct {
+		field, err string
+	}{
+		{"X string `validate:\"min=1.5\"`", "non-negative integer"},
+		{"X []int `validate:\"len=-1\"`", "non-negative integer"},
+		{"X int `validate:\"min=1.5\"`", "expected an integer"},
+		{"X int `validate:\"max=1e3\"`", "expected an integer"},
+		{"X uint `validate:\"min=-1\"`", "non-negative integer"},
+		{"X uint8 `validate:\"max=300\"`", "range of uint8"},
+		{"X float64 `validate:\"max=Inf\"`", "invalid max limit"},
+		{"X float64 `validate:\"min=NaN\"`", "invalid min limit"},
+	} {
+		src := "package api\n\ntype Input struct {\n\t" + c.field + "\n}\n"
+		d := newTestDoc(t, src, "{{endpoint \"POST\" \"/input\"}}\n\n{{input \"Input\"}}\n", Options{})
+		var b bytes.Buffer
+		if err := d.WriteValidators(&b, "."); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: got error %v, want one containing %q", c.field, err, c.err)
+		}
+	}
+}
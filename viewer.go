@@ -0,0 +1,89 @@
+package jsondoc
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+)
+
+// openAPIViewers are the pages of the viewers of OpenAPI descriptions
+// (formatted with the title of the page and the URL of the spec or the
+// spec itself).
+var openAPIViewers = map[string]func(title, specURL string, spec []byte) string{
+	"redoc": func(title, specURL string, spec []byte) string {
+		return fmt.Sprintf(viewerHead, html.EscapeString(title), "") +
+			fmt.Sprintf("<redoc spec-url=\"%s\"></redoc>\n<script src=\"https://cdn.jsdelivr.net/npm/redoc@2/bundles/redoc.standalone.js\"></script>\n</body>\n</html>\n", html.EscapeString(specURL))
+	},
+	"swagger-ui": func(title, specURL string, spec []byte) string {
+		css := "<link rel=\"stylesheet\" href=\"https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui.css\">\n"
+		url := strings.Replace(jsString(specURL), "</", `<\/`, -1)
+		return fmt.Sprintf(viewerHead, html.EscapeString(title), css) +
+			fmt.Sprintf("<div id=\"swagger-ui\"></div>\n<script src=\"https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui-bundle.js\"></script>\n<script>\nSwaggerUIBundle({url: %s, dom_id: \"#swagger-ui\"});\n</script>\n</body>\n</html>\n", url)
+	},
+	// Stoplight Elements with the spec inlined (so that the page works
+	// without serving the spec)
+	"elements": func(title, specURL string, spec []byte) string {
+		css := "<link rel=\"stylesheet\" href=\"https://unpkg.com/@stoplight/elements@8/styles.min.css\">\n<script src=\"https://unpkg.com/@stoplight/elements@8/web-components.min.js\"></script>\n"
+		// </script> may not occur inside of the script element
+		js := strings.Replace(strings.TrimSpace(string(spec)), "</", `<\/`, -1)
+		return fmt.Sprintf(viewerHead, html.EscapeString(title), css) +
+			fmt.Sprintf("<elements-api id=\"jsondoc-elements\" router=\"hash\" layout=\"sidebar\"></elements-api>\n<script>\ndocument.getElementById(\"jsondoc-elements\").apiDescriptionDocument = %s;\n</script>\n</body>\n</html>\n", js)
+	},
+}
+
+const viewerHead = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>%s</title>
+%s<style>
+body {
+    margin: 0;
+}
+</style>
+</head>
+<body>
+`
+
+// OpenAPIViewers returns the names of the viewers supported by
+// WriteOpenAPIViewer.
+func OpenAPIViewers() []string {
+	var names []string
+	for name := range openAPIViewers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteOpenAPIViewer writes an HTML page showing the OpenAPI
+// description (as written by WriteOpenAPI) at specURL (such as its file
+// name, relative to the page) with the given viewer ("redoc" or
+// "swagger-ui", loaded from a CDN) so that the spec may be browsed by
+// serving both files. The "elements" viewer (Stoplight Elements) shows
+// the description inlined in the page instead.
+func (d *JSONDoc) WriteOpenAPIViewer(w io.Writer, viewer, specURL string) error {
+	page := openAPIViewers[viewer]
+	if page == nil {
+		return fmt.Errorf("unknown OpenAPI viewer %q (supported: %s)", viewer, strings.Join(OpenAPIViewers(), ", "))
+	}
+	c, err := d.begin()
+	if err != nil {
+		return err
+	}
+	defer d.end(c)
+	var spec bytes.Buffer
+	if err := c.writeOpenAPI(&spec); err != nil {
+		return err
+	}
+	title := d.title
+	if title == "" {
+		title = "API"
+	}
+	_, err = io.WriteString(w, page(title, specURL, spec.Bytes()))
+	return err
+}
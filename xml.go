@@ -0,0 +1,411 @@
+package jsondoc
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"go/ast"
+	"html"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// xmlContentType is the content type of XML inputs and outputs.
+const xmlContentType = "application/xml"
+
+// xmlField is a struct field as encoded by encoding/xml.
+type xmlField struct {
+	Path        []string // names of the nested elements (a>b>c) or of the attribute
+	Kind        string   // "element", "attribute", "chardata", "cdata", "innerxml" or "comment"
+	Optional    bool
+	Description string
+
+	typ  ast.Expr
+	c    *context
+	fake fakeField
+}
+
+// xmlKinds are the descriptions of the kinds of XML fields.
+var xmlKinds = map[string]string{
+	"element":   "element",
+	"attribute": "attribute",
+	"chardata":  "character data",
+	"cdata":     "CDATA section",
+	"innerxml":  "raw XML",
+	"comment":   "comment",
+}
+
+// xmlElem is a named type queued to be rendered as an XML element.
+type xmlElem struct {
+	t  *ast.TypeSpec
+	c  *context
+	id string
+}
+
+// inputXML documents the input of type name (a struct) sent as XML
+// (using the encoding/xml struct tag semantics).
+func (d *JSONDoc) inputXML(name string, level ...int) (string, error) {
+	return d.renderXML("input", name, level)
+}
+
+// outputXML documents the output of type name (a struct) sent as XML.
+func (d *JSONDoc) outputXML(name string, level ...int) (string, error) {
+	return d.renderXML("output", name, level)
+}
+
+func (d *JSONDoc) renderXML(kind, name string, level []int) (string, error) {
+	d.b.Reset()
+	e := d.currentEndpoint()
+	l, err := d.sectionLevel(e, level)
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %v", kind, name, err)
+	}
+	id := d.sectionID(e, kind, name)
+	title := "Input"
+	if kind == "output" {
+		title = "Output"
+	}
+	if e != nil {
+		if kind == "input" {
+			e.InputContentType = xmlContentType
+		} else {
+			e.OutputContentType = xmlContentType
+		}
+	}
+	fmt.Fprintf(&d.b, "%s %s (%s, XML) {#%s}\n<div>\n", heading(l), title, markdownEscapeString(typeIdent(name)), id)
+	t, c, err := d.lookupTypeName(name)
+	if err != nil {
+		return "", err
+	}
+	if s := d.xmlRendered[t]; s != "" {
+		d.addEdge(id, s)
+		fmt.Fprintf(&d.b, "<p>XML <a href=\"#%s\">element %s</a> described above.</p>\n", html.EscapeString(s), html.EscapeString(t.Name.Name))
+	} else {
+		d.xmlRendered[t] = id
+		queue := []xmlElem{{t, c, id}}
+		for i := 0; i < len(queue); i++ {
+			q := queue[i]
+			if i > 0 {
+				d.writeHeading(q.id, "Element "+q.t.Name.Name)
+				d.addAnchor(anchor{ID: q.id, Kind: "type", Title: "Element " + q.t.Name.Name, Type: q.t.Name.Name})
+			}
+			d.graphParent = q.id
+			if err := d.renderXMLElem(q.t, q.c, &queue); err != nil {
+				return "", err
+			}
+		}
+	}
+	d.b.WriteString("</div>\n")
+	return d.b.String(), nil
+}
+
+// renderXMLElem writes the table of the attributes and the content of
+// the XML element of type t (queueing the element types it refers to).
+func (d *JSONDoc) renderXMLElem(t *ast.TypeSpec, c *context, queue *[]xmlElem) error {
+	fields, root, err := d.xmlFields(t, c)
+	if err != nil {
+		return err
+	}
+	type row struct {
+		Name, Kind, Type, Description string
+	}
+	v := struct {
+		Root string
+		Rows []row
+	}{Root: html.EscapeString(root.Local)}
+	for _, f := range fields {
+		r := row{Kind: xmlKinds[f.Kind], Description: d.linkTerms(html.EscapeString(f.Description))}
+		switch f.Kind {
+		case "element":
+			var names []string
+			for _, s := range f.Path {
+				names = append(names, "&lt;"+html.EscapeString(s)+"&gt;")
+			}
+			r.Name = "<code>" + strings.Join(names, "") + "</code>"
+		case "attribute":
+			r.Name = "<code>" + html.EscapeString(f.Path[0]) + "</code>"
+		}
+		if f.Optional {
+			r.Name += " (optional)"
+		}
+		if r.Type, err = d.xmlTypeLink(f.typ, f.c, queue); err != nil {
+			return err
+		}
+		v.Rows = append(v.Rows, r)
+	}
+	if len(v.Rows) == 0 {
+		fmt.Fprintf(&d.b, "<p>XML element <code>&lt;%s&gt;</code> with no content.</p>\n", v.Root)
+		return nil
+	}
+	return d.table.ExecuteTemplate(&d.b, "xml", v)
+}
+
+// xmlTypeLink returns the description of the value type of an XML
+// field (linking to the element types queued to be rendered).
+func (d *JSONDoc) xmlTypeLink(t ast.Expr, c *context, queue *[]xmlElem) (string, error) {
+	switch t := t.(type) {
+	case *ast.StarExpr:
+		return d.xmlTypeLink(t.X, c, queue)
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && (ident.Name == "byte" || ident.Name == "uint8") {
+			return "string", nil
+		}
+		s, err := d.xmlTypeLink(t.Elt, c, queue)
+		return "repeated " + s, err
+	case *ast.MapType:
+		return "", fmt.Errorf("maps are not supported in XML")
+	case *ast.StructType:
+		return "", fmt.Errorf("anonymous structs are not supported in XML, use a named type")
+	case *ast.Ident:
+		if builtin[t.Name] {
+			return html.EscapeString(t.Name), nil
+		}
+	case *ast.SelectorExpr:
+		if d.isTime(t, c) {
+			return "time (RFC 3339)", nil
+		}
+	default:
+		return html.EscapeString(fmt.Sprint(t)), nil
+	}
+	ts, c, err := d.lookupType(t, c)
+	if err != nil {
+		return "", err
+	}
+	if ts == nil {
+		return html.EscapeString(fmt.Sprint(t)), nil
+	}
+	if _, ok := ts.Type.(*ast.StructType); !ok {
+		return d.xmlTypeLink(ts.Type, c, queue)
+	}
+	id := d.xmlRendered[ts]
+	if id == "" {
+		id = d.uniqueID("xml-type-" + idFromString(ts.Name.Name))
+		d.xmlRendered[ts] = id
+		*queue = append(*queue, xmlElem{ts, c, id})
+	}
+	d.addEdge(d.graphParent, id)
+	return fmt.Sprintf(`<a href="#%s">%s</a>`, html.EscapeString(id), html.EscapeString(ts.Name.Name)), nil
+}
+
+// xmlFields returns the fields of the struct type t as encoded by
+// encoding/xml (including the fields of embedded structs) and the name
+// of the element (given with the XMLName field, possibly with the name
+// space, or the type name).
+func (d *JSONDoc) xmlFields(t *ast.TypeSpec, c *context) ([]xmlField, xml.Name, error) {
+	st, ok := t.Type.(*ast.StructType)
+	if !ok {
+		return nil, xml.Name{}, fmt.Errorf("XML type %s is not a struct", t.Name.Name)
+	}
+	root := xml.Name{Local: t.Name.Name}
+	fields, err := d.appendXMLFields(nil, &root, st, c)
+	return fields, root, err
+}
+
+func (d *JSONDoc) appendXMLFields(fields []xmlField, root *xml.Name, t *ast.StructType, c *context) ([]xmlField, error) {
+	for _, f := range t.Fields.List {
+		if len(f.Names) == 0 {
+			typ := f.Type
+			if s, ok := typ.(*ast.StarExpr); ok {
+				typ = s.X
+			}
+			ts, c, err := d.lookupType(typ, c)
+			if err != nil {
+				return nil, err
+			}
+			if ts == nil {
+				continue
+			}
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				if fields, err = d.appendXMLFields(fields, root, st, c); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		for _, ident := range f.Names {
+			if !ast.IsExported(ident.Name) {
+				continue
+			}
+			name, opts, err := xmlTag(f.Tag)
+			if err != nil {
+				return nil, err
+			}
+			if ident.Name == "XMLName" {
+				if name != "" {
+					*root = xml.Name{Local: name}
+					if i := strings.LastIndexByte(name, ' '); i != -1 {
+						*root = xml.Name{Space: name[:i], Local: name[i+1:]}
+					}
+				}
+				continue
+			}
+			if name == "-" {
+				continue
+			}
+			xf := xmlField{Kind: "element", Description: parseFieldComment(f).Description, typ: f.Type, c: c}
+			for _, o := range opts {
+				switch o {
+				case "attr":
+					xf.Kind = "attribute"
+				case "chardata", "cdata", "innerxml", "comment":
+					xf.Kind = o
+				case "omitempty":
+					xf.Optional = true
+				}
+			}
+			if xf.Kind == "element" || xf.Kind == "attribute" {
+				if name == "" {
+					name = ident.Name
+				}
+				xf.Path = strings.Split(name, ">")
+			}
+			key := ""
+			if len(xf.Path) > 0 {
+				key = xf.Path[len(xf.Path)-1]
+			}
+			xf.fake = fieldFake(key, f)
+			fields = append(fields, xf)
+		}
+	}
+	return fields, nil
+}
+
+// xmlTag returns the name and the options of the xml struct tag.
+func xmlTag(tag *ast.BasicLit) (string, []string, error) {
+	if tag == nil {
+		return "", nil, nil
+	}
+	s, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return "", nil, err
+	}
+	fields := strings.Split(reflect.StructTag(s).Get("xml"), ",")
+	return fields[0], fields[1:], nil
+}
+
+// sampleXML returns a sample XML document of the named type (as given to
+// the inputXML and outputXML actions).
+func (d *JSONDoc) sampleXML(name string) (string, error) {
+	t, c, err := d.lookupTypeName(name)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := d.writeXMLSample(&b, t, c, "", "", make(map[*ast.TypeSpec]bool)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// writeXMLSample writes a sample element of type t (named elem or, if
+// empty, as the type) indented with prefix.
+func (d *JSONDoc) writeXMLSample(b *bytes.Buffer, t *ast.TypeSpec, c *context, elem, prefix string, seen map[*ast.TypeSpec]bool) error {
+	fields, root, err := d.xmlFields(t, c)
+	if err != nil {
+		return err
+	}
+	if elem == "" {
+		elem = root.Local
+	}
+	seen[t] = true
+	defer delete(seen, t)
+	fmt.Fprintf(b, "%s<%s", prefix, elem)
+	if root.Space != "" {
+		fmt.Fprintf(b, " xmlns=\"%s\"", html.EscapeString(root.Space))
+	}
+	for _, f := range fields {
+		if f.Kind == "attribute" {
+			fmt.Fprintf(b, " %s=\"%s\"", f.Path[0], html.EscapeString(textValue(d.sample(f.typ, f.c, f.fake, seen))))
+		}
+	}
+	b.WriteString(">")
+	var open []string // elements of the nested path opened so far
+	inner := false
+	for _, f := range fields {
+		switch f.Kind {
+		case "attribute", "comment", "innerxml":
+			continue
+		case "chardata", "cdata":
+			closeXMLPath(b, &open, 0, prefix)
+			b.WriteString(html.EscapeString(textValue(d.sample(f.typ, f.c, f.fake, seen))))
+			continue
+		}
+		inner = true
+		parents := f.Path[:len(f.Path)-1]
+		i := 0
+		for i < len(open) && i < len(parents) && open[i] == parents[i] {
+			i++
+		}
+		closeXMLPath(b, &open, i, prefix)
+		for _, p := range parents[i:] {
+			fmt.Fprintf(b, "\n%s<%s>", xmlIndent(prefix, len(open)+1), p)
+			open = append(open, p)
+		}
+		ind := xmlIndent(prefix, len(open)+1)
+		if err := d.writeXMLValue(b, f.typ, f.c, f.Path[len(f.Path)-1], f.fake, ind, seen); err != nil {
+			return err
+		}
+	}
+	closeXMLPath(b, &open, 0, prefix)
+	if inner {
+		b.WriteString("\n" + prefix)
+	}
+	fmt.Fprintf(b, "</%s>", elem)
+	return nil
+}
+
+// writeXMLValue writes a sample element named elem of type t.
+func (d *JSONDoc) writeXMLValue(b *bytes.Buffer, t ast.Expr, c *context, elem string, fake fakeField, prefix string, seen map[*ast.TypeSpec]bool) error {
+	switch tt := t.(type) {
+	case *ast.StarExpr:
+		return d.writeXMLValue(b, tt.X, c, elem, fake, prefix, seen)
+	case *ast.ArrayType:
+		if ident, ok := tt.Elt.(*ast.Ident); !ok || (ident.Name != "byte" && ident.Name != "uint8") {
+			return d.writeXMLValue(b, tt.Elt, c, elem, fake.elem(), prefix, seen)
+		}
+	case *ast.Ident, *ast.SelectorExpr:
+		// time.Time (marshaled as RFC 3339) is checked in the context
+		// of the field, not of the package of the looked up type
+		ts, tc, err := d.lookupType(t, c)
+		if err == nil && ts != nil && !d.isTime(t, c) {
+			if _, ok := ts.Type.(*ast.StructType); ok {
+				if seen[ts] {
+					return nil
+				}
+				b.WriteString("\n")
+				return d.writeXMLSample(b, ts, tc, elem, prefix, seen)
+			}
+		}
+	}
+	fmt.Fprintf(b, "\n%s<%s>%s</%s>", prefix, elem, html.EscapeString(textValue(d.sample(t, c, fake, seen))), elem)
+	return nil
+}
+
+// isTime reports whether t is time.Time.
+func (d *JSONDoc) isTime(t ast.Expr, c *context) bool {
+	sel, ok := t.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Time" {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	path, err := d.findImportIdent(c.File, ident.Name)
+	return err == nil && path == "time"
+}
+
+// closeXMLPath closes the elements of the nested path opened after
+// the first n.
+func closeXMLPath(b *bytes.Buffer, open *[]string, n int, prefix string) {
+	for len(*open) > n {
+		i := len(*open) - 1
+		fmt.Fprintf(b, "\n%s</%s>", xmlIndent(prefix, i+1), (*open)[i])
+		*open = (*open)[:i]
+	}
+}
+
+func xmlIndent(prefix string, depth int) string {
+	return prefix + strings.Repeat("  ", depth)
+}
@@ -0,0 +1,136 @@
+package jsondoc
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const xmlSrc = `package api
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+type Order struct {
+	XMLName  xml.Name  ` + "`xml:\"urn:shop order\"`" + `
+	ID       int       ` + "`xml:\"id,attr\"`" + `
+	Customer string    ` + "`xml:\"customer>name\"`" + ` // name of the customer
+	Email    string    ` + "`xml:\"customer>email,omitempty\"`" + `
+	Lines    []Line    ` + "`xml:\"line\"`" + `
+	Placed   time.Time ` + "`xml:\"placed\"`" + `
+	Note     string    ` + "`xml:\",chardata\"`" + `
+	Comment  string    ` + "`xml:\",comment\"`" + `
+	Skipped  string    ` + "`xml:\"-\"`" + `
+}
+
+type Line struct {
+	SKU string ` + "`xml:\"sku,attr\"`" + `
+	Qty int    ` + "`xml:\"qty,omitempty\"`" + `
+}
+`
+
+const xmlTmpl = "{{endpoint \"POST\" \"/order\"}}\n\n{{inputXML \"Order\"}}\n"
+
+func TestRenderXML(t *testing.T) {
+	md := renderMarkdown(t, xmlSrc, xmlTmpl)
+	for _, s := range []string{
+		"### Input (Order, XML) {#endpoint-post-order-input}",
+		"<caption>Content of XML element &lt;order&gt;</caption>",
+		"<td><code>id</code></td>\n<td>attribute</td>\n<td>int</td>",
+		"<td><code>&lt;customer&gt;&lt;name&gt;</code></td>\n<td>element</td>\n<td>string</td>\n<td>name of the customer</td>",
+		"<td><code>&lt;customer&gt;&lt;email&gt;</code> (optional)</td>",
+		`<td>repeated <a href="#xml-type-line">Line</a></td>`,
+		"<td>time (RFC 3339)</td>",
+		"<td></td>\n<td>character data</td>",
+		"<td></td>\n<td>comment</td>",
+		`<h4 id="xml-type-line">Element Line`,
+		"<td><code>&lt;qty&gt;</code> (optional)</td>",
+	} {
+		if !strings.Contains(md, s) {
+			t.Errorf("%q not found in\n%s", s, md)
+		}
+	}
+	if strings.Contains(md, "Skipped") || strings.Contains(md, "skipped") {
+		t.Errorf("the skipped field is documented in\n%s", md)
+	}
+}
+
+func TestRenderXMLErrors(t *testing.T) {
+	for _, c := range []struct {
+		field, err string
+	}{
+		{"M map[string]int", "maps are not supported in XML"},
+		{"S struct{ X int }", "anonymous structs are not supported"},
+	} {
+		src := "package api\n\ntype Order struct {\n\t" + c.field + "\n}\n"
+		d := newTestDoc(t, src, xmlTmpl, Options{})
+		if err := d.WriteMarkdown(io.Discard); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: got error %v, want one containing %q", c.field, err, c.err)
+		}
+	}
+}
+
+// xmlSampleTestSrc decodes the sample of Order (in order.xml).
+const xmlSampleTestSrc = `package api
+
+import (
+	"encoding/xml"
+	"os"
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+	b, err := os.ReadFile("order.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var o Order
+	if err := xml.Unmarshal(b, &o); err != nil {
+		t.Fatal(err)
+	}
+	if o.ID == 0 || o.Customer == "" || o.Email == "" || len(o.Lines) != 1 || o.Lines[0].SKU == "" || o.Placed.IsZero() || o.Note == "" {
+		t.Errorf("got %+v", o)
+	}
+}
+`
+
+func TestSampleXML(t *testing.T) {
+	d, dir := newTestPackageDoc(t, xmlSrc, xmlTmpl, Options{})
+	if err := d.execute(); err != nil {
+		t.Fatal(err)
+	}
+	s, err := d.sampleXML("Order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := xml.NewDecoder(strings.NewReader(s))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("%v in\n%s", err, s)
+		}
+	}
+	if !strings.HasPrefix(s, `<order xmlns="urn:shop" id="`) {
+		t.Errorf("unexpected sample\n%s", s)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "order.xml"), []byte(s), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "api_test.go"), []byte(xmlSampleTestSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGo(t, dir, "test", "-count=1", ".")
+}
+
+func TestSampleXMLSnippets(t *testing.T) {
+	md := renderMarkdown(t, xmlSrc, xmlTmpl+"\n{{snippets}}\n")
+	if !strings.Contains(md, "application/xml") || !strings.Contains(md, "urn:shop") {
+		t.Errorf("no XML sample in the snippets\n%s", md)
+	}
+}
@@ -0,0 +1,467 @@
+package jsondoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OpenAPI descriptions written by hand are usually in YAML. As jsondoc
+// depends only on the standard library they are read with a parser of
+// the subset of YAML used in such documents: block mappings and
+// sequences, flow collections, plain and quoted scalars and literal
+// (|) and folded (>) block scalars. Anchors, aliases and tags are
+// reported as errors and multiple documents are not supported.
+
+// decodeSpec decodes the OpenAPI description given in JSON or YAML
+// (mappings are decoded as map[string]interface{} and numbers as
+// float64, as with encoding/json).
+func decodeSpec(b []byte) (map[string]interface{}, error) {
+	if s := strings.TrimSpace(string(b)); strings.HasPrefix(s, "{") {
+		var m map[string]interface{}
+		err := json.Unmarshal(b, &m)
+		return m, err
+	}
+	v, err := decodeYAML(b)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a mapping at the top level")
+	}
+	return m, nil
+}
+
+// yamlParser parses the lines of a YAML document.
+type yamlParser struct {
+	lines []string
+	i     int // index of the current line
+}
+
+func decodeYAML(b []byte) (interface{}, error) {
+	p := &yamlParser{lines: strings.Split(strings.Replace(string(b), "\r\n", "\n", -1), "\n")}
+	indent, _, ok := p.peek()
+	if !ok {
+		return nil, nil
+	}
+	v, err := p.node(indent, -1)
+	if err != nil {
+		return nil, err
+	}
+	if _, text, ok := p.peek(); ok {
+		return nil, p.errorf("unexpected %q", text)
+	}
+	return v, nil
+}
+
+// errorf returns the error at the current line.
+func (p *yamlParser) errorf(format string, args ...interface{}) error {
+	return p.errorAt(p.i, format, args...)
+}
+
+// errorAt returns the error at the line with the given index.
+func (p *yamlParser) errorAt(i int, format string, args ...interface{}) error {
+	return fmt.Errorf("yaml: line %d: %s", i+1, fmt.Sprintf(format, args...))
+}
+
+// peek returns the indentation and the text (without the comment) of
+// the current line skipping blank lines, comments and the markers of
+// the document.
+func (p *yamlParser) peek() (indent int, text string, ok bool) {
+	for ; p.i < len(p.lines); p.i++ {
+		line := p.lines[p.i]
+		text = strings.TrimSpace(stripYAMLComment(line))
+		if text == "" || text == "---" || text == "..." || strings.HasPrefix(text, "%") {
+			continue
+		}
+		return len(line) - len(strings.TrimLeft(line, " ")), text, true
+	}
+	return 0, "", false
+}
+
+// stripYAMLComment returns the line without the comment (if any).
+func stripYAMLComment(line string) string {
+	quote := byte(0)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote == '\'' && c == '\'' && i+1 < len(line) && line[i+1] == '\'':
+			i++ // an escaped quote
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" :-[{,", line[i-1]) != -1):
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// node parses the node beginning at the current line (indented with
+// indent spaces) in the collection indented with parent spaces.
+func (p *yamlParser) node(indent, parent int) (interface{}, error) {
+	_, text, _ := p.peek()
+	if text == "-" || strings.HasPrefix(text, "- ") {
+		return p.sequence(indent)
+	}
+	if _, _, ok := splitYAMLKey(text); ok {
+		return p.mapping(indent)
+	}
+	p.i++
+	return p.scalar(text, parent)
+}
+
+// mapping parses the block mapping indented with indent spaces.
+func (p *yamlParser) mapping(indent int) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	for {
+		ind, text, ok := p.peek()
+		if !ok || ind < indent {
+			return m, nil
+		}
+		if ind > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		key, rest, ok := splitYAMLKey(text)
+		if !ok {
+			return nil, p.errorf("expected a key of a mapping, got %q", text)
+		}
+		p.i++
+		var v interface{}
+		var err error
+		if rest == "" {
+			v, err = p.value(indent, true)
+		} else {
+			v, err = p.scalar(rest, indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+}
+
+// sequence parses the block sequence indented with indent spaces.
+func (p *yamlParser) sequence(indent int) ([]interface{}, error) {
+	a := []interface{}{}
+	for {
+		ind, text, ok := p.peek()
+		if !ok || ind != indent || (text != "-" && !strings.HasPrefix(text, "- ")) {
+			if ok && ind > indent {
+				return nil, p.errorf("unexpected indentation")
+			}
+			return a, nil
+		}
+		rest := strings.TrimSpace(text[1:])
+		var v interface{}
+		var err error
+		if rest == "" {
+			p.i++
+			v, err = p.value(indent, false)
+		} else {
+			// the item continues at the column of its content (such
+			// as the first key of a mapping)
+			col := indent + strings.Index(p.lines[p.i][indent:], rest)
+			p.lines[p.i] = strings.Repeat(" ", col) + rest
+			v, err = p.node(col, indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+	}
+}
+
+// value parses the value given in the lines following the key of a
+// mapping (or the dash of a sequence) indented with indent spaces (nil
+// if there is none). The sequences being the values of mappings may be
+// indented as their keys.
+func (p *yamlParser) value(indent int, inMapping bool) (interface{}, error) {
+	ind, text, ok := p.peek()
+	switch {
+	case ok && ind > indent:
+		return p.node(ind, indent)
+	case ok && ind == indent && inMapping && (text == "-" || strings.HasPrefix(text, "- ")):
+		return p.sequence(ind)
+	}
+	return nil, nil
+}
+
+// scalar returns the value of the scalar (or the flow collection, or
+// the block scalar whose lines follow) given after the key of a mapping
+// indented with parent spaces.
+func (p *yamlParser) scalar(s string, parent int) (interface{}, error) {
+	line := p.i - 1 // the line of the scalar (already read)
+	switch {
+	case s[0] == '|' || s[0] == '>':
+		return p.blockScalar(s, parent), nil
+	case s[0] == '[' || s[0] == '{':
+		f := &flowParser{s: s}
+		v, err := f.value()
+		if err == nil && strings.TrimSpace(f.s[f.i:]) != "" {
+			err = fmt.Errorf("unexpected %q", f.s[f.i:])
+		}
+		if err != nil {
+			return nil, p.errorAt(line, "%v", err)
+		}
+		return v, nil
+	case strings.IndexByte("&*!", s[0]) != -1:
+		return nil, p.errorAt(line, "anchors, aliases and tags are not supported")
+	case s[0] == '"' || s[0] == '\'':
+		v, err := unquoteYAML(s)
+		if err != nil {
+			return nil, p.errorAt(line, "%v", err)
+		}
+		return v, nil
+	}
+	// a plain scalar may continue in the following (more indented) lines
+	for {
+		ind, text, ok := p.peek()
+		if !ok || ind <= parent {
+			break
+		}
+		if _, _, ok := splitYAMLKey(text); ok {
+			return nil, p.errorf("unexpected indentation")
+		}
+		s += " " + text
+		p.i++
+	}
+	return plainYAML(s), nil
+}
+
+// blockScalar returns the literal (|) or folded (>) block scalar
+// given in the lines more indented than parent.
+func (p *yamlParser) blockScalar(header string, parent int) string {
+	var lines []string
+	indent := -1
+	for ; p.i < len(p.lines); p.i++ {
+		line := strings.TrimRight(p.lines[p.i], " \t")
+		ind := len(line) - len(strings.TrimLeft(line, " "))
+		if line == "" {
+			lines = append(lines, "")
+			continue
+		}
+		if ind <= parent {
+			break
+		}
+		if indent == -1 {
+			indent = ind
+		}
+		if ind < indent {
+			break
+		}
+		lines = append(lines, line[indent:])
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	var s string
+	if header[0] == '|' {
+		s = strings.Join(lines, "\n")
+	} else {
+		// single line breaks are folded into spaces
+		for i, line := range lines {
+			switch {
+			case line == "":
+				s += "\n"
+				continue
+			case i > 0 && lines[i-1] != "":
+				s += " "
+			}
+			s += line
+		}
+	}
+	switch {
+	case strings.Contains(header, "-"):
+		return s
+	case s == "":
+		return s
+	}
+	return s + "\n"
+}
+
+// splitYAMLKey splits the line of a mapping into the key and the rest
+// of the line (the value, if given in the same line).
+func splitYAMLKey(s string) (key, rest string, ok bool) {
+	switch {
+	case s == "" || strings.IndexByte("[{|>", s[0]) != -1:
+		return "", "", false
+	case s[0] == '-' && (len(s) == 1 || s[1] == ' '):
+		return "", "", false // an item of a sequence
+	}
+	if s[0] == '"' || s[0] == '\'' {
+		end := quotedEnd(s)
+		if end == -1 || end == len(s) || s[end] != ':' || (end+1 < len(s) && s[end+1] != ' ') {
+			return "", "", false
+		}
+		k, err := unquoteYAML(s[:end])
+		if err != nil {
+			return "", "", false
+		}
+		return k, strings.TrimSpace(s[end+1:]), true
+	}
+	i := strings.Index(s, ": ")
+	if i == -1 {
+		if !strings.HasSuffix(s, ":") {
+			return "", "", false
+		}
+		i = len(s) - 1
+	}
+	return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:]), true
+}
+
+// quotedEnd returns the index following the quoted string at the
+// beginning of s (-1 if it is not terminated).
+func quotedEnd(s string) int {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++
+		case s[i] == q && q == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == q:
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// unquoteYAML returns the value of the single- or double-quoted scalar.
+func unquoteYAML(s string) (string, error) {
+	if end := quotedEnd(s); end != len(s) {
+		return "", fmt.Errorf("invalid quoted string %s", s)
+	}
+	if s[0] == '\'' {
+		return strings.Replace(s[1:len(s)-1], "''", "'", -1), nil
+	}
+	return strconv.Unquote(s)
+}
+
+// plainYAML returns the value of the plain scalar (null, a boolean, a
+// number or a string).
+func plainYAML(s string) interface{} {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && strings.IndexAny(s, "_xXoObB") == -1 {
+		return f
+	}
+	return s
+}
+
+// flowParser parses flow collections (such as [a, b] or {a: 1}).
+type flowParser struct {
+	s string
+	i int
+}
+
+func (f *flowParser) skipSpace() {
+	for f.i < len(f.s) && (f.s[f.i] == ' ' || f.s[f.i] == '\t') {
+		f.i++
+	}
+}
+
+func (f *flowParser) value() (interface{}, error) {
+	f.skipSpace()
+	if f.i == len(f.s) {
+		return nil, fmt.Errorf("unexpected end of flow collection")
+	}
+	switch f.s[f.i] {
+	case '[':
+		f.i++
+		a := []interface{}{}
+		for {
+			f.skipSpace()
+			if f.i < len(f.s) && f.s[f.i] == ']' {
+				f.i++
+				return a, nil
+			}
+			v, err := f.value()
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+			if err := f.separator(']'); err != nil {
+				return nil, err
+			}
+		}
+	case '{':
+		f.i++
+		m := make(map[string]interface{})
+		for {
+			f.skipSpace()
+			if f.i < len(f.s) && f.s[f.i] == '}' {
+				f.i++
+				return m, nil
+			}
+			k, err := f.scalar(":,}")
+			if err != nil {
+				return nil, err
+			}
+			key := fmt.Sprint(k)
+			f.skipSpace()
+			var v interface{}
+			if f.i < len(f.s) && f.s[f.i] == ':' {
+				f.i++
+				if v, err = f.value(); err != nil {
+					return nil, err
+				}
+			}
+			m[key] = v
+			if err := f.separator('}'); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return f.scalar(",]}")
+}
+
+// separator skips the comma following an element of a collection (or
+// leaves the end of the collection to be read).
+func (f *flowParser) separator(end byte) error {
+	f.skipSpace()
+	switch {
+	case f.i < len(f.s) && f.s[f.i] == ',':
+		f.i++
+		return nil
+	case f.i < len(f.s) && f.s[f.i] == end:
+		return nil
+	}
+	return fmt.Errorf("expected ',' or '%c' in flow collection", end)
+}
+
+// scalar parses the quoted scalar or the plain scalar ending with one
+// of the stop characters.
+func (f *flowParser) scalar(stop string) (interface{}, error) {
+	f.skipSpace()
+	if f.i < len(f.s) && (f.s[f.i] == '"' || f.s[f.i] == '\'') {
+		end := quotedEnd(f.s[f.i:])
+		if end == -1 {
+			return nil, fmt.Errorf("unterminated quoted string")
+		}
+		s, err := unquoteYAML(f.s[f.i : f.i+end])
+		f.i += end
+		return s, err
+	}
+	if f.i < len(f.s) && strings.IndexByte("&*!", f.s[f.i]) != -1 {
+		return nil, fmt.Errorf("anchors, aliases and tags are not supported")
+	}
+	start := f.i
+	for f.i < len(f.s) && strings.IndexByte(stop, f.s[f.i]) == -1 {
+		f.i++
+	}
+	return plainYAML(strings.TrimSpace(f.s[start:f.i])), nil
+}
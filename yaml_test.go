@@ -0,0 +1,76 @@
+package jsondoc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	for _, c := range []struct {
+		name, src string
+		want      interface{}
+	}{
+		{"mapping", "a: 1\nb: x y\nc:\nd: true\n", map[string]interface{}{"a": 1.0, "b": "x y", "c": nil, "d": true}},
+		{"nested", "a:\n  b:\n    c: ~\n", map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"c": nil}}}},
+		{"sequence", "- 1\n- two\n-\n  - 3\n", []interface{}{1.0, "two", []interface{}{3.0}}},
+		{"sequence of mappings", "a:\n- b: 1\n  c: 2\n- d\n", map[string]interface{}{"a": []interface{}{map[string]interface{}{"b": 1.0, "c": 2.0}, "d"}}},
+		{"comments", "# spec\na: 1 # one\nb: 'x # y'\nc: x#y\n", map[string]interface{}{"a": 1.0, "b": "x # y", "c": "x#y"}},
+		{"documents", "---\na: 1\n...\n", map[string]interface{}{"a": 1.0}},
+		{"plain multi-line", "a: x\n  y\nb: 0x1f\n", map[string]interface{}{"a": "x y", "b": "0x1f"}},
+		{"single-quoted", "a: 'it''s: # not a comment'\n", map[string]interface{}{"a": "it's: # not a comment"}},
+		{"double-quoted", "a: \"x\\ty\\\"\"\n\"b c\": \"1\"\n", map[string]interface{}{"a": "x\ty\"", "b c": "1"}},
+		{"literal", "a: |\n  x\n   y\n\n  z\nb: 1\n", map[string]interface{}{"a": "x\n y\n\nz\n", "b": 1.0}},
+		{"literal strip", "a: |-\n  x\n  y\n", map[string]interface{}{"a": "x\ny"}},
+		{"folded", "a: >\n  x\n  y\n\n  z\n", map[string]interface{}{"a": "x y\nz\n"}},
+		{"flow sequence", "a: [1, 'b, c', [d], {e: f}]\n", map[string]interface{}{"a": []interface{}{1.0, "b, c", []interface{}{"d"}, map[string]interface{}{"e": "f"}}}},
+		{"flow mapping", "a: {b: 1, \"c\": [], d}\n", map[string]interface{}{"a": map[string]interface{}{"b": 1.0, "c": []interface{}{}, "d": nil}}},
+		{"empty", "# nothing\n", nil},
+	} {
+		v, err := decodeYAML([]byte(c.src))
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+		} else if !reflect.DeepEqual(v, c.want) {
+			t.Errorf("%s: got %#v, want %#v", c.name, v, c.want)
+		}
+	}
+}
+
+func TestDecodeYAMLErrors(t *testing.T) {
+	for _, c := range []struct {
+		name, src, err string
+	}{
+		{"anchor", "a: &x 1\nb: 2\n", "line 1: anchors, aliases and tags are not supported"},
+		{"alias", "a: 1\nb: *x\n", "line 2: anchors, aliases and tags are not supported"},
+		{"tag", "a: !!str 1\n", "line 1: anchors, aliases and tags are not supported"},
+		{"anchor in flow", "a: [&x 1]\n", "line 1: anchors, aliases and tags are not supported"},
+		{"indentation", "a: 1\n  b: 2\n", "line 2: unexpected indentation"},
+		{"not a key", "a: 1\nb\n", "line 2: expected a key of a mapping"},
+		{"unterminated flow", "a: [1, 2\n", "line 1: expected ',' or ']'"},
+		{"unterminated quote", "a: 'x\n", "line 1: invalid quoted string"},
+		{"trailing", "- 1\nb: 2\n", "line 2: unexpected \"b: 2\""},
+	} {
+		_, err := decodeYAML([]byte(c.src))
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: got error %v, want one containing %q", c.name, err, c.err)
+		}
+	}
+}
+
+func TestDecodeSpec(t *testing.T) {
+	for _, src := range []string{
+		`{"openapi": "3.0.3", "paths": {"/item": {}}}`,
+		"openapi: 3.0.3\npaths:\n  /item: {}\n",
+	} {
+		s, err := decodeSpec([]byte(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s["openapi"] != "3.0.3" || mapAt(s, "paths", "/item") == nil {
+			t.Errorf("decodeSpec(%q) = %v", src, s)
+		}
+	}
+	if _, err := decodeSpec([]byte("- 1\n")); err == nil {
+		t.Error("got no error for a sequence at the top level")
+	}
+}